@@ -1,11 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
 	"github.com/jho3r/finanger-back/internal/app/server"
 	"github.com/jho3r/finanger-back/internal/app/settings"
 	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
 )
 
+// shutdownTimeout is the time given to the in-flight requests to finish when the process is interrupted.
+const shutdownTimeout = 10 * time.Second
+
 var loggerMain = logger.Setup("main")
 
 func main() {
@@ -13,11 +27,169 @@ func main() {
 
 	settings.LoadEnvs()
 
-	router := server.SetupServer()
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	router, workers := server.SetupServer()
+
+	// The server and the workers stop when the process is interrupted.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+
+	for _, worker := range workers {
+		wg.Add(1)
+
+		go func(worker server.Worker) {
+			defer wg.Done()
+			worker.Run(ctx)
+		}(worker)
+	}
+
+	srv := &http.Server{Addr: ":" + settings.Commons.Port, Handler: router}
+
+	// shutdownDone is closed when the in-flight requests finished or the shutdown timed out.
+	shutdownDone := make(chan struct{})
+
+	go func() {
+		defer close(shutdownDone)
+
+		<-ctx.Done()
+		loggerMain.Info("Shutting down the server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			loggerMain.WithError(err).Error("Error shutting down the server")
+		}
+	}()
 
 	loggerMain.Infof("Server running on port %s", settings.Commons.Port)
 
-	if err := router.Run(":" + settings.Commons.Port); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		loggerMain.WithError(err).Fatal("Error running the server")
 	}
+
+	// The server only closes after the interruption, wait for the requests and the workers to finish.
+	<-shutdownDone
+	wg.Wait()
+
+	loggerMain.Info("Server stopped")
+}
+
+// runCommand runs an admin command instead of the server.
+func runCommand(name string, args []string) {
+	switch name {
+	case "backfill-net-worth":
+		backfillNetWorth(args)
+	case "migrate":
+		migrate(args)
+	default:
+		loggerMain.Fatalf("Unknown command %s, the available commands are: backfill-net-worth, migrate", name)
+	}
+}
+
+// backfillNetWorth takes the net worth snapshots of every day between the dates from the stored balances and prices.
+// Usage: backfill-net-worth -from 2023-01-01 [-to 2023-12-31] [-user 1]
+func backfillNetWorth(args []string) {
+	flags := flag.NewFlagSet("backfill-net-worth", flag.ExitOnError)
+	fromFlag := flags.String("from", "", "first day to backfill, YYYY-MM-DD")
+	toFlag := flags.String("to", time.Now().Format(time.DateOnly), "last day to backfill, YYYY-MM-DD")
+	userID := flags.Uint("user", 0, "id of the user to backfill, all the users by default")
+
+	if err := flags.Parse(args); err != nil {
+		loggerMain.WithError(err).Fatal("Error parsing the flags")
+	}
+
+	from, err := time.Parse(time.DateOnly, *fromFlag)
+	if err != nil {
+		loggerMain.WithError(err).Fatal("The from flag must be a YYYY-MM-DD date")
+	}
+
+	to, err := time.Parse(time.DateOnly, *toFlag)
+	if err != nil {
+		loggerMain.WithError(err).Fatal("The to flag must be a YYYY-MM-DD date")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	admin := server.SetupAdmin()
+
+	count, err := admin.NetWorthService.Backfill(ctx, *userID, from, to)
+	if err != nil {
+		loggerMain.WithError(err).Fatalf("Error backfilling the net worth after %d snapshots", count)
+	}
+
+	loggerMain.Infof("Backfilled %d net worth snapshots", count)
+}
+
+// migrate applies, reverts or inspects the embedded migrations of the database.
+// Usage: migrate up | migrate down [steps] | migrate status | migrate force <version>
+func migrate(args []string) {
+	if len(args) == 0 {
+		loggerMain.Fatal("Missing the migrate command, the available commands are: up, down, status, force")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbMigrator := server.SetupMigrator()
+	defer dbMigrator.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := dbMigrator.Up(ctx)
+		if err != nil {
+			loggerMain.WithError(err).Fatalf("Error applying the migrations after %d of them", len(applied))
+		}
+
+		loggerMain.Infof("Applied %d migrations", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				loggerMain.Fatal("The steps must be a positive number")
+			}
+		}
+
+		reverted, err := dbMigrator.Down(ctx, steps)
+		if err != nil {
+			loggerMain.WithError(err).Fatalf("Error reverting the migrations after %d of them", len(reverted))
+		}
+
+		loggerMain.Infof("Reverted %d migrations", len(reverted))
+	case "status":
+		status, err := dbMigrator.Status(ctx)
+		if err != nil {
+			loggerMain.WithError(err).Fatal("Error getting the migrations status")
+		}
+
+		loggerMain.Infof("Version %d, dirty %t, %d applied and %d pending migrations",
+			status.Version, status.Dirty, len(status.Applied), len(status.Pending))
+
+		for _, migration := range status.Pending {
+			loggerMain.Infof("Pending %d_%s", migration.Version, migration.Name)
+		}
+	case "force":
+		if len(args) < 2 {
+			loggerMain.Fatal("Missing the version to force")
+		}
+
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			loggerMain.WithError(err).Fatal("The version must be a number")
+		}
+
+		if err := dbMigrator.Force(ctx, version); err != nil {
+			loggerMain.WithError(err).Fatal("Error forcing the version")
+		}
+	default:
+		loggerMain.Fatalf("Unknown migrate command %s, the available commands are: up, down, status, force", args[0])
+	}
 }
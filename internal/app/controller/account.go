@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+var loggerAccount = logger.Setup("controller.account")
+
+type AccountReq struct {
+	AssetID         uint            `json:"asset_id" binding:"required"`
+	Name            string          `json:"name" binding:"required"`
+	Type            string          `json:"type" binding:"required,oneof=checking savings cash credit_card brokerage loan income expense equity"`
+	Institution     string          `json:"institution"`
+	OpeningBalance  decimal.Decimal `json:"opening_balance"`
+	OpeningDate     time.Time       `json:"opening_date"`
+	CostBasisMethod string          `json:"cost_basis_method" binding:"omitempty,oneof=fifo lifo highest_cost average_cost"`
+}
+
+type UpdateAccountReq struct {
+	Name            *string `json:"name" binding:"omitempty,min=1"`
+	Type            *string `json:"type" binding:"omitempty,oneof=checking savings cash credit_card brokerage loan income expense equity"`
+	Institution     *string `json:"institution"`
+	Status          *string `json:"status" binding:"omitempty,oneof=open closed"`
+	CostBasisMethod *string `json:"cost_basis_method" binding:"omitempty,oneof=fifo lifo highest_cost average_cost"`
+}
+
+type GetAccountsQuery struct {
+	Type   string `form:"type" binding:"omitempty,oneof=checking savings cash credit_card brokerage loan income expense equity"`
+	Status string `form:"status" binding:"omitempty,oneof=open closed"`
+}
+
+// CreateAccount creates a new account for the authenticated user.
+func CreateAccount(accountService account.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request AccountReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerAccount.WithError(err).Error("Error binding the account")
+			renderError(c, "Error binding the account", bindingError(err))
+			return
+		}
+
+		created, err := accountService.Create(c.Request.Context(), account.Account{
+			UserID:          GetUserID(c),
+			AssetID:         request.AssetID,
+			Name:            request.Name,
+			Type:            account.AccountType(request.Type),
+			Institution:     request.Institution,
+			OpeningBalance:  money.Money{Amount: request.OpeningBalance},
+			OpeningDate:     request.OpeningDate,
+			CostBasisMethod: account.CostBasisMethod(request.CostBasisMethod),
+		})
+		if err != nil {
+			loggerAccount.WithError(err).Error("Error creating the account")
+			renderError(c, "Error creating the account", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: created})
+	}
+}
+
+// GetAccounts returns the accounts of the authenticated user given filters.
+func GetAccounts(accountService account.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query GetAccountsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerAccount.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		accounts, err := accountService.Get(c.Request.Context(), GetUserID(c), account.AccountFilter{
+			Type:   account.AccountType(query.Type),
+			Status: account.AccountStatus(query.Status),
+		})
+		if err != nil {
+			loggerAccount.WithError(err).Error("Error getting the accounts")
+			renderError(c, "Error getting the accounts", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: accounts})
+	}
+}
+
+// GetAccount returns the account of the authenticated user with the given id.
+func GetAccount(accountService account.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerAccount.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		found, err := accountService.GetByID(c.Request.Context(), GetUserID(c), id)
+		if err != nil {
+			loggerAccount.WithError(err).Error("Error getting the account")
+			renderError(c, "Error getting the account", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: found})
+	}
+}
+
+// UpdateAccount updates the given fields of an account of the authenticated user.
+func UpdateAccount(accountService account.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerAccount.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request UpdateAccountReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerAccount.WithError(err).Error("Error binding the account")
+			renderError(c, "Error binding the account", bindingError(err))
+			return
+		}
+
+		patch := account.AccountPatch{
+			Name:        request.Name,
+			Institution: request.Institution,
+		}
+
+		if request.Type != nil {
+			accountType := account.AccountType(*request.Type)
+			patch.Type = &accountType
+		}
+
+		if request.Status != nil {
+			status := account.AccountStatus(*request.Status)
+			patch.Status = &status
+		}
+
+		if request.CostBasisMethod != nil {
+			method := account.CostBasisMethod(*request.CostBasisMethod)
+			patch.CostBasisMethod = &method
+		}
+
+		updated, err := accountService.Update(c.Request.Context(), GetUserID(c), id, patch)
+		if err != nil {
+			loggerAccount.WithError(err).Error("Error updating the account")
+			renderError(c, "Error updating the account", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: updated})
+	}
+}
+
+// DeleteAccount soft deletes an account of the authenticated user.
+func DeleteAccount(accountService account.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerAccount.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		if err := accountService.Delete(c.Request.Context(), GetUserID(c), id); err != nil {
+			loggerAccount.WithError(err).Error("Error deleting the account")
+			renderError(c, "Error deleting the account", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Account deleted successfully"})
+	}
+}
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/budget"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+var loggerBudget = logger.Setup("controller.budget")
+
+type BudgetReq struct {
+	CategoryID uint            `json:"category_id" binding:"required"`
+	Name       string          `json:"name" binding:"required"`
+	Period     string          `json:"period" binding:"required,oneof=weekly monthly yearly custom"`
+	Amount     decimal.Decimal `json:"amount" binding:"required"`
+	StartDate  string          `json:"start_date" binding:"required"`
+	EndDate    string          `json:"end_date"`
+	Rollover   bool            `json:"rollover"`
+}
+
+type UpdateBudgetReq struct {
+	Name     *string          `json:"name" binding:"omitempty,min=1"`
+	Amount   *decimal.Decimal `json:"amount"`
+	EndDate  *string          `json:"end_date"`
+	Rollover *bool            `json:"rollover"`
+}
+
+type ProgressQuery struct {
+	Date string `form:"date"`
+}
+
+// CreateBudget creates a new budget for the authenticated user.
+func CreateBudget(budgetService budget.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request BudgetReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerBudget.WithError(err).Error("Error binding the budget")
+			renderError(c, "Error binding the budget", bindingError(err))
+			return
+		}
+
+		startDate, err := parseDate("start_date", request.StartDate)
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error parsing the start date")
+			renderError(c, "Error parsing the start date", err)
+			return
+		}
+
+		endDate, err := parseEndDate("end_date", request.EndDate)
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error parsing the end date")
+			renderError(c, "Error parsing the end date", err)
+			return
+		}
+
+		newBudget := budget.Budget{
+			UserID:     GetUserID(c),
+			CategoryID: request.CategoryID,
+			Name:       request.Name,
+			Period:     budget.Period(request.Period),
+			Amount:     money.Money{Amount: request.Amount},
+			StartDate:  startDate,
+			Rollover:   request.Rollover,
+		}
+
+		if !endDate.IsZero() {
+			newBudget.EndDate = &endDate
+		}
+
+		created, err := budgetService.Create(c.Request.Context(), newBudget)
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error creating the budget")
+			renderError(c, "Error creating the budget", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: created})
+	}
+}
+
+// GetBudgets returns the budgets of the authenticated user.
+func GetBudgets(budgetService budget.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		budgets, err := budgetService.Get(c.Request.Context(), GetUserID(c))
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error getting the budgets")
+			renderError(c, "Error getting the budgets", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: budgets})
+	}
+}
+
+// GetBudget returns the budget of the authenticated user with the given id.
+func GetBudget(budgetService budget.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		found, err := budgetService.GetByID(c.Request.Context(), GetUserID(c), id)
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error getting the budget")
+			renderError(c, "Error getting the budget", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: found})
+	}
+}
+
+// UpdateBudget updates the given fields of a budget of the authenticated user.
+func UpdateBudget(budgetService budget.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request UpdateBudgetReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerBudget.WithError(err).Error("Error binding the budget")
+			renderError(c, "Error binding the budget", bindingError(err))
+			return
+		}
+
+		patch := budget.BudgetPatch{
+			Name:     request.Name,
+			Rollover: request.Rollover,
+		}
+
+		if request.Amount != nil {
+			patch.Amount = &money.Money{Amount: *request.Amount}
+		}
+
+		if request.EndDate != nil {
+			endDate, err := parseEndDate("end_date", *request.EndDate)
+			if err != nil {
+				loggerBudget.WithError(err).Error("Error parsing the end date")
+				renderError(c, "Error parsing the end date", err)
+				return
+			}
+
+			patch.EndDate = &endDate
+		}
+
+		updated, err := budgetService.Update(c.Request.Context(), GetUserID(c), id, patch)
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error updating the budget")
+			renderError(c, "Error updating the budget", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: updated})
+	}
+}
+
+// DeleteBudget soft deletes a budget of the authenticated user.
+func DeleteBudget(budgetService budget.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		if err := budgetService.Delete(c.Request.Context(), GetUserID(c), id); err != nil {
+			loggerBudget.WithError(err).Error("Error deleting the budget")
+			renderError(c, "Error deleting the budget", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Budget deleted successfully"})
+	}
+}
+
+// GetBudgetProgress returns the spending of a budget of the authenticated user in the period of a date, now by default.
+func GetBudgetProgress(budgetService budget.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var query ProgressQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerBudget.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		date, err := parseEndDate("date", query.Date)
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error parsing the date")
+			renderError(c, "Error parsing the date", err)
+			return
+		}
+
+		if date.IsZero() {
+			date = time.Now()
+		}
+
+		progress, err := budgetService.GetProgress(c.Request.Context(), GetUserID(c), id, date)
+		if err != nil {
+			loggerBudget.WithError(err).Error("Error getting the progress of the budget")
+			renderError(c, "Error getting the progress of the budget", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: progress})
+	}
+}
@@ -0,0 +1,339 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/category"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerCategory = logger.Setup("controller.category")
+
+type CategoryReq struct {
+	ParentID *uint  `json:"parent_id"`
+	Name     string `json:"name" binding:"required"`
+	Kind     string `json:"kind" binding:"required,oneof=income expense"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+}
+
+type UpdateCategoryReq struct {
+	Name     *string `json:"name" binding:"omitempty,min=1"`
+	Archived *bool   `json:"archived"`
+	Icon     *string `json:"icon"`
+	Color    *string `json:"color"`
+}
+
+type GetCategoriesQuery struct {
+	IncludeArchived bool `form:"include_archived"`
+}
+
+type MoveCategoryReq struct {
+	ParentID *uint `json:"parent_id"`
+}
+
+type MergeCategoryReq struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+type TransactionCategoryReq struct {
+	CategoryID *uint `json:"category_id"`
+}
+
+type TagReq struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type TransactionTagsReq struct {
+	TagIDs []uint `json:"tag_ids" binding:"required"`
+}
+
+// CreateCategory creates a new category for the authenticated user.
+func CreateCategory(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request CategoryReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerCategory.WithError(err).Error("Error binding the category")
+			renderError(c, "Error binding the category", bindingError(err))
+			return
+		}
+
+		created, err := categoryService.Create(c.Request.Context(), category.Category{
+			UserID:   GetUserID(c),
+			ParentID: request.ParentID,
+			Name:     request.Name,
+			Kind:     category.CategoryKind(request.Kind),
+			Icon:     request.Icon,
+			Color:    request.Color,
+		})
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error creating the category")
+			renderError(c, "Error creating the category", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: created})
+	}
+}
+
+// GetCategories returns the category tree of the authenticated user.
+func GetCategories(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query GetCategoriesQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerCategory.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		tree, err := categoryService.GetTree(c.Request.Context(), GetUserID(c), query.IncludeArchived)
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the categories")
+			renderError(c, "Error getting the categories", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: tree})
+	}
+}
+
+// UpdateCategory updates the given fields of a category of the authenticated user.
+func UpdateCategory(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request UpdateCategoryReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerCategory.WithError(err).Error("Error binding the category")
+			renderError(c, "Error binding the category", bindingError(err))
+			return
+		}
+
+		updated, err := categoryService.Update(c.Request.Context(), GetUserID(c), id, category.CategoryPatch{
+			Name:     request.Name,
+			Archived: request.Archived,
+			Icon:     request.Icon,
+			Color:    request.Color,
+		})
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error updating the category")
+			renderError(c, "Error updating the category", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: updated})
+	}
+}
+
+// MoveCategory changes the parent of a category of the authenticated user.
+func MoveCategory(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request MoveCategoryReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerCategory.WithError(err).Error("Error binding the move")
+			renderError(c, "Error binding the move", bindingError(err))
+			return
+		}
+
+		moved, err := categoryService.Move(c.Request.Context(), GetUserID(c), id, request.ParentID)
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error moving the category")
+			renderError(c, "Error moving the category", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: moved})
+	}
+}
+
+// MergeCategory merges a category of the authenticated user into another, re-pointing its transactions.
+func MergeCategory(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request MergeCategoryReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerCategory.WithError(err).Error("Error binding the merge")
+			renderError(c, "Error binding the merge", bindingError(err))
+			return
+		}
+
+		target, err := categoryService.Merge(c.Request.Context(), GetUserID(c), id, request.TargetID)
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error merging the category")
+			renderError(c, "Error merging the category", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: target})
+	}
+}
+
+// DeleteCategory soft deletes a category of the authenticated user.
+func DeleteCategory(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		if err := categoryService.Delete(c.Request.Context(), GetUserID(c), id); err != nil {
+			loggerCategory.WithError(err).Error("Error deleting the category")
+			renderError(c, "Error deleting the category", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Category deleted successfully"})
+	}
+}
+
+// SetTransactionCategory sets or removes the category of a transaction of the authenticated user.
+func SetTransactionCategory(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request TransactionCategoryReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerCategory.WithError(err).Error("Error binding the category of the transaction")
+			renderError(c, "Error binding the category of the transaction", bindingError(err))
+			return
+		}
+
+		if err := categoryService.SetTransactionCategory(c.Request.Context(), GetUserID(c), id, request.CategoryID); err != nil {
+			loggerCategory.WithError(err).Error("Error setting the category of the transaction")
+			renderError(c, "Error setting the category of the transaction", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Transaction category updated successfully"})
+	}
+}
+
+// CreateTag creates a new tag for the authenticated user.
+func CreateTag(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request TagReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerCategory.WithError(err).Error("Error binding the tag")
+			renderError(c, "Error binding the tag", bindingError(err))
+			return
+		}
+
+		created, err := categoryService.CreateTag(c.Request.Context(), category.Tag{UserID: GetUserID(c), Name: request.Name})
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error creating the tag")
+			renderError(c, "Error creating the tag", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: created})
+	}
+}
+
+// GetTags returns the tags of the authenticated user.
+func GetTags(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tags, err := categoryService.GetTags(c.Request.Context(), GetUserID(c))
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the tags")
+			renderError(c, "Error getting the tags", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: tags})
+	}
+}
+
+// DeleteTag soft deletes a tag of the authenticated user.
+func DeleteTag(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		if err := categoryService.DeleteTag(c.Request.Context(), GetUserID(c), id); err != nil {
+			loggerCategory.WithError(err).Error("Error deleting the tag")
+			renderError(c, "Error deleting the tag", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Tag deleted successfully"})
+	}
+}
+
+// GetTransactionTags returns the tags of a transaction of the authenticated user.
+func GetTransactionTags(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		tags, err := categoryService.GetTransactionTags(c.Request.Context(), GetUserID(c), id)
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the tags of the transaction")
+			renderError(c, "Error getting the tags of the transaction", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: tags})
+	}
+}
+
+// SetTransactionTags replaces the tags of a transaction of the authenticated user.
+func SetTransactionTags(categoryService category.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request TransactionTagsReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerCategory.WithError(err).Error("Error binding the tags of the transaction")
+			renderError(c, "Error binding the tags of the transaction", bindingError(err))
+			return
+		}
+
+		tags, err := categoryService.SetTransactionTags(c.Request.Context(), GetUserID(c), id, request.TagIDs)
+		if err != nil {
+			loggerCategory.WithError(err).Error("Error setting the tags of the transaction")
+			renderError(c, "Error setting the tags of the transaction", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: tags})
+	}
+}
@@ -2,25 +2,64 @@ package controller
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
 	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
 	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
 )
 
 var loggerFinAsset = logger.Setup("controller.finasset")
 
+// FinancialAssetReq is the request to create a financial asset.
+// The type and the metadata are validated by the asset type registry.
 type FinancialAssetReq struct {
-	Symbol string `json:"symbol" binding:"required"`
-	Name   string `json:"name" binding:"required"`
-	Desc   string `json:"desc" binding:"required"`
-	Type   string `json:"type" binding:"required,oneof=currency stock crypto"`
+	Symbol   string            `json:"symbol" binding:"required"`
+	Name     string            `json:"name" binding:"required"`
+	Desc     string            `json:"desc" binding:"required"`
+	Type     string            `json:"type" binding:"required"`
+	Metadata finasset.Metadata `json:"metadata"`
+}
+
+type UpdateFinancialAssetReq struct {
+	Symbol   *string            `json:"symbol" binding:"omitempty,min=1"`
+	Name     *string            `json:"name" binding:"omitempty,min=1"`
+	Desc     *string            `json:"desc" binding:"omitempty,min=1"`
+	Type     *string            `json:"type" binding:"omitempty,min=1"`
+	Metadata *finasset.Metadata `json:"metadata"`
 }
 
 type GetFinancialAssetsQuery struct {
-	Type   string `form:"type" binding:"omitempty,oneof=currency stock crypto"`
+	Type   string `form:"type"`
 	Symbol string `form:"symbol"`
 	Name   string `form:"name"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=id -id created_at -created_at"`
+}
+
+type PriceReq struct {
+	Timestamp     time.Time       `json:"timestamp" binding:"required"`
+	Open          decimal.Decimal `json:"open" binding:"required"`
+	High          decimal.Decimal `json:"high" binding:"required"`
+	Low           decimal.Decimal `json:"low" binding:"required"`
+	Close         decimal.Decimal `json:"close" binding:"required"`
+	Volume        decimal.Decimal `json:"volume"`
+	Source        string          `json:"source" binding:"required"`
+	QuoteCurrency string          `json:"quote_currency" binding:"required"`
+}
+
+type UpsertPricesReq struct {
+	Prices []PriceReq `json:"prices" binding:"required,min=1,dive"`
+}
+
+type GetPricesQuery struct {
+	From          string `form:"from"`
+	To            string `form:"to"`
+	Interval      string `form:"interval"`
+	QuoteCurrency string `form:"quote_currency"`
 }
 
 // CreateFinancialAsset creates a new financial asset.
@@ -29,24 +68,25 @@ func CreateFinancialAsset(finAssetService finasset.Service) gin.HandlerFunc {
 		var request FinancialAssetReq
 		if err := c.ShouldBindJSON(&request); err != nil {
 			loggerFinAsset.WithError(err).Error("Error binding the financial asset")
-			c.JSON(http.StatusBadRequest, Error{Message: "Error binding the financial asset", Error: err.Error()})
+			renderError(c, "Error binding the financial asset", bindingError(err))
 			return
 		}
 
 		finAsset := finasset.FinancialAsset{
-			Symbol: request.Symbol,
-			Name:   request.Name,
-			Desc:   request.Desc,
-			Type:   finasset.AssetType(request.Type),
+			Symbol:   request.Symbol,
+			Name:     request.Name,
+			Desc:     request.Desc,
+			Type:     finasset.AssetType(request.Type),
+			Metadata: request.Metadata,
 		}
 
-		if err := finAssetService.Create(finAsset); err != nil {
+		if err := finAssetService.Create(c.Request.Context(), finAsset); err != nil {
 			loggerFinAsset.WithError(err).Error("Error creating the financial asset")
-			c.JSON(http.StatusInternalServerError, Error{Message: "Error creating the financial asset", Error: err.Error()})
+			renderError(c, "Error creating the financial asset", err)
 			return
 		}
 
-		c.JSON(200, Success{Message: "Financial asset created successfully"})
+		c.JSON(http.StatusOK, Success{Message: "Financial asset created successfully"})
 	}
 }
 
@@ -56,21 +96,225 @@ func GetFinancialAssets(finAssetService finasset.Service) gin.HandlerFunc {
 		var query GetFinancialAssetsQuery
 		if err := c.ShouldBindQuery(&query); err != nil {
 			loggerFinAsset.WithError(err).Error("Error binding the query")
-			c.JSON(http.StatusBadRequest, Error{Message: "Error binding the query", Error: err.Error()})
+			renderError(c, "Error binding the query", bindingError(err))
 			return
 		}
 
-		finAssets, err := finAssetService.Get(finasset.FinancialAsset{
+		filter := finasset.FinancialAsset{
 			Symbol: query.Symbol,
 			Name:   query.Name,
 			Type:   finasset.AssetType(query.Type),
-		})
+		}
+
+		pagination, err := crosscuting.NewPagination(query.Limit, query.Cursor, query.Sort, filter)
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the pagination")
+			renderError(c, "Error getting the pagination", err)
+			return
+		}
+
+		page, err := finAssetService.Get(c.Request.Context(), filter, pagination)
 		if err != nil {
 			loggerFinAsset.WithError(err).Error("Error getting the financial assets")
-			c.JSON(http.StatusInternalServerError, Error{Message: "Error getting the financial assets", Error: err.Error()})
+			renderError(c, "Error getting the financial assets", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, PagedData{Data: page.Items, NextCursor: page.NextCursor, Total: page.Total})
+	}
+}
+
+// GetFinancialAsset returns the financial asset with the given id.
+func GetFinancialAsset(finAssetService finasset.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		finAsset, err := finAssetService.GetByID(c.Request.Context(), id)
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the financial asset")
+			renderError(c, "Error getting the financial asset", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: finAsset})
+	}
+}
+
+// UpdateFinancialAsset updates the given fields of a financial asset.
+func UpdateFinancialAsset(finAssetService finasset.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request UpdateFinancialAssetReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerFinAsset.WithError(err).Error("Error binding the financial asset")
+			renderError(c, "Error binding the financial asset", bindingError(err))
+			return
+		}
+
+		patch := finasset.FinancialAssetPatch{
+			Symbol:   request.Symbol,
+			Name:     request.Name,
+			Desc:     request.Desc,
+			Metadata: request.Metadata,
+		}
+
+		if request.Type != nil {
+			assetType := finasset.AssetType(*request.Type)
+			patch.Type = &assetType
+		}
+
+		finAsset, err := finAssetService.Update(c.Request.Context(), id, patch)
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error updating the financial asset")
+			renderError(c, "Error updating the financial asset", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: finAsset})
+	}
+}
+
+// DeleteFinancialAsset soft deletes a financial asset.
+func DeleteFinancialAsset(finAssetService finasset.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		if err := finAssetService.Delete(c.Request.Context(), id); err != nil {
+			loggerFinAsset.WithError(err).Error("Error deleting the financial asset")
+			renderError(c, "Error deleting the financial asset", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Financial asset deleted successfully"})
+	}
+}
+
+// RestoreFinancialAsset restores a soft deleted financial asset.
+func RestoreFinancialAsset(finAssetService finasset.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		finAsset, err := finAssetService.Restore(c.Request.Context(), id)
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error restoring the financial asset")
+			renderError(c, "Error restoring the financial asset", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: finAsset})
+	}
+}
+
+// GetFinancialAssetTypes returns the registered asset types and their metadata fields.
+func GetFinancialAssetTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, Data{Data: finasset.Types()})
+}
+
+// UpsertPrices stores a batch of prices of a financial asset.
+func UpsertPrices(finAssetService finasset.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request UpsertPricesReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerFinAsset.WithError(err).Error("Error binding the prices")
+			renderError(c, "Error binding the prices", bindingError(err))
+			return
+		}
+
+		prices := make([]finasset.Price, len(request.Prices))
+		for i, price := range request.Prices {
+			prices[i] = finasset.Price{
+				Timestamp:     price.Timestamp,
+				Open:          price.Open,
+				High:          price.High,
+				Low:           price.Low,
+				Close:         price.Close,
+				Volume:        price.Volume,
+				Source:        price.Source,
+				QuoteCurrency: price.QuoteCurrency,
+			}
+		}
+
+		if err := finAssetService.UpsertPrices(c.Request.Context(), id, prices); err != nil {
+			loggerFinAsset.WithError(err).Error("Error upserting the prices")
+			renderError(c, "Error upserting the prices", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Prices stored successfully"})
+	}
+}
+
+// GetPrices returns the price history of a financial asset.
+func GetPrices(finAssetService finasset.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var query GetPricesQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerFinAsset.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		from, err := parseDate("from", query.From)
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error parsing the from date")
+			renderError(c, "Error parsing the from date", err)
+			return
+		}
+
+		to, err := parseEndDate("to", query.To)
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error parsing the to date")
+			renderError(c, "Error parsing the to date", err)
+			return
+		}
+
+		prices, err := finAssetService.GetPrices(c.Request.Context(), id, finasset.PriceQuery{
+			From:          from,
+			To:            to,
+			Interval:      finasset.Interval(query.Interval),
+			QuoteCurrency: query.QuoteCurrency,
+		})
+		if err != nil {
+			loggerFinAsset.WithError(err).Error("Error getting the prices")
+			renderError(c, "Error getting the prices", err)
 			return
 		}
 
-		c.JSON(http.StatusOK, Data{Data: finAssets})
+		c.JSON(http.StatusOK, Data{Data: prices})
 	}
 }
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/fx"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+var loggerFX = logger.Setup("controller.fx")
+
+type ConvertQuery struct {
+	From   string          `form:"from" binding:"required"`
+	To     string          `form:"to" binding:"required"`
+	Amount decimal.Decimal `form:"amount" binding:"required"`
+	Date   string          `form:"date"`
+}
+
+// Convert converts an amount between two currencies at a given date, today by default.
+// A date without time uses the rates of the whole day.
+func Convert(fxService fx.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query ConvertQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerFX.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		date, err := parseEndDate("date", query.Date)
+		if err != nil {
+			loggerFX.WithError(err).Error("Error parsing the date")
+			renderError(c, "Error parsing the date", err)
+			return
+		}
+
+		if date.IsZero() {
+			date = time.Now()
+		}
+
+		conversion, err := fxService.Convert(c.Request.Context(), money.New(query.Amount, query.From), query.To, date)
+		if err != nil {
+			loggerFX.WithError(err).Error("Error converting the amount")
+			renderError(c, "Error converting the amount", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: conversion})
+	}
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/holding"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+var loggerHolding = logger.Setup("controller.holding")
+
+type TradeReq struct {
+	AccountID uint            `json:"account_id" binding:"required"`
+	AssetID   uint            `json:"asset_id" binding:"required"`
+	Side      string          `json:"side" binding:"required,oneof=buy sell"`
+	Date      string          `json:"date" binding:"required"`
+	Quantity  decimal.Decimal `json:"quantity" binding:"required"`
+	Price     decimal.Decimal `json:"price"`
+	Fee       decimal.Decimal `json:"fee"`
+}
+
+type HoldingsQuery struct {
+	AccountID uint `form:"account_id"`
+	AssetID   uint `form:"asset_id"`
+}
+
+// CreateTrade records a buy or a sell of a financial asset for the authenticated user.
+func CreateTrade(holdingService holding.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request TradeReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerHolding.WithError(err).Error("Error binding the trade")
+			renderError(c, "Error binding the trade", bindingError(err))
+			return
+		}
+
+		date, err := parseDate("date", request.Date)
+		if err != nil {
+			loggerHolding.WithError(err).Error("Error parsing the date")
+			renderError(c, "Error parsing the date", err)
+			return
+		}
+
+		created, err := holdingService.CreateTrade(c.Request.Context(), holding.Trade{
+			UserID:    GetUserID(c),
+			AccountID: request.AccountID,
+			AssetID:   request.AssetID,
+			Side:      holding.Side(request.Side),
+			Date:      date,
+			Quantity:  request.Quantity,
+			Price:     money.Money{Amount: request.Price},
+			Fee:       money.Money{Amount: request.Fee},
+		})
+		if err != nil {
+			loggerHolding.WithError(err).Error("Error creating the trade")
+			renderError(c, "Error creating the trade", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: created})
+	}
+}
+
+// GetTrades returns the trades of the authenticated user, optionally by account and asset.
+func GetTrades(holdingService holding.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, ok := holdingFilter(c)
+		if !ok {
+			return
+		}
+
+		trades, err := holdingService.GetTrades(c.Request.Context(), GetUserID(c), filter)
+		if err != nil {
+			loggerHolding.WithError(err).Error("Error getting the trades")
+			renderError(c, "Error getting the trades", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: trades})
+	}
+}
+
+// GetLots returns the open tax lots of the authenticated user, optionally by account and asset.
+func GetLots(holdingService holding.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, ok := holdingFilter(c)
+		if !ok {
+			return
+		}
+
+		lots, err := holdingService.GetLots(c.Request.Context(), GetUserID(c), filter)
+		if err != nil {
+			loggerHolding.WithError(err).Error("Error getting the lots")
+			renderError(c, "Error getting the lots", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: lots})
+	}
+}
+
+// GetHoldings returns the current holdings of the authenticated user, optionally by account and asset.
+func GetHoldings(holdingService holding.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, ok := holdingFilter(c)
+		if !ok {
+			return
+		}
+
+		holdings, err := holdingService.GetHoldings(c.Request.Context(), GetUserID(c), filter)
+		if err != nil {
+			loggerHolding.WithError(err).Error("Error getting the holdings")
+			renderError(c, "Error getting the holdings", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: holdings})
+	}
+}
+
+// holdingFilter binds the filter of the holdings query, rendering the error when it is not valid.
+func holdingFilter(c *gin.Context) (holding.TradeFilter, bool) {
+	var query HoldingsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		loggerHolding.WithError(err).Error("Error binding the query")
+		renderError(c, "Error binding the query", bindingError(err))
+		return holding.TradeFilter{}, false
+	}
+
+	return holding.TradeFilter{AccountID: query.AccountID, AssetID: query.AssetID}, true
+}
@@ -0,0 +1,308 @@
+package controller
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/importer"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerImporter = logger.Setup("controller.importer")
+
+// maxImportSize is the biggest statement file accepted, in bytes.
+const maxImportSize = 10 << 20
+
+type ImportReq struct {
+	IncomeAccountID  uint `form:"income_account_id" binding:"required"`
+	ExpenseAccountID uint `form:"expense_account_id" binding:"required"`
+}
+
+type CSVPreviewReq struct {
+	ProfileID uint `form:"profile_id" binding:"required"`
+}
+
+type CSVImportReq struct {
+	ProfileID        uint `form:"profile_id" binding:"required"`
+	IncomeAccountID  uint `form:"income_account_id" binding:"required"`
+	ExpenseAccountID uint `form:"expense_account_id" binding:"required"`
+}
+
+type ImportProfileReq struct {
+	Name             string `json:"name" binding:"required"`
+	Delimiter        string `json:"delimiter"`
+	Encoding         string `json:"encoding" binding:"omitempty,oneof=utf-8 latin-1"`
+	SkipRows         int    `json:"skip_rows" binding:"min=0"`
+	HasHeader        bool   `json:"has_header"`
+	DateColumn       string `json:"date_column" binding:"required"`
+	DateFormat       string `json:"date_format"`
+	AmountColumn     string `json:"amount_column"`
+	DebitColumn      string `json:"debit_column"`
+	CreditColumn     string `json:"credit_column"`
+	PayeeColumn      string `json:"payee_column"`
+	MemoColumn       string `json:"memo_column"`
+	IDColumn         string `json:"id_column"`
+	DecimalSeparator string `json:"decimal_separator" binding:"omitempty,oneof=. ,"`
+}
+
+// ImportStatement imports the OFX or QFX statement uploaded in the file field into an account of the authenticated user.
+func ImportStatement(importerService importer.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+		var request ImportReq
+		if err := c.ShouldBind(&request); err != nil {
+			loggerImporter.WithError(err).Error("Error binding the import")
+			renderError(c, "Error binding the import", bindingError(err))
+			return
+		}
+
+		file, ok := importFile(c)
+		if !ok {
+			return
+		}
+		defer file.Close()
+
+		options := importer.ImportOptions{IncomeAccountID: request.IncomeAccountID, ExpenseAccountID: request.ExpenseAccountID}
+
+		summary, err := importerService.ImportOFX(c.Request.Context(), GetUserID(c), id, options, file)
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error importing the statement")
+			renderError(c, "Error importing the statement", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: summary})
+	}
+}
+
+// PreviewCSVImport reads the CSV file uploaded in the file field with an import profile of the authenticated user,
+// and returns its rows without importing them.
+func PreviewCSVImport(importerService importer.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+		var request CSVPreviewReq
+		if err := c.ShouldBind(&request); err != nil {
+			loggerImporter.WithError(err).Error("Error binding the preview")
+			renderError(c, "Error binding the preview", bindingError(err))
+			return
+		}
+
+		file, ok := importFile(c)
+		if !ok {
+			return
+		}
+		defer file.Close()
+
+		previews, err := importerService.PreviewCSV(c.Request.Context(), GetUserID(c), id, request.ProfileID, file)
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error previewing the file")
+			renderError(c, "Error previewing the file", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: previews})
+	}
+}
+
+// ImportCSV imports the CSV file uploaded in the file field into an account of the authenticated user,
+// reading it with one of their import profiles.
+func ImportCSV(importerService importer.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+		var request CSVImportReq
+		if err := c.ShouldBind(&request); err != nil {
+			loggerImporter.WithError(err).Error("Error binding the import")
+			renderError(c, "Error binding the import", bindingError(err))
+			return
+		}
+
+		file, ok := importFile(c)
+		if !ok {
+			return
+		}
+		defer file.Close()
+
+		options := importer.ImportOptions{IncomeAccountID: request.IncomeAccountID, ExpenseAccountID: request.ExpenseAccountID}
+
+		summary, err := importerService.ImportCSV(c.Request.Context(), GetUserID(c), id, request.ProfileID, options, file)
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error importing the file")
+			renderError(c, "Error importing the file", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: summary})
+	}
+}
+
+// CreateImportProfile creates a new CSV import profile for the authenticated user.
+func CreateImportProfile(importerService importer.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request ImportProfileReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerImporter.WithError(err).Error("Error binding the import profile")
+			renderError(c, "Error binding the import profile", bindingError(err))
+			return
+		}
+
+		created, err := importerService.CreateProfile(c.Request.Context(), importProfile(request, GetUserID(c), 0))
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error creating the import profile")
+			renderError(c, "Error creating the import profile", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: created})
+	}
+}
+
+// GetImportProfiles returns the CSV import profiles of the authenticated user.
+func GetImportProfiles(importerService importer.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		profiles, err := importerService.GetProfiles(c.Request.Context(), GetUserID(c))
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error getting the import profiles")
+			renderError(c, "Error getting the import profiles", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: profiles})
+	}
+}
+
+// GetImportProfile returns the CSV import profile of the authenticated user with the given id.
+func GetImportProfile(importerService importer.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		found, err := importerService.GetProfile(c.Request.Context(), GetUserID(c), id)
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error getting the import profile")
+			renderError(c, "Error getting the import profile", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: found})
+	}
+}
+
+// UpdateImportProfile replaces the settings of a CSV import profile of the authenticated user.
+func UpdateImportProfile(importerService importer.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request ImportProfileReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerImporter.WithError(err).Error("Error binding the import profile")
+			renderError(c, "Error binding the import profile", bindingError(err))
+			return
+		}
+
+		updated, err := importerService.UpdateProfile(c.Request.Context(), importProfile(request, GetUserID(c), id))
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error updating the import profile")
+			renderError(c, "Error updating the import profile", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: updated})
+	}
+}
+
+// DeleteImportProfile deletes a CSV import profile of the authenticated user.
+func DeleteImportProfile(importerService importer.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerImporter.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		if err := importerService.DeleteProfile(c.Request.Context(), GetUserID(c), id); err != nil {
+			loggerImporter.WithError(err).Error("Error deleting the import profile")
+			renderError(c, "Error deleting the import profile", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Import profile deleted successfully"})
+	}
+}
+
+// importProfile returns the import profile of the request for the user.
+func importProfile(r ImportProfileReq, userID, id uint) importer.Profile {
+	profile := importer.Profile{
+		UserID:           userID,
+		Name:             r.Name,
+		Delimiter:        r.Delimiter,
+		Encoding:         importer.Encoding(r.Encoding),
+		SkipRows:         r.SkipRows,
+		HasHeader:        r.HasHeader,
+		DateColumn:       r.DateColumn,
+		DateFormat:       r.DateFormat,
+		AmountColumn:     r.AmountColumn,
+		DebitColumn:      r.DebitColumn,
+		CreditColumn:     r.CreditColumn,
+		PayeeColumn:      r.PayeeColumn,
+		MemoColumn:       r.MemoColumn,
+		IDColumn:         r.IDColumn,
+		DecimalSeparator: r.DecimalSeparator,
+	}
+	profile.ID = id
+
+	return profile
+}
+
+// importFile opens the file uploaded in the file field, rendering the error when it can not.
+func importFile(c *gin.Context) (multipart.File, bool) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		loggerImporter.WithError(err).Error("Error getting the file")
+		renderError(c, "Error getting the file", bindingError(err))
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		loggerImporter.WithError(err).Error("Error opening the file")
+		renderError(c, "Error opening the file", err)
+		return nil, false
+	}
+
+	return file, true
+}
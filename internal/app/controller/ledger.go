@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+var loggerLedger = logger.Setup("controller.ledger")
+
+type PostingReq struct {
+	AccountID uint            `json:"account_id" binding:"required"`
+	Amount    decimal.Decimal `json:"amount" binding:"required"`
+	Memo      string          `json:"memo"`
+}
+
+type TransactionReq struct {
+	Date        time.Time    `json:"date"`
+	Kind        string       `json:"kind" binding:"required,oneof=income expense transfer adjustment"`
+	Description string       `json:"description"`
+	Postings    []PostingReq `json:"postings" binding:"required,min=2,dive"`
+}
+
+type BalanceQuery struct {
+	AsOf string `form:"as_of"`
+}
+
+type EntriesQuery struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+// CreateTransaction records a double-entry transaction of the authenticated user.
+func CreateTransaction(ledgerService ledger.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request TransactionReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerLedger.WithError(err).Error("Error binding the transaction")
+			renderError(c, "Error binding the transaction", bindingError(err))
+			return
+		}
+
+		postings := make([]ledger.Posting, len(request.Postings))
+		for i, posting := range request.Postings {
+			postings[i] = ledger.Posting{
+				AccountID: posting.AccountID,
+				Amount:    money.Money{Amount: posting.Amount},
+				Memo:      posting.Memo,
+			}
+		}
+
+		txn, err := ledgerService.CreateTransaction(c.Request.Context(), ledger.Transaction{
+			UserID:      GetUserID(c),
+			Date:        request.Date,
+			Kind:        ledger.TransactionKind(request.Kind),
+			Description: request.Description,
+			Postings:    postings,
+		})
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error creating the transaction")
+			renderError(c, "Error creating the transaction", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: txn})
+	}
+}
+
+// GetTransaction returns a transaction of the authenticated user with its postings.
+func GetTransaction(ledgerService ledger.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		txn, err := ledgerService.GetTransaction(c.Request.Context(), GetUserID(c), id)
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error getting the transaction")
+			renderError(c, "Error getting the transaction", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: txn})
+	}
+}
+
+// DeleteTransaction soft deletes a transaction of the authenticated user.
+func DeleteTransaction(ledgerService ledger.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		if err := ledgerService.DeleteTransaction(c.Request.Context(), GetUserID(c), id); err != nil {
+			loggerLedger.WithError(err).Error("Error deleting the transaction")
+			renderError(c, "Error deleting the transaction", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Transaction deleted successfully"})
+	}
+}
+
+// GetAccountBalance returns the balance of an account of the authenticated user at a date, now by default.
+func GetAccountBalance(ledgerService ledger.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var query BalanceQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerLedger.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		asOf, err := parseEndDate("as_of", query.AsOf)
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error parsing the as_of date")
+			renderError(c, "Error parsing the as_of date", err)
+			return
+		}
+
+		if asOf.IsZero() {
+			asOf = time.Now()
+		}
+
+		balance, err := ledgerService.GetBalance(c.Request.Context(), GetUserID(c), id, asOf)
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error getting the balance")
+			renderError(c, "Error getting the balance", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: balance})
+	}
+}
+
+// GetAccountEntries returns the postings of an account of the authenticated user with the running balance.
+func GetAccountEntries(ledgerService ledger.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var query EntriesQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerLedger.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		from, err := parseDate("from", query.From)
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error parsing the from date")
+			renderError(c, "Error parsing the from date", err)
+			return
+		}
+
+		to, err := parseEndDate("to", query.To)
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error parsing the to date")
+			renderError(c, "Error parsing the to date", err)
+			return
+		}
+
+		entries, err := ledgerService.GetEntries(c.Request.Context(), GetUserID(c), id, from, to)
+		if err != nil {
+			loggerLedger.WithError(err).Error("Error getting the entries")
+			renderError(c, "Error getting the entries", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: entries})
+	}
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/jwt"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+const (
+	// UserIDKey is the key of the authenticated user id in the gin context.
+	UserIDKey = "user_id"
+
+	// statusClientClosedRequest is the non standard status for the requests canceled by the client.
+	statusClientClosedRequest = 499
+)
+
+var (
+	loggerMiddleware = logger.Setup("controller.middleware")
+
+	errMissingToken   = crosscuting.NewTypedError(crosscuting.ErrUnauthorized, "MISSING_ACCESS_TOKEN", "missing access token error")
+	errNotAdmin       = crosscuting.NewTypedError(crosscuting.ErrForbidden, "ADMIN_REQUIRED", "admin required error")
+	errInvalidRequest = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_REQUEST", "invalid request error")
+
+	// statusByKind maps the kinds of the error taxonomy to the http status of the response.
+	statusByKind = map[error]int{
+		crosscuting.ErrNotFound:     http.StatusNotFound,
+		crosscuting.ErrConflict:     http.StatusConflict,
+		crosscuting.ErrValidation:   http.StatusUnprocessableEntity,
+		crosscuting.ErrUnauthorized: http.StatusUnauthorized,
+		crosscuting.ErrForbidden:    http.StatusForbidden,
+		crosscuting.ErrTimeout:      http.StatusGatewayTimeout,
+		crosscuting.ErrCanceled:     statusClientClosedRequest,
+		crosscuting.ErrInternal:     http.StatusInternalServerError,
+	}
+)
+
+// ErrorHandler renders the last error registered in the context with the status of its kind.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		ginErr := c.Errors.Last()
+		if ginErr == nil || c.Writer.Written() {
+			return
+		}
+
+		message, ok := ginErr.Meta.(string)
+		if !ok {
+			message = "Error processing the request"
+		}
+
+		c.JSON(statusByKind[crosscuting.KindOf(ginErr.Err)], Error{
+			Message: message,
+			Error:   ginErr.Err.Error(),
+			Code:    crosscuting.CodeOf(ginErr.Err),
+		})
+	}
+}
+
+// Authenticate validates the bearer access token and stores the user id in the context.
+func Authenticate(tokens jwt.JWT) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			desc := "Missing or malformed authorization header"
+			loggerMiddleware.Error(desc)
+			renderError(c, desc, errMissingToken)
+			c.Abort()
+			return
+		}
+
+		claims, err := tokens.Validate(token)
+		if err != nil {
+			loggerMiddleware.WithError(err).Error("Error validating the access token")
+			renderError(c, "Invalid access token", err)
+			c.Abort()
+			return
+		}
+
+		userID, err := claims.UserID()
+		if err != nil {
+			loggerMiddleware.WithError(err).Error("Error getting the user id from the access token")
+			renderError(c, "Invalid access token", err)
+			c.Abort()
+			return
+		}
+
+		c.Set(UserIDKey, userID)
+		c.Next()
+	}
+}
+
+// RequireAdmin only lets the admin users through, it must run after the Authenticate middleware.
+func RequireAdmin(adminUserIDs []uint) gin.HandlerFunc {
+	admins := make(map[uint]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = true
+	}
+
+	return func(c *gin.Context) {
+		if userID := GetUserID(c); !admins[userID] {
+			desc := fmt.Sprintf("The user %d is not an admin", userID)
+			loggerMiddleware.Error(desc)
+			renderError(c, "Only the admins can do this operation", fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, errNotAdmin))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetUserID returns the id of the authenticated user stored by the Authenticate middleware.
+func GetUserID(c *gin.Context) uint {
+	return c.GetUint(UserIDKey)
+}
+
+// renderError registers the error in the context to be rendered by the ErrorHandler middleware.
+func renderError(c *gin.Context, message string, err error) {
+	_ = c.Error(err).SetMeta(message)
+}
+
+// bindingError classifies an error binding the request as a validation error.
+func bindingError(err error) error {
+	return fmt.Errorf(crosscuting.WrapLabel, "Error binding the request", errInvalidRequest, err.Error())
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/networth"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerNetWorth = logger.Setup("controller.networth")
+
+type NetWorthQuery struct {
+	Date string `form:"date"`
+}
+
+type NetWorthHistoryQuery struct {
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Interval string `form:"interval" binding:"omitempty,oneof=daily weekly monthly"`
+}
+
+// GetNetWorth returns the net worth of the authenticated user at the end of a day, today by default,
+// with its breakdown by account type and by asset class.
+func GetNetWorth(netWorthService networth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query NetWorthQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerNetWorth.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		date, err := parseDate("date", query.Date)
+		if err != nil {
+			loggerNetWorth.WithError(err).Error("Error parsing the date")
+			renderError(c, "Error parsing the date", err)
+			return
+		}
+
+		if date.IsZero() {
+			date = time.Now()
+		}
+
+		snapshot, err := netWorthService.Compute(c.Request.Context(), GetUserID(c), date)
+		if err != nil {
+			loggerNetWorth.WithError(err).Error("Error computing the net worth")
+			renderError(c, "Error computing the net worth", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: snapshot})
+	}
+}
+
+// GetNetWorthHistory returns the net worth snapshots of the authenticated user between the dates,
+// one per interval, daily by default.
+func GetNetWorthHistory(netWorthService networth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query NetWorthHistoryQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerNetWorth.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		from, err := parseDate("from", query.From)
+		if err != nil {
+			loggerNetWorth.WithError(err).Error("Error parsing the from date")
+			renderError(c, "Error parsing the from date", err)
+			return
+		}
+
+		to, err := parseEndDate("to", query.To)
+		if err != nil {
+			loggerNetWorth.WithError(err).Error("Error parsing the to date")
+			renderError(c, "Error parsing the to date", err)
+			return
+		}
+
+		interval := networth.Interval(query.Interval)
+		if interval == "" {
+			interval = networth.Daily
+		}
+
+		history, err := netWorthService.GetHistory(c.Request.Context(), GetUserID(c), from, to, interval)
+		if err != nil {
+			loggerNetWorth.WithError(err).Error("Error getting the net worth history")
+			renderError(c, "Error getting the net worth history", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: history})
+	}
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+)
+
+var (
+	errInvalidID   = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_ID", "invalid id error")
+	errInvalidDate = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_DATE", "invalid date error")
+)
+
+const dayLayout = "2006-01-02"
+
+// dateLayouts are the layouts accepted for the dates in the query params.
+var dateLayouts = []string{time.RFC3339, dayLayout}
+
+// idParam returns the path parameter with the given name as a database id.
+func idParam(c *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf(crosscuting.WrapLabelWithoutError, "The "+name+" param must be a positive integer", errInvalidID)
+	}
+
+	return uint(id), nil
+}
+
+// parseDate parses a date of a query param, an empty value is the zero time.
+// Dates without time are the start of the day in UTC.
+func parseDate(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(crosscuting.WrapLabel, "The "+name+" param must be a RFC3339 date or YYYY-MM-DD", errInvalidDate, value)
+}
+
+// parseEndDate parses a date of a query param used as the end of a range, an empty value is the zero time.
+// Dates without time are the end of the day in UTC, so the whole day is included.
+func parseEndDate(name, value string) (time.Time, error) {
+	date, err := parseDate(name, value)
+	if err != nil || len(value) != len(dayLayout) {
+		return date, err
+	}
+
+	return date.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/portfolio"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerPortfolio = logger.Setup("controller.portfolio")
+
+type ValuationQuery struct {
+	AsOf string `form:"as_of"`
+}
+
+type PnLQuery struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+// GetPortfolioValuation returns the value of the holdings of the authenticated user at a date, now by default.
+func GetPortfolioValuation(portfolioService portfolio.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query ValuationQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerPortfolio.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		asOf, err := parseEndDate("as_of", query.AsOf)
+		if err != nil {
+			loggerPortfolio.WithError(err).Error("Error parsing the as of date")
+			renderError(c, "Error parsing the as of date", err)
+			return
+		}
+
+		if asOf.IsZero() {
+			asOf = time.Now()
+		}
+
+		valuation, err := portfolioService.GetValuation(c.Request.Context(), GetUserID(c), asOf)
+		if err != nil {
+			loggerPortfolio.WithError(err).Error("Error getting the valuation")
+			renderError(c, "Error getting the valuation", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: valuation})
+	}
+}
+
+// GetPortfolioPnL returns the realized and unrealized gains of the authenticated user between the dates.
+// Without dates, it covers all the sales up to now.
+func GetPortfolioPnL(portfolioService portfolio.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query PnLQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerPortfolio.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		from, err := parseDate("from", query.From)
+		if err != nil {
+			loggerPortfolio.WithError(err).Error("Error parsing the from date")
+			renderError(c, "Error parsing the from date", err)
+			return
+		}
+
+		to, err := parseEndDate("to", query.To)
+		if err != nil {
+			loggerPortfolio.WithError(err).Error("Error parsing the to date")
+			renderError(c, "Error parsing the to date", err)
+			return
+		}
+
+		if to.IsZero() {
+			to = time.Now()
+		}
+
+		pnl, err := portfolioService.GetPnL(c.Request.Context(), GetUserID(c), from, to)
+		if err != nil {
+			loggerPortfolio.WithError(err).Error("Error getting the profit and loss")
+			renderError(c, "Error getting the profit and loss", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: pnl})
+	}
+}
@@ -0,0 +1,319 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/app/domains/recurring"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRecurring = logger.Setup("controller.recurring")
+
+// defaultPreview is the number of occurrences of a preview when it is not given.
+const defaultPreview = 10
+
+type TemplateReq struct {
+	Name        string       `json:"name" binding:"required"`
+	Kind        string       `json:"kind" binding:"required,oneof=income expense transfer adjustment"`
+	Description string       `json:"description"`
+	CategoryID  *uint        `json:"category_id"`
+	Postings    []PostingReq `json:"postings" binding:"required,min=2,dive"`
+	Frequency   string       `json:"frequency" binding:"required,oneof=daily weekly monthly yearly"`
+	Interval    int          `json:"interval" binding:"omitempty,min=1"`
+	ByDay       string       `json:"by_day"`
+	StartDate   string       `json:"start_date" binding:"required"`
+	EndDate     string       `json:"end_date"`
+	Count       *int         `json:"count" binding:"omitempty,min=1"`
+}
+
+type SplitTemplateReq struct {
+	Date        string       `json:"date" binding:"required"`
+	Name        *string      `json:"name" binding:"omitempty,min=1"`
+	Description *string      `json:"description"`
+	CategoryID  *uint        `json:"category_id"`
+	Postings    []PostingReq `json:"postings" binding:"omitempty,min=2,dive"`
+	Frequency   *string      `json:"frequency" binding:"omitempty,oneof=daily weekly monthly yearly"`
+	Interval    *int         `json:"interval" binding:"omitempty,min=1"`
+	ByDay       *string      `json:"by_day"`
+	EndDate     *string      `json:"end_date"`
+	Count       *int         `json:"count" binding:"omitempty,min=1"`
+}
+
+type SkipOccurrenceReq struct {
+	Date string `json:"date" binding:"required"`
+}
+
+type PreviewQuery struct {
+	From  string `form:"from"`
+	Count int    `form:"count" binding:"omitempty,min=1,max=100"`
+}
+
+// CreateRecurringTemplate creates a new recurring transaction for the authenticated user.
+func CreateRecurringTemplate(recurringService recurring.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request TemplateReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerRecurring.WithError(err).Error("Error binding the template")
+			renderError(c, "Error binding the template", bindingError(err))
+			return
+		}
+
+		startDate, err := parseDate("start_date", request.StartDate)
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error parsing the start date")
+			renderError(c, "Error parsing the start date", err)
+			return
+		}
+
+		endDate, err := parseEndDate("end_date", request.EndDate)
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error parsing the end date")
+			renderError(c, "Error parsing the end date", err)
+			return
+		}
+
+		interval := request.Interval
+		if interval == 0 {
+			interval = 1
+		}
+
+		schedule := recurring.Schedule{
+			Frequency: recurring.Frequency(request.Frequency),
+			Interval:  interval,
+			ByDay:     request.ByDay,
+			StartDate: startDate,
+			Count:     request.Count,
+		}
+
+		if !endDate.IsZero() {
+			schedule.EndDate = &endDate
+		}
+
+		created, err := recurringService.Create(c.Request.Context(), recurring.Template{
+			UserID:      GetUserID(c),
+			Name:        request.Name,
+			Kind:        ledger.TransactionKind(request.Kind),
+			Description: request.Description,
+			CategoryID:  request.CategoryID,
+			Postings:    templatePostings(request.Postings),
+			Schedule:    schedule,
+		})
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error creating the template")
+			renderError(c, "Error creating the template", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: created})
+	}
+}
+
+// GetRecurringTemplates returns the recurring transactions of the authenticated user.
+func GetRecurringTemplates(recurringService recurring.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		templates, err := recurringService.Get(c.Request.Context(), GetUserID(c))
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error getting the templates")
+			renderError(c, "Error getting the templates", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: templates})
+	}
+}
+
+// GetRecurringTemplate returns the recurring transaction of the authenticated user with the given id.
+func GetRecurringTemplate(recurringService recurring.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		template, err := recurringService.GetByID(c.Request.Context(), GetUserID(c), id)
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error getting the template")
+			renderError(c, "Error getting the template", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: template})
+	}
+}
+
+// DeleteRecurringTemplate soft deletes a recurring transaction of the authenticated user.
+func DeleteRecurringTemplate(recurringService recurring.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		if err := recurringService.Delete(c.Request.Context(), GetUserID(c), id); err != nil {
+			loggerRecurring.WithError(err).Error("Error deleting the template")
+			renderError(c, "Error deleting the template", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Recurring transaction deleted successfully"})
+	}
+}
+
+// PreviewRecurringTemplate returns the next occurrences of a recurring transaction of the authenticated user.
+func PreviewRecurringTemplate(recurringService recurring.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var query PreviewQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			loggerRecurring.WithError(err).Error("Error binding the query")
+			renderError(c, "Error binding the query", bindingError(err))
+			return
+		}
+
+		from, err := parseDate("from", query.From)
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error parsing the from date")
+			renderError(c, "Error parsing the from date", err)
+			return
+		}
+
+		if from.IsZero() {
+			from = time.Now()
+		}
+
+		count := query.Count
+		if count == 0 {
+			count = defaultPreview
+		}
+
+		occurrences, err := recurringService.Preview(c.Request.Context(), GetUserID(c), id, from, count)
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error previewing the template")
+			renderError(c, "Error previewing the template", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: occurrences})
+	}
+}
+
+// SkipRecurringOccurrence skips an occurrence of a recurring transaction of the authenticated user.
+func SkipRecurringOccurrence(recurringService recurring.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request SkipOccurrenceReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerRecurring.WithError(err).Error("Error binding the occurrence")
+			renderError(c, "Error binding the occurrence", bindingError(err))
+			return
+		}
+
+		date, err := parseDate("date", request.Date)
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error parsing the date")
+			renderError(c, "Error parsing the date", err)
+			return
+		}
+
+		if err := recurringService.Skip(c.Request.Context(), GetUserID(c), id, date); err != nil {
+			loggerRecurring.WithError(err).Error("Error skipping the occurrence")
+			renderError(c, "Error skipping the occurrence", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "Occurrence skipped successfully"})
+	}
+}
+
+// SplitRecurringTemplate edits an occurrence of a recurring transaction of the authenticated user
+// and the following ones, returning the new template that holds them.
+func SplitRecurringTemplate(recurringService recurring.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := idParam(c, "id")
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error getting the id param")
+			renderError(c, "Error getting the id param", err)
+			return
+		}
+
+		var request SplitTemplateReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerRecurring.WithError(err).Error("Error binding the template")
+			renderError(c, "Error binding the template", bindingError(err))
+			return
+		}
+
+		date, err := parseDate("date", request.Date)
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error parsing the date")
+			renderError(c, "Error parsing the date", err)
+			return
+		}
+
+		patch := recurring.TemplatePatch{
+			Name:        request.Name,
+			Description: request.Description,
+			CategoryID:  request.CategoryID,
+			Interval:    request.Interval,
+			ByDay:       request.ByDay,
+			Count:       request.Count,
+		}
+
+		if request.Postings != nil {
+			patch.Postings = templatePostings(request.Postings)
+		}
+
+		if request.Frequency != nil {
+			frequency := recurring.Frequency(*request.Frequency)
+			patch.Frequency = &frequency
+		}
+
+		if request.EndDate != nil {
+			endDate, err := parseEndDate("end_date", *request.EndDate)
+			if err != nil {
+				loggerRecurring.WithError(err).Error("Error parsing the end date")
+				renderError(c, "Error parsing the end date", err)
+				return
+			}
+
+			patch.EndDate = &endDate
+		}
+
+		created, err := recurringService.Split(c.Request.Context(), GetUserID(c), id, date, patch)
+		if err != nil {
+			loggerRecurring.WithError(err).Error("Error splitting the template")
+			renderError(c, "Error splitting the template", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: created})
+	}
+}
+
+func templatePostings(requests []PostingReq) recurring.TemplatePostings {
+	postings := make(recurring.TemplatePostings, len(requests))
+	for i, posting := range requests {
+		postings[i] = recurring.TemplatePosting{AccountID: posting.AccountID, Amount: posting.Amount, Memo: posting.Memo}
+	}
+
+	return postings
+}
@@ -5,6 +5,7 @@ type (
 	Error struct {
 		Message string `json:"message"`
 		Error   string `json:"error"`
+		Code    string `json:"code"`
 	}
 
 	// Success is the struct for the success response.
@@ -16,4 +17,11 @@ type (
 	Data struct {
 		Data interface{} `json:"data"`
 	}
+
+	// PagedData is the struct for the paginated data response.
+	PagedData struct {
+		Data       interface{} `json:"data"`
+		NextCursor string      `json:"next_cursor"`
+		Total      int64       `json:"total"`
+	}
 )
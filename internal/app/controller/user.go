@@ -17,13 +17,22 @@ type User struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// Login is the controller for the login endpoint
+type LoginReq struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Signup is the controller for the signup endpoint
 func Signup(userService user.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request User
 		if err := c.ShouldBindJSON(&request); err != nil {
 			loggerUser.WithError(err).Error("Error binding the user")
-			c.JSON(http.StatusBadRequest, Error{Message: "Error binding the user", Error: err.Error()})
+			renderError(c, "Error binding the user", bindingError(err))
 			return
 		}
 
@@ -34,12 +43,74 @@ func Signup(userService user.Service) gin.HandlerFunc {
 			Password: request.Password,
 		}
 
-		if err := userService.Signup(user); err != nil {
+		if err := userService.Signup(c.Request.Context(), user); err != nil {
 			loggerUser.WithError(err).Error("Error signing up the user")
-			c.JSON(http.StatusInternalServerError, Error{Message: "Error signing up the user", Error: err.Error()})
+			renderError(c, "Error signing up the user", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Success{Message: "User created successfully"})
+	}
+}
+
+// Login is the controller for the login endpoint
+func Login(userService user.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request LoginReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerUser.WithError(err).Error("Error binding the credentials")
+			renderError(c, "Error binding the credentials", bindingError(err))
+			return
+		}
+
+		session, err := userService.Login(c.Request.Context(), request.Email, request.Password)
+		if err != nil {
+			loggerUser.WithError(err).Error("Error logging in the user")
+			renderError(c, "Error logging in the user", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: session})
+	}
+}
+
+// RefreshToken is the controller for the refresh token endpoint
+func RefreshToken(userService user.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request RefreshTokenReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerUser.WithError(err).Error("Error binding the refresh token")
+			renderError(c, "Error binding the refresh token", bindingError(err))
+			return
+		}
+
+		session, err := userService.Refresh(c.Request.Context(), request.RefreshToken)
+		if err != nil {
+			loggerUser.WithError(err).Error("Error refreshing the session")
+			renderError(c, "Error refreshing the session", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, Data{Data: session})
+	}
+}
+
+// Logout is the controller for the logout endpoint
+func Logout(userService user.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request RefreshTokenReq
+		if err := c.ShouldBindJSON(&request); err != nil {
+			loggerUser.WithError(err).Error("Error binding the refresh token")
+			renderError(c, "Error binding the refresh token", bindingError(err))
+			return
+		}
+
+		if err := userService.Logout(c.Request.Context(), request.RefreshToken); err != nil {
+			loggerUser.WithError(err).Error("Error logging out the user")
+			renderError(c, "Error logging out the user", err)
 			return
 		}
 
-		c.JSON(200, Success{Message: "User created successfully"})
+		c.JSON(http.StatusOK, Success{Message: "User logged out successfully"})
 	}
 }
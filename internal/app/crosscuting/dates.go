@@ -0,0 +1,20 @@
+package crosscuting
+
+import "time"
+
+// AddMonths adds the months to the date keeping its time, clamping the day to the last day of the resulting month.
+func AddMonths(date time.Time, months int) time.Time {
+	year, month, day := date.Date()
+	first := time.Date(year, month+time.Month(months), 1, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+
+	if lastDay := DaysIn(first); day > lastDay {
+		day = lastDay
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+// DaysIn returns the number of days of the month of the date.
+func DaysIn(date time.Time) int {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
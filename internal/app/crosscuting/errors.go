@@ -0,0 +1,99 @@
+package crosscuting
+
+import "errors"
+
+// Kinds of the error taxonomy, every error returned to the client is classified in one of them.
+var (
+	// ErrNotFound is the kind for resources that do not exist.
+	ErrNotFound = errors.New("not found error")
+	// ErrConflict is the kind for operations that clash with the current state, like duplicated keys.
+	ErrConflict = errors.New("conflict error")
+	// ErrValidation is the kind for invalid input.
+	ErrValidation = errors.New("validation error")
+	// ErrUnauthorized is the kind for missing or invalid credentials.
+	ErrUnauthorized = errors.New("unauthorized error")
+	// ErrForbidden is the kind for authenticated users without access to the resource.
+	ErrForbidden = errors.New("forbidden error")
+	// ErrTimeout is the kind for operations that did not finish before their deadline.
+	ErrTimeout = errors.New("timeout error")
+	// ErrCanceled is the kind for operations abandoned because the client canceled the request.
+	ErrCanceled = errors.New("canceled error")
+	// ErrInternal is the kind for unexpected errors, it is the default kind.
+	ErrInternal = errors.New("internal error")
+)
+
+// Default machine readable codes of each kind.
+const (
+	CodeNotFound     = "NOT_FOUND"
+	CodeConflict     = "CONFLICT"
+	CodeValidation   = "VALIDATION_ERROR"
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeForbidden    = "FORBIDDEN"
+	CodeTimeout      = "TIMEOUT"
+	CodeCanceled     = "CANCELED"
+	CodeInternal     = "INTERNAL_ERROR"
+)
+
+// kinds is ordered by precedence, used when an error wraps more than one kind.
+var kinds = []error{ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict, ErrValidation, ErrTimeout, ErrCanceled, ErrInternal}
+
+var defaultCodes = map[error]string{
+	ErrNotFound:     CodeNotFound,
+	ErrConflict:     CodeConflict,
+	ErrValidation:   CodeValidation,
+	ErrUnauthorized: CodeUnauthorized,
+	ErrForbidden:    CodeForbidden,
+	ErrTimeout:      CodeTimeout,
+	ErrCanceled:     CodeCanceled,
+	ErrInternal:     CodeInternal,
+}
+
+// TypedError is an error of the taxonomy with a stable machine readable code.
+// errors.Is matches both the typed error itself and its kind.
+type TypedError struct {
+	kind error
+	code string
+	msg  string
+}
+
+// NewTypedError creates a new typed error of the given kind and code.
+func NewTypedError(kind error, code, msg string) *TypedError {
+	return &TypedError{kind: kind, code: code, msg: msg}
+}
+
+// Error returns the message of the error.
+func (e *TypedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the kind of the error.
+func (e *TypedError) Unwrap() error {
+	return e.kind
+}
+
+// Code returns the machine readable code of the error.
+func (e *TypedError) Code() string {
+	return e.code
+}
+
+// KindOf returns the kind of the error, ErrInternal if it is not classified.
+func KindOf(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	return ErrInternal
+}
+
+// CodeOf returns the machine readable code of the error.
+// It is the code of the first typed error in the chain or the default code of its kind.
+func CodeOf(err error) string {
+	var typed *TypedError
+	if errors.As(err, &typed) {
+		return typed.Code()
+	}
+
+	return defaultCodes[KindOf(err)]
+}
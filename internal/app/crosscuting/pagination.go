@@ -0,0 +1,158 @@
+package crosscuting
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLimit is the page size used when the limit is not given.
+	DefaultLimit = 20
+	// MaxLimit is the biggest page size allowed.
+	MaxLimit = 100
+
+	// SortByID sorts the list by the id.
+	SortByID = "id"
+	// SortByCreatedAt sorts the list by the creation date and then by the id.
+	SortByCreatedAt = "created_at"
+)
+
+var errInvalidCursor = NewTypedError(ErrValidation, "INVALID_CURSOR", "invalid cursor error")
+
+type (
+	// Cursor is the position of the last element of a page, the next page starts after it.
+	// It keeps the sort and the hash of the filters of its list, since it is only a position in that list.
+	Cursor struct {
+		ID        uint      `json:"id"`
+		CreatedAt time.Time `json:"created_at"`
+		Sort      string    `json:"sort"`
+		Filter    string    `json:"filter"`
+	}
+
+	// Pagination is the keyset pagination of a list sorted by a field and then by the id.
+	// The filter is the hash of the filters of the list.
+	Pagination struct {
+		Limit  int
+		Cursor *Cursor
+		SortBy string
+		Desc   bool
+		Filter string
+	}
+)
+
+// NewPagination creates the pagination from the raw limit, cursor and sort of a request and the filters of the list.
+// The sort is the field name, prefixed with "-" for descending order. The cursor must come from a page
+// of the list with the same sort and filters.
+func NewPagination(limit int, cursor, sort string, filter interface{}) (Pagination, error) {
+	pagination := Pagination{Limit: limit, SortBy: SortByID, Filter: hashFilter(filter)}
+
+	if pagination.Limit <= 0 {
+		pagination.Limit = DefaultLimit
+	}
+
+	if pagination.Limit > MaxLimit {
+		pagination.Limit = MaxLimit
+	}
+
+	if sort != "" {
+		pagination.Desc = strings.HasPrefix(sort, "-")
+		pagination.SortBy = strings.TrimPrefix(sort, "-")
+	}
+
+	if cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return Pagination{}, err
+		}
+
+		if decoded.Sort != pagination.sort() || decoded.Filter != pagination.Filter {
+			return Pagination{}, fmt.Errorf(WrapLabelWithoutError, "The cursor belongs to a list with another sort or filters", errInvalidCursor)
+		}
+
+		pagination.Cursor = &decoded
+	}
+
+	return pagination, nil
+}
+
+// Order returns the ORDER BY clause of the pagination.
+func (p Pagination) Order() string {
+	direction := "ASC"
+	if p.Desc {
+		direction = "DESC"
+	}
+
+	if p.SortBy == SortByCreatedAt {
+		return fmt.Sprintf("created_at %s, id %s", direction, direction)
+	}
+
+	return "id " + direction
+}
+
+// KeysetCondition returns the condition and the args to get the elements after the cursor.
+// It returns an empty condition when there is no cursor.
+func (p Pagination) KeysetCondition() (string, []interface{}) {
+	if p.Cursor == nil {
+		return "", nil
+	}
+
+	operator := ">"
+	if p.Desc {
+		operator = "<"
+	}
+
+	if p.SortBy == SortByCreatedAt {
+		return fmt.Sprintf("(created_at, id) %s (?, ?)", operator), []interface{}{p.Cursor.CreatedAt, p.Cursor.ID}
+	}
+
+	return fmt.Sprintf("id %s ?", operator), []interface{}{p.Cursor.ID}
+}
+
+// NextCursor returns the cursor of the page that starts after the element with the id and the creation date.
+func (p Pagination) NextCursor(id uint, createdAt time.Time) string {
+	return EncodeCursor(Cursor{ID: id, CreatedAt: createdAt, Sort: p.sort(), Filter: p.Filter})
+}
+
+// sort returns the raw sort of the pagination, the field name prefixed with "-" for descending order.
+func (p Pagination) sort() string {
+	if p.Desc {
+		return "-" + p.SortBy
+	}
+
+	return p.SortBy
+}
+
+// EncodeCursor returns the opaque representation of the cursor sent to the clients.
+func EncodeCursor(cursor Cursor) string {
+	bytes, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// DecodeCursor parses the opaque representation of a cursor.
+func DecodeCursor(cursor string) (Cursor, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return Cursor{}, fmt.Errorf(WrapLabel, "Error decoding the cursor", errInvalidCursor, err.Error())
+	}
+
+	var decoded Cursor
+	if err := json.Unmarshal(bytes, &decoded); err != nil {
+		return Cursor{}, fmt.Errorf(WrapLabel, "Error parsing the cursor", errInvalidCursor, err.Error())
+	}
+
+	return decoded, nil
+}
+
+// hashFilter returns the hash of the JSON representation of the filters.
+func hashFilter(filter interface{}) string {
+	bytes, _ := json.Marshal(filter)
+	sum := sha256.Sum256(bytes)
+
+	return hex.EncodeToString(sum[:8])
+}
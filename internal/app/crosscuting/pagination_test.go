@@ -0,0 +1,93 @@
+package crosscuting
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type listFilter struct {
+	Type string
+	Name string
+}
+
+func TestNewPaginationCursor(t *testing.T) {
+	first, err := NewPagination(10, "", "-created_at", listFilter{Type: "stock"})
+	if err != nil {
+		t.Fatalf("NewPagination() error = %v", err)
+	}
+
+	createdAt := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+	cursor := first.NextCursor(7, createdAt)
+
+	tests := []struct {
+		name    string
+		cursor  string
+		sort    string
+		filter  interface{}
+		wantErr bool
+	}{
+		{name: "same sort and filters", cursor: cursor, sort: "-created_at", filter: listFilter{Type: "stock"}},
+		{name: "another direction", cursor: cursor, sort: "created_at", filter: listFilter{Type: "stock"}, wantErr: true},
+		{name: "another field", cursor: cursor, sort: "-id", filter: listFilter{Type: "stock"}, wantErr: true},
+		{name: "another filter", cursor: cursor, sort: "-created_at", filter: listFilter{Type: "stock", Name: "a"}, wantErr: true},
+		{name: "not base64", cursor: "not a cursor!", sort: "-created_at", filter: listFilter{Type: "stock"}, wantErr: true},
+		{name: "not json", cursor: "bm90IGpzb24", sort: "-created_at", filter: listFilter{Type: "stock"}, wantErr: true},
+		{
+			name:    "cursor without sort and filters",
+			cursor:  EncodeCursor(Cursor{ID: 7, CreatedAt: createdAt}),
+			sort:    "-created_at",
+			filter:  listFilter{Type: "stock"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagination, err := NewPagination(10, tt.cursor, tt.sort, tt.filter)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Fatalf("NewPagination() error = %v, want %v", err, ErrValidation)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewPagination() unexpected error = %v", err)
+			}
+
+			if pagination.Cursor == nil || pagination.Cursor.ID != 7 || !pagination.Cursor.CreatedAt.Equal(createdAt) {
+				t.Errorf("NewPagination() cursor = %+v, want the id 7 created at %v", pagination.Cursor, createdAt)
+			}
+		})
+	}
+}
+
+func TestNewPaginationDefaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		sort      string
+		wantLimit int
+		wantOrder string
+	}{
+		{name: "defaults", wantLimit: DefaultLimit, wantOrder: "id ASC"},
+		{name: "limit over the maximum", limit: MaxLimit + 1, wantLimit: MaxLimit, wantOrder: "id ASC"},
+		{name: "descending creation date", limit: 5, sort: "-created_at", wantLimit: 5, wantOrder: "created_at DESC, id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagination, err := NewPagination(tt.limit, "", tt.sort, nil)
+			if err != nil {
+				t.Fatalf("NewPagination() unexpected error = %v", err)
+			}
+
+			if pagination.Limit != tt.wantLimit || pagination.Order() != tt.wantOrder {
+				t.Errorf("NewPagination() = limit %d and order %q, want %d and %q", pagination.Limit, pagination.Order(), tt.wantLimit, tt.wantOrder)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package account
+
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
+
+const (
+	// Checking is the type for the checking accounts.
+	Checking AccountType = "checking"
+	// Savings is the type for the savings accounts.
+	Savings AccountType = "savings"
+	// Cash is the type for the cash in hand.
+	Cash AccountType = "cash"
+	// CreditCard is the type for the credit cards.
+	CreditCard AccountType = "credit_card"
+	// Brokerage is the type for the brokerage accounts.
+	Brokerage AccountType = "brokerage"
+	// Loan is the type for the loans.
+	Loan AccountType = "loan"
+	// Income is the type for the nominal accounts that record the sources of income.
+	Income AccountType = "income"
+	// Expense is the type for the nominal accounts that record the expenses.
+	Expense AccountType = "expense"
+	// Equity is the type for the nominal accounts that record the opening balances and adjustments.
+	Equity AccountType = "equity"
+
+	// Open is the status of the accounts in use.
+	Open AccountStatus = "open"
+	// Closed is the status of the accounts no longer in use.
+	Closed AccountStatus = "closed"
+
+	// FIFO sells the oldest lots first.
+	FIFO CostBasisMethod = "fifo"
+	// LIFO sells the newest lots first.
+	LIFO CostBasisMethod = "lifo"
+	// HighestCost sells the lots with the highest unit cost first.
+	HighestCost CostBasisMethod = "highest_cost"
+	// AverageCost sells from all the lots in proportion, at the average unit cost.
+	AverageCost CostBasisMethod = "average_cost"
+)
+
+type (
+	// AccountType can be checking, savings, cash, credit_card, brokerage, loan, income, expense or equity.
+	AccountType string
+
+	// AccountStatus can be open or closed.
+	AccountStatus string
+
+	// CostBasisMethod is how the sales of an account are matched with its tax lots.
+	// It can be fifo, lifo, highest_cost or average_cost.
+	CostBasisMethod string
+
+	// Account is the struct for an account of a user, denominated in a financial asset.
+	Account struct {
+		gorm.Model
+		UserID          uint            `json:"user_id" gorm:"not null"`
+		AssetID         uint            `json:"asset_id" gorm:"not null"`
+		Name            string          `json:"name" gorm:"not null"`
+		Type            AccountType     `json:"type" gorm:"not null"`
+		Institution     string          `json:"institution" gorm:"not null"`
+		OpeningBalance  money.Money     `json:"opening_balance" gorm:"embedded;embeddedPrefix:opening_balance_"`
+		OpeningDate     time.Time       `json:"opening_date" gorm:"not null"`
+		Status          AccountStatus   `json:"status" gorm:"not null"`
+		ClosedAt        *time.Time      `json:"closed_at"`
+		CostBasisMethod CostBasisMethod `json:"cost_basis_method" gorm:"not null"`
+	}
+
+	// AccountFilter is the struct for the filters of the accounts of a user, empty fields do not filter.
+	// DeletedAfter includes the soft deleted accounts whose deletion is after the time, nil excludes all of them.
+	AccountFilter struct {
+		Type         AccountType
+		Status       AccountStatus
+		DeletedAfter *time.Time
+	}
+
+	// AccountPatch is the struct for the partial update of an account, nil fields are not updated.
+	AccountPatch struct {
+		Name            *string
+		Type            *AccountType
+		Institution     *string
+		Status          *AccountStatus
+		CostBasisMethod *CostBasisMethod
+	}
+)
+
+// IsValid reports if the account type is supported.
+func (t AccountType) IsValid() bool {
+	switch t {
+	case Checking, Savings, Cash, CreditCard, Brokerage, Loan, Income, Expense, Equity:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsLiability reports if the balance of the accounts of the type is owed by the user.
+func (t AccountType) IsLiability() bool {
+	return t == CreditCard || t == Loan
+}
+
+// IsNominal reports if the accounts of the type only balance the ledger and do not hold money of the user.
+func (t AccountType) IsNominal() bool {
+	return t == Income || t == Expense || t == Equity
+}
+
+// IsValid reports if the account status is supported.
+func (s AccountStatus) IsValid() bool {
+	return s == Open || s == Closed
+}
+
+// IsValid reports if the cost basis method is supported.
+func (m CostBasisMethod) IsValid() bool {
+	switch m {
+	case FIFO, LIFO, HighestCost, AverageCost:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsEmpty reports if the patch does not update any field.
+func (p AccountPatch) IsEmpty() bool {
+	return p.Name == nil && p.Type == nil && p.Institution == nil && p.Status == nil && p.CostBasisMethod == nil
+}
@@ -0,0 +1,161 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.account.repository")
+
+// Repository is the interface for the account repository.
+// All the operations are scoped to the accounts of the given user.
+type Repository interface {
+	Create(ctx context.Context, account Account) (Account, error)
+	Get(ctx context.Context, userID uint, filter AccountFilter) ([]Account, error)
+	GetByID(ctx context.Context, userID, id uint) (Account, error)
+	Update(ctx context.Context, userID, id uint, patch AccountPatch) error
+	Delete(ctx context.Context, userID, id uint) error
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the account repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewAccountRepository creates a new account repository.
+func NewAccountRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// Create creates a new account and returns it with its id.
+func (r *RepositoryImpl) Create(ctx context.Context, account Account) (Account, error) {
+	if err := r.db.Create(ctx, &account); err != nil {
+		loggerRepo.WithError(err).Error("Error creating record in the database")
+
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+// Get returns the accounts of the user given filters, sorted by id.
+func (r *RepositoryImpl) Get(ctx context.Context, userID uint, filter AccountFilter) ([]Account, error) {
+	var accounts []Account
+
+	queryConditions := []string{"user_id = ?"}
+	args := []interface{}{userID}
+
+	if filter.Type != "" {
+		queryConditions = append(queryConditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+
+	if filter.Status != "" {
+		queryConditions = append(queryConditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+
+	db := r.db
+	if filter.DeletedAfter != nil {
+		db = db.Unscoped()
+		queryConditions = append(queryConditions, "(deleted_at IS NULL OR deleted_at > ?)")
+		args = append(args, *filter.DeletedAfter)
+	}
+
+	if err := db.Order("id ASC").WhereFind(ctx, &accounts, strings.Join(queryConditions, " AND "), args...); err != nil {
+		loggerRepo.WithError(err).Error("Error getting records from the database")
+
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// GetByID returns the account of the user with the given id.
+func (r *RepositoryImpl) GetByID(ctx context.Context, userID, id uint) (Account, error) {
+	var account Account
+	if err := r.db.WhereFirst(ctx, &account, "user_id = ? AND id = ?", userID, id); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the account by id")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return Account{}, fmt.Errorf(crosscuting.WrapLabel, "Account not found", ErrAccountNotFound, err.Error())
+		}
+
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+// Update updates the non nil fields of the patch in the account of the user.
+// Closing the account sets its closed date and reopening it clears it.
+func (r *RepositoryImpl) Update(ctx context.Context, userID, id uint, patch AccountPatch) error {
+	values := map[string]interface{}{}
+
+	if patch.Name != nil {
+		values["name"] = *patch.Name
+	}
+
+	if patch.Type != nil {
+		values["type"] = *patch.Type
+	}
+
+	if patch.Institution != nil {
+		values["institution"] = *patch.Institution
+	}
+
+	if patch.CostBasisMethod != nil {
+		values["cost_basis_method"] = *patch.CostBasisMethod
+	}
+
+	if patch.Status != nil {
+		values["status"] = *patch.Status
+		values["closed_at"] = nil
+
+		if *patch.Status == Closed {
+			values["closed_at"] = time.Now()
+		}
+	}
+
+	rows, err := r.db.WhereUpdates(ctx, &Account{}, values, "user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error updating record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Account not found", ErrAccountNotFound)
+	}
+
+	return nil
+}
+
+// Delete soft deletes the account of the user.
+func (r *RepositoryImpl) Delete(ctx context.Context, userID, id uint) error {
+	rows, err := r.db.WhereDelete(ctx, &Account{}, "user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error deleting record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Account not found", ErrAccountNotFound)
+	}
+
+	return nil
+}
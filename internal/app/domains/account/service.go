@@ -0,0 +1,148 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerService = logger.Setup("domain.account.service")
+	// ErrAccountNotFound is returned when the account does not exist or belongs to another user.
+	ErrAccountNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "ACCOUNT_NOT_FOUND", "account not found error")
+	// ErrInvalidAccount is returned when the fields of the account are not valid.
+	ErrInvalidAccount = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_ACCOUNT", "invalid account error")
+)
+
+// Service is the interface for the account service.
+type Service interface {
+	Create(ctx context.Context, account Account) (Account, error)
+	Get(ctx context.Context, userID uint, filter AccountFilter) ([]Account, error)
+	GetByID(ctx context.Context, userID, id uint) (Account, error)
+	Update(ctx context.Context, userID, id uint, patch AccountPatch) (Account, error)
+	Delete(ctx context.Context, userID, id uint) error
+}
+
+// ServiceImpl is the struct that contains the account service.
+type ServiceImpl struct {
+	repo            Repository
+	finAssetService finasset.Service
+}
+
+// NewAccountService creates a new account service.
+func NewAccountService(repo Repository, finAssetService finasset.Service) Service {
+	return &ServiceImpl{repo: repo, finAssetService: finAssetService}
+}
+
+// Create creates a new open account denominated in an existing financial asset.
+// The opening balance is rounded to the minor units of the asset and the cost basis method is fifo by default.
+func (s *ServiceImpl) Create(ctx context.Context, account Account) (Account, error) {
+	if !account.Type.IsValid() {
+		return Account{}, invalidAccount(fmt.Sprintf("Unknown account type %s", account.Type))
+	}
+
+	if account.CostBasisMethod == "" {
+		account.CostBasisMethod = FIFO
+	}
+
+	if !account.CostBasisMethod.IsValid() {
+		return Account{}, invalidAccount(fmt.Sprintf("Unknown cost basis method %s", account.CostBasisMethod))
+	}
+
+	asset, err := s.finAssetService.GetByID(ctx, account.AssetID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the asset of the account")
+		return Account{}, err
+	}
+
+	account.OpeningBalance = money.New(account.OpeningBalance.Amount, asset.Symbol).Round(asset.MinorUnits())
+
+	// The opening balance counts from the opening date, which defaults to the creation of the account.
+	if account.OpeningDate.IsZero() {
+		account.OpeningDate = time.Now()
+	}
+
+	account.OpeningDate = account.OpeningDate.UTC()
+	account.Status = Open
+	account.ClosedAt = nil
+
+	created, err := s.repo.Create(ctx, account)
+	if err != nil {
+		loggerService.WithError(err).Error("Error creating the account")
+		return Account{}, err
+	}
+
+	return created, nil
+}
+
+// Get returns the accounts of the user given filters.
+func (s *ServiceImpl) Get(ctx context.Context, userID uint, filter AccountFilter) ([]Account, error) {
+	if filter.Type != "" && !filter.Type.IsValid() {
+		return nil, invalidAccount(fmt.Sprintf("Unknown account type %s", filter.Type))
+	}
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, invalidAccount(fmt.Sprintf("Unknown account status %s", filter.Status))
+	}
+
+	accounts, err := s.repo.Get(ctx, userID, filter)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the accounts from the repo")
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// GetByID returns the account of the user with the given id.
+func (s *ServiceImpl) GetByID(ctx context.Context, userID, id uint) (Account, error) {
+	account, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the account from the repo")
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+// Update updates the account of the user with the given fields and returns it updated.
+func (s *ServiceImpl) Update(ctx context.Context, userID, id uint, patch AccountPatch) (Account, error) {
+	switch {
+	case patch.IsEmpty():
+		return Account{}, invalidAccount("No fields to update")
+	case patch.Type != nil && !patch.Type.IsValid():
+		return Account{}, invalidAccount(fmt.Sprintf("Unknown account type %s", *patch.Type))
+	case patch.Status != nil && !patch.Status.IsValid():
+		return Account{}, invalidAccount(fmt.Sprintf("Unknown account status %s", *patch.Status))
+	case patch.CostBasisMethod != nil && !patch.CostBasisMethod.IsValid():
+		return Account{}, invalidAccount(fmt.Sprintf("Unknown cost basis method %s", *patch.CostBasisMethod))
+	}
+
+	if err := s.repo.Update(ctx, userID, id, patch); err != nil {
+		loggerService.WithError(err).Error("Error updating the account")
+		return Account{}, err
+	}
+
+	return s.GetByID(ctx, userID, id)
+}
+
+// Delete soft deletes the account of the user.
+func (s *ServiceImpl) Delete(ctx context.Context, userID, id uint) error {
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		loggerService.WithError(err).Error("Error deleting the account")
+		return err
+	}
+
+	return nil
+}
+
+func invalidAccount(desc string) error {
+	loggerService.WithError(ErrInvalidAccount).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidAccount)
+}
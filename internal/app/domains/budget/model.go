@@ -0,0 +1,117 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
+
+const (
+	// Weekly is the period of the budgets renewed every seven days.
+	Weekly Period = "weekly"
+	// Monthly is the period of the budgets renewed every month.
+	Monthly Period = "monthly"
+	// Yearly is the period of the budgets renewed every year.
+	Yearly Period = "yearly"
+	// Custom is the period of the budgets with a single period between the start and the end dates.
+	Custom Period = "custom"
+)
+
+type (
+	// Period can be weekly, monthly, yearly or custom.
+	Period string
+
+	// Budget is the struct for the spending limit of a user in an expense category and its subcategories.
+	// The periods start at the start date and repeat until the end date, if any.
+	// With rollover, the unspent or overspent amount of a period is added to the limit of the next one.
+	Budget struct {
+		gorm.Model
+		UserID     uint        `json:"user_id" gorm:"not null"`
+		CategoryID uint        `json:"category_id" gorm:"not null"`
+		Name       string      `json:"name" gorm:"not null"`
+		Period     Period      `json:"period" gorm:"not null"`
+		Amount     money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+		StartDate  time.Time   `json:"start_date" gorm:"not null"`
+		EndDate    *time.Time  `json:"end_date"`
+		Rollover   bool        `json:"rollover" gorm:"not null"`
+	}
+
+	// BudgetPatch is the struct for the partial update of a budget, nil fields are not updated.
+	BudgetPatch struct {
+		Name     *string
+		Amount   *money.Money
+		EndDate  *time.Time
+		Rollover *bool
+	}
+
+	// Progress is the struct for the spending of a budget in one of its periods, in the currency of the user.
+	Progress struct {
+		BudgetID    uint        `json:"budget_id"`
+		PeriodStart time.Time   `json:"period_start"`
+		PeriodEnd   time.Time   `json:"period_end"`
+		Limit       money.Money `json:"limit"`
+		Rollover    money.Money `json:"rollover"`
+		Available   money.Money `json:"available"`
+		Spent       money.Money `json:"spent"`
+		Remaining   money.Money `json:"remaining"`
+		Projected   money.Money `json:"projected"`
+	}
+)
+
+// IsValid reports if the period is supported.
+func (p Period) IsValid() bool {
+	switch p {
+	case Weekly, Monthly, Yearly, Custom:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsEmpty reports if the patch does not update any field.
+func (p BudgetPatch) IsEmpty() bool {
+	return p.Name == nil && p.Amount == nil && p.EndDate == nil && p.Rollover == nil
+}
+
+// periodStart returns the start of the n-th period of the budget, counting from zero.
+// Monthly and yearly periods keep the day of the start date, clamped to the last day of shorter months.
+func (b Budget) periodStart(n int) time.Time {
+	start := b.StartDate
+
+	switch b.Period {
+	case Weekly:
+		return start.AddDate(0, 0, 7*n)
+	case Monthly:
+		return crosscuting.AddMonths(start, n)
+	case Yearly:
+		return crosscuting.AddMonths(start, 12*n)
+	default:
+		return start
+	}
+}
+
+// periodAt returns the index, the start and the end (inclusive) of the period of the budget that contains the date.
+// Dates before the start date belong to the first period and dates after the end date to the last one.
+func (b Budget) periodAt(date time.Time) (int, time.Time, time.Time) {
+	if b.Period == Custom {
+		return 0, b.StartDate, *b.EndDate
+	}
+
+	if b.EndDate != nil && date.After(*b.EndDate) {
+		date = *b.EndDate
+	}
+
+	n := 0
+	for !b.periodStart(n + 1).After(date) {
+		n++
+	}
+
+	end := b.periodStart(n + 1).Add(-time.Nanosecond)
+	if b.EndDate != nil && end.After(*b.EndDate) {
+		end = *b.EndDate
+	}
+
+	return n, b.periodStart(n), end
+}
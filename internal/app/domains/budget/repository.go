@@ -0,0 +1,129 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.budget.repository")
+
+// Repository is the interface for the budget repository.
+// All the operations are scoped to the budgets of the given user.
+type Repository interface {
+	Create(ctx context.Context, budget Budget) (Budget, error)
+	Get(ctx context.Context, userID uint) ([]Budget, error)
+	GetByID(ctx context.Context, userID, id uint) (Budget, error)
+	Update(ctx context.Context, userID, id uint, patch BudgetPatch) error
+	Delete(ctx context.Context, userID, id uint) error
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the budget repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewBudgetRepository creates a new budget repository.
+func NewBudgetRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// Create creates a new budget and returns it with its id.
+func (r *RepositoryImpl) Create(ctx context.Context, budget Budget) (Budget, error) {
+	if err := r.db.Create(ctx, &budget); err != nil {
+		loggerRepo.WithError(err).Error("Error creating record in the database")
+
+		return Budget{}, err
+	}
+
+	return budget, nil
+}
+
+// Get returns the budgets of the user, sorted by id.
+func (r *RepositoryImpl) Get(ctx context.Context, userID uint) ([]Budget, error) {
+	var budgets []Budget
+	if err := r.db.Order("id ASC").WhereFind(ctx, &budgets, "user_id = ?", userID); err != nil {
+		loggerRepo.WithError(err).Error("Error getting records from the database")
+
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// GetByID returns the budget of the user with the given id.
+func (r *RepositoryImpl) GetByID(ctx context.Context, userID, id uint) (Budget, error) {
+	var budget Budget
+	if err := r.db.WhereFirst(ctx, &budget, "user_id = ? AND id = ?", userID, id); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the budget by id")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return Budget{}, fmt.Errorf(crosscuting.WrapLabel, "Budget not found", ErrBudgetNotFound, err.Error())
+		}
+
+		return Budget{}, err
+	}
+
+	return budget, nil
+}
+
+// Update updates the non nil fields of the patch in the budget of the user.
+func (r *RepositoryImpl) Update(ctx context.Context, userID, id uint, patch BudgetPatch) error {
+	values := map[string]interface{}{}
+
+	if patch.Name != nil {
+		values["name"] = *patch.Name
+	}
+
+	if patch.Amount != nil {
+		values["amount_amount"] = patch.Amount.Amount
+		values["amount_currency"] = patch.Amount.Currency
+	}
+
+	if patch.EndDate != nil {
+		values["end_date"] = *patch.EndDate
+	}
+
+	if patch.Rollover != nil {
+		values["rollover"] = *patch.Rollover
+	}
+
+	rows, err := r.db.WhereUpdates(ctx, &Budget{}, values, "user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error updating record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Budget not found", ErrBudgetNotFound)
+	}
+
+	return nil
+}
+
+// Delete soft deletes the budget of the user.
+func (r *RepositoryImpl) Delete(ctx context.Context, userID, id uint) error {
+	rows, err := r.db.WhereDelete(ctx, &Budget{}, "user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error deleting record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Budget not found", ErrBudgetNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,371 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/category"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/domains/fx"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/app/domains/user"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	loggerService = logger.Setup("domain.budget.service")
+	// ErrBudgetNotFound is returned when the budget does not exist or belongs to another user.
+	ErrBudgetNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "BUDGET_NOT_FOUND", "budget not found error")
+	// ErrInvalidBudget is returned when the fields of the budget are not valid.
+	ErrInvalidBudget = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_BUDGET", "invalid budget error")
+)
+
+// Service is the interface for the budget service.
+type Service interface {
+	Create(ctx context.Context, budget Budget) (Budget, error)
+	Get(ctx context.Context, userID uint) ([]Budget, error)
+	GetByID(ctx context.Context, userID, id uint) (Budget, error)
+	Update(ctx context.Context, userID, id uint, patch BudgetPatch) (Budget, error)
+	Delete(ctx context.Context, userID, id uint) error
+	GetProgress(ctx context.Context, userID, id uint, date time.Time) (Progress, error)
+}
+
+// ServiceImpl is the struct that contains the budget service.
+type ServiceImpl struct {
+	repo            Repository
+	userService     user.Service
+	categoryService category.Service
+	accountService  account.Service
+	ledgerService   ledger.Service
+	finAssetService finasset.Service
+	fxService       fx.Service
+}
+
+// NewBudgetService creates a new budget service.
+func NewBudgetService(
+	repo Repository,
+	userService user.Service,
+	categoryService category.Service,
+	accountService account.Service,
+	ledgerService ledger.Service,
+	finAssetService finasset.Service,
+	fxService fx.Service,
+) Service {
+	return &ServiceImpl{
+		repo:            repo,
+		userService:     userService,
+		categoryService: categoryService,
+		accountService:  accountService,
+		ledgerService:   ledgerService,
+		finAssetService: finAssetService,
+		fxService:       fxService,
+	}
+}
+
+// Create creates a new budget on an expense category of the user.
+// The amount is denominated in the currency of the user and rounded to its minor units.
+func (s *ServiceImpl) Create(ctx context.Context, budget Budget) (Budget, error) {
+	if strings.TrimSpace(budget.Name) == "" {
+		return Budget{}, invalidBudget("The name is required")
+	}
+
+	if !budget.Period.IsValid() {
+		return Budget{}, invalidBudget(fmt.Sprintf("Unknown budget period %s", budget.Period))
+	}
+
+	if budget.StartDate.IsZero() {
+		return Budget{}, invalidBudget("The start date is required")
+	}
+
+	if budget.Period == Custom && budget.EndDate == nil {
+		return Budget{}, invalidBudget("The end date is required for custom periods")
+	}
+
+	if budget.EndDate != nil && !budget.EndDate.After(budget.StartDate) {
+		return Budget{}, invalidBudget("The end date must be after the start date")
+	}
+
+	if !budget.Amount.Amount.IsPositive() {
+		return Budget{}, invalidBudget("The amount must be positive")
+	}
+
+	cat, err := s.categoryService.GetByID(ctx, budget.UserID, budget.CategoryID)
+	if err != nil {
+		return Budget{}, err
+	}
+
+	if cat.Kind != category.Expense {
+		return Budget{}, invalidBudget("The category of a budget must be an expense category")
+	}
+
+	owner, err := s.userService.GetByID(ctx, budget.UserID)
+	if err != nil {
+		return Budget{}, err
+	}
+
+	asset, err := s.finAssetService.GetBySymbol(ctx, owner.Currency)
+	if err != nil {
+		return Budget{}, err
+	}
+
+	budget.Amount = money.New(budget.Amount.Amount, asset.Symbol).Round(asset.MinorUnits())
+
+	created, err := s.repo.Create(ctx, budget)
+	if err != nil {
+		loggerService.WithError(err).Error("Error creating the budget")
+		return Budget{}, err
+	}
+
+	return created, nil
+}
+
+// Get returns the budgets of the user.
+func (s *ServiceImpl) Get(ctx context.Context, userID uint) ([]Budget, error) {
+	budgets, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the budgets from the repo")
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// GetByID returns the budget of the user with the given id.
+func (s *ServiceImpl) GetByID(ctx context.Context, userID, id uint) (Budget, error) {
+	budget, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the budget from the repo")
+		return Budget{}, err
+	}
+
+	return budget, nil
+}
+
+// Update updates the budget of the user with the given fields and returns it updated.
+// The amount keeps the currency of the budget.
+func (s *ServiceImpl) Update(ctx context.Context, userID, id uint, patch BudgetPatch) (Budget, error) {
+	if patch.IsEmpty() {
+		return Budget{}, invalidBudget("No fields to update")
+	}
+
+	if patch.Name != nil && strings.TrimSpace(*patch.Name) == "" {
+		return Budget{}, invalidBudget("The name can not be empty")
+	}
+
+	budget, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return Budget{}, err
+	}
+
+	if patch.EndDate != nil && !patch.EndDate.After(budget.StartDate) {
+		return Budget{}, invalidBudget("The end date must be after the start date")
+	}
+
+	if patch.Amount != nil {
+		if !patch.Amount.Amount.IsPositive() {
+			return Budget{}, invalidBudget("The amount must be positive")
+		}
+
+		asset, err := s.finAssetService.GetBySymbol(ctx, budget.Amount.Currency)
+		if err != nil {
+			return Budget{}, err
+		}
+
+		amount := money.New(patch.Amount.Amount, asset.Symbol).Round(asset.MinorUnits())
+		patch.Amount = &amount
+	}
+
+	if err := s.repo.Update(ctx, userID, id, patch); err != nil {
+		loggerService.WithError(err).Error("Error updating the budget")
+		return Budget{}, err
+	}
+
+	return s.repo.GetByID(ctx, userID, id)
+}
+
+// Delete soft deletes the budget of the user.
+func (s *ServiceImpl) Delete(ctx context.Context, userID, id uint) error {
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		loggerService.WithError(err).Error("Error deleting the budget")
+		return err
+	}
+
+	return nil
+}
+
+// GetProgress returns the spending of the budget in the period that contains the date, in the currency of the user.
+// The spending is the sum of the postings to expense accounts of the transactions in the category of the budget
+// or its subcategories, converted at the date of each transaction. The projection extrapolates the spending
+// linearly to the end of the period.
+func (s *ServiceImpl) GetProgress(ctx context.Context, userID, id uint, date time.Time) (Progress, error) {
+	budget, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	owner, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	asset, err := s.finAssetService.GetBySymbol(ctx, owner.Currency)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	index, start, end := budget.periodAt(date)
+
+	// With rollover, the spending of all the previous periods is needed to carry the differences.
+	from := start
+	if budget.Rollover {
+		from = budget.StartDate
+	}
+
+	spentByPeriod, err := s.spending(ctx, budget, asset.Symbol, from, end)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	limit, err := s.convert(ctx, budget.Amount, asset.Symbol, date)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	carry := money.Zero(asset.Symbol)
+
+	if budget.Rollover {
+		for i := 0; i < index; i++ {
+			spent := spentOrZero(spentByPeriod, i, asset.Symbol)
+
+			if carry, err = money.Sum(asset.Symbol, carry, limit, spent.Neg()); err != nil {
+				return Progress{}, err
+			}
+		}
+	}
+
+	spent := spentOrZero(spentByPeriod, index, asset.Symbol)
+
+	available, err := limit.Add(carry)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	remaining, err := available.Sub(spent)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	minorUnits := asset.MinorUnits()
+
+	return Progress{
+		BudgetID:    budget.ID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Limit:       limit.Round(minorUnits),
+		Rollover:    carry.Round(minorUnits),
+		Available:   available.Round(minorUnits),
+		Spent:       spent.Round(minorUnits),
+		Remaining:   remaining.Round(minorUnits),
+		Projected:   project(spent, start, end, date).Round(minorUnits),
+	}, nil
+}
+
+// spending returns the spending of the budget between the dates grouped by the index of the period.
+func (s *ServiceImpl) spending(ctx context.Context, budget Budget, currency string, from, to time.Time) (map[int]money.Money, error) {
+	categoryIDs, err := s.categoryService.GetDescendantIDs(ctx, budget.UserID, budget.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	expenseAccounts, err := s.accountService.Get(ctx, budget.UserID, account.AccountFilter{Type: account.Expense})
+	if err != nil {
+		return nil, err
+	}
+
+	isExpense := make(map[uint]bool, len(expenseAccounts))
+	for _, acc := range expenseAccounts {
+		isExpense[acc.ID] = true
+	}
+
+	txns, err := s.ledgerService.GetTransactions(ctx, budget.UserID, ledger.TransactionFilter{
+		CategoryIDs: categoryIDs,
+		From:        from,
+		To:          to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spentByPeriod := map[int]money.Money{}
+
+	for _, txn := range txns {
+		index, _, _ := budget.periodAt(txn.Date)
+		spent := spentOrZero(spentByPeriod, index, currency)
+
+		for _, posting := range txn.Postings {
+			if !isExpense[posting.AccountID] {
+				continue
+			}
+
+			converted, err := s.convert(ctx, posting.Amount, currency, txn.Date)
+			if err != nil {
+				return nil, err
+			}
+
+			if spent, err = spent.Add(converted); err != nil {
+				return nil, err
+			}
+		}
+
+		spentByPeriod[index] = spent
+	}
+
+	return spentByPeriod, nil
+}
+
+// convert converts the amount to the currency on the date, without rounding.
+func (s *ServiceImpl) convert(ctx context.Context, amount money.Money, currency string, date time.Time) (money.Money, error) {
+	if amount.Currency == currency {
+		return amount, nil
+	}
+
+	conversion, err := s.fxService.Convert(ctx, amount, currency, date)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return conversion.Converted, nil
+}
+
+// project extrapolates the spending up to the date to the whole period.
+// Once the period is over, or before it starts, the projection is the spending itself.
+func project(spent money.Money, start, end, date time.Time) money.Money {
+	elapsed := date.Sub(start)
+	total := end.Sub(start)
+
+	if elapsed <= 0 || elapsed >= total {
+		return spent
+	}
+
+	return spent.Mul(decimal.NewFromInt(int64(total)).Div(decimal.NewFromInt(int64(elapsed))))
+}
+
+func spentOrZero(spentByPeriod map[int]money.Money, index int, currency string) money.Money {
+	if spent, ok := spentByPeriod[index]; ok {
+		return spent
+	}
+
+	return money.Zero(currency)
+}
+
+func invalidBudget(desc string) error {
+	loggerService.WithError(ErrInvalidBudget).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidBudget)
+}
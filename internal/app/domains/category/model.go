@@ -0,0 +1,82 @@
+package category
+
+import "github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+
+const (
+	// Income is the kind for the categories of the money received.
+	Income CategoryKind = "income"
+	// Expense is the kind for the categories of the money spent.
+	Expense CategoryKind = "expense"
+)
+
+type (
+	// CategoryKind can be income or expense.
+	CategoryKind string
+
+	// Category is the struct for a category of the transactions of a user.
+	// Categories form a tree, the children have the kind of their parent.
+	Category struct {
+		gorm.Model
+		UserID   uint         `json:"user_id" gorm:"not null"`
+		ParentID *uint        `json:"parent_id"`
+		Name     string       `json:"name" gorm:"not null"`
+		Kind     CategoryKind `json:"kind" gorm:"not null"`
+		Archived bool         `json:"archived" gorm:"not null"`
+		Icon     string       `json:"icon" gorm:"not null"`
+		Color    string       `json:"color" gorm:"not null"`
+		Children []Category   `json:"children,omitempty" gorm:"-"`
+	}
+
+	// CategoryPatch is the struct for the partial update of a category, nil fields are not updated.
+	CategoryPatch struct {
+		Name     *string
+		Archived *bool
+		Icon     *string
+		Color    *string
+	}
+
+	// Tag is the struct for a free-form label of the transactions of a user.
+	Tag struct {
+		gorm.Model
+		UserID uint   `json:"user_id" gorm:"not null"`
+		Name   string `json:"name" gorm:"not null"`
+	}
+
+	// TransactionTag is the struct for the tags attached to a transaction.
+	TransactionTag struct {
+		TransactionID uint `json:"transaction_id" gorm:"primaryKey"`
+		TagID         uint `json:"tag_id" gorm:"primaryKey"`
+	}
+)
+
+// IsValid reports if the category kind is supported.
+func (k CategoryKind) IsValid() bool {
+	return k == Income || k == Expense
+}
+
+// IsEmpty reports if the patch does not update any field.
+func (p CategoryPatch) IsEmpty() bool {
+	return p.Name == nil && p.Archived == nil && p.Icon == nil && p.Color == nil
+}
+
+// defaults is the category set created for every new user.
+var defaults = []Category{
+	{Name: "Salary", Kind: Income, Icon: "briefcase", Color: "#2E7D32"},
+	{Name: "Investments", Kind: Income, Icon: "trending-up", Color: "#1B5E20"},
+	{Name: "Gifts", Kind: Income, Icon: "gift", Color: "#43A047"},
+	{Name: "Other income", Kind: Income, Icon: "plus-circle", Color: "#66BB6A"},
+	{Name: "Housing", Kind: Expense, Icon: "home", Color: "#5D4037", Children: []Category{
+		{Name: "Rent", Kind: Expense, Icon: "key", Color: "#6D4C41"},
+		{Name: "Utilities", Kind: Expense, Icon: "zap", Color: "#795548"},
+	}},
+	{Name: "Food", Kind: Expense, Icon: "shopping-cart", Color: "#EF6C00", Children: []Category{
+		{Name: "Groceries", Kind: Expense, Icon: "shopping-bag", Color: "#F57C00"},
+		{Name: "Restaurants", Kind: Expense, Icon: "coffee", Color: "#FB8C00"},
+	}},
+	{Name: "Transportation", Kind: Expense, Icon: "truck", Color: "#1565C0"},
+	{Name: "Health", Kind: Expense, Icon: "heart", Color: "#C62828"},
+	{Name: "Entertainment", Kind: Expense, Icon: "film", Color: "#6A1B9A"},
+	{Name: "Shopping", Kind: Expense, Icon: "tag", Color: "#AD1457"},
+	{Name: "Education", Kind: Expense, Icon: "book", Color: "#00838F"},
+	{Name: "Other expenses", Kind: Expense, Icon: "more-horizontal", Color: "#546E7A"},
+}
@@ -0,0 +1,356 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.category.repository")
+
+// Repository is the interface for the category and tag repository.
+// All the operations are scoped to the categories and tags of the given user.
+type Repository interface {
+	Create(ctx context.Context, category Category) (Category, error)
+	CreateTree(ctx context.Context, userID uint, categories []Category) error
+	GetAll(ctx context.Context, userID uint) ([]Category, error)
+	GetByID(ctx context.Context, userID, id uint) (Category, error)
+	Update(ctx context.Context, userID, id uint, patch CategoryPatch) error
+	Move(ctx context.Context, userID, id uint, parentID *uint) error
+	Merge(ctx context.Context, userID, sourceID, targetID uint) error
+	Delete(ctx context.Context, userID, id uint) error
+	SetTransactionCategory(ctx context.Context, userID, transactionID uint, categoryID *uint) error
+
+	CreateTag(ctx context.Context, tag Tag) (Tag, error)
+	GetTags(ctx context.Context, userID uint) ([]Tag, error)
+	GetTagsByIDs(ctx context.Context, userID uint, ids []uint) ([]Tag, error)
+	DeleteTag(ctx context.Context, userID, id uint) error
+	GetTransactionTags(ctx context.Context, transactionID uint) ([]Tag, error)
+	SetTransactionTags(ctx context.Context, transactionID uint, tagIDs []uint) error
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the category repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewCategoryRepository creates a new category repository.
+func NewCategoryRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// Create creates a new category and returns it with its id.
+func (r *RepositoryImpl) Create(ctx context.Context, category Category) (Category, error) {
+	if err := r.db.Create(ctx, &category); err != nil {
+		loggerRepo.WithError(err).Error("Error creating record in the database")
+
+		return Category{}, err
+	}
+
+	return category, nil
+}
+
+// CreateTree creates the categories and their children for the user atomically.
+func (r *RepositoryImpl) CreateTree(ctx context.Context, userID uint, categories []Category) error {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		return createTree(ctx, tx, userID, nil, categories)
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error creating the category tree in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+func createTree(ctx context.Context, tx gorm.Gorm, userID uint, parentID *uint, categories []Category) error {
+	for _, category := range categories {
+		children := category.Children
+
+		category.UserID = userID
+		category.ParentID = parentID
+		category.Children = nil
+
+		if err := tx.Create(ctx, &category); err != nil {
+			return err
+		}
+
+		id := category.ID
+		if err := createTree(ctx, tx, userID, &id, children); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAll returns all the categories of the user, sorted by id.
+func (r *RepositoryImpl) GetAll(ctx context.Context, userID uint) ([]Category, error) {
+	var categories []Category
+	if err := r.db.Order("id ASC").WhereFind(ctx, &categories, "user_id = ?", userID); err != nil {
+		loggerRepo.WithError(err).Error("Error getting records from the database")
+
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// GetByID returns the category of the user with the given id.
+func (r *RepositoryImpl) GetByID(ctx context.Context, userID, id uint) (Category, error) {
+	var category Category
+	if err := r.db.WhereFirst(ctx, &category, "user_id = ? AND id = ?", userID, id); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the category by id")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return Category{}, fmt.Errorf(crosscuting.WrapLabel, "Category not found", ErrCategoryNotFound, err.Error())
+		}
+
+		return Category{}, err
+	}
+
+	return category, nil
+}
+
+// Update updates the non nil fields of the patch in the category of the user.
+func (r *RepositoryImpl) Update(ctx context.Context, userID, id uint, patch CategoryPatch) error {
+	values := map[string]interface{}{}
+
+	if patch.Name != nil {
+		values["name"] = *patch.Name
+	}
+
+	if patch.Archived != nil {
+		values["archived"] = *patch.Archived
+	}
+
+	if patch.Icon != nil {
+		values["icon"] = *patch.Icon
+	}
+
+	if patch.Color != nil {
+		values["color"] = *patch.Color
+	}
+
+	return r.updateCategory(ctx, userID, id, values)
+}
+
+// Move changes the parent of the category of the user, a nil parent makes it a root category.
+func (r *RepositoryImpl) Move(ctx context.Context, userID, id uint, parentID *uint) error {
+	return r.updateCategory(ctx, userID, id, map[string]interface{}{"parent_id": parentID})
+}
+
+func (r *RepositoryImpl) updateCategory(ctx context.Context, userID, id uint, values map[string]interface{}) error {
+	rows, err := r.db.WhereUpdates(ctx, &Category{}, values, "user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error updating record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Category not found", ErrCategoryNotFound)
+	}
+
+	return nil
+}
+
+// Merge moves the transactions and the children of the source category to the target and deletes the source, atomically.
+func (r *RepositoryImpl) Merge(ctx context.Context, userID, sourceID, targetID uint) error {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		if _, err := tx.WhereUpdates(ctx, &ledger.Transaction{}, map[string]interface{}{"category_id": targetID},
+			"user_id = ? AND category_id = ?", userID, sourceID); err != nil {
+			return err
+		}
+
+		if _, err := tx.WhereUpdates(ctx, &Category{}, map[string]interface{}{"parent_id": targetID},
+			"user_id = ? AND parent_id = ?", userID, sourceID); err != nil {
+			return err
+		}
+
+		return deleteCategory(ctx, tx, userID, sourceID)
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error merging the categories in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the category from its transactions and soft deletes it, atomically.
+func (r *RepositoryImpl) Delete(ctx context.Context, userID, id uint) error {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		if _, err := tx.WhereUpdates(ctx, &ledger.Transaction{}, map[string]interface{}{"category_id": nil},
+			"user_id = ? AND category_id = ?", userID, id); err != nil {
+			return err
+		}
+
+		return deleteCategory(ctx, tx, userID, id)
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error deleting the category in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+func deleteCategory(ctx context.Context, tx gorm.Gorm, userID, id uint) error {
+	rows, err := tx.WhereDelete(ctx, &Category{}, "user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Category not found", ErrCategoryNotFound)
+	}
+
+	return nil
+}
+
+// SetTransactionCategory sets the category of the transaction of the user, nil removes it.
+func (r *RepositoryImpl) SetTransactionCategory(ctx context.Context, userID, transactionID uint, categoryID *uint) error {
+	rows, err := r.db.WhereUpdates(ctx, &ledger.Transaction{}, map[string]interface{}{"category_id": categoryID},
+		"user_id = ? AND id = ?", userID, transactionID)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error setting the category of the transaction")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Transaction not found", ledger.ErrTransactionNotFound)
+	}
+
+	return nil
+}
+
+// CreateTag creates a new tag and returns it with its id.
+func (r *RepositoryImpl) CreateTag(ctx context.Context, tag Tag) (Tag, error) {
+	if err := r.db.Create(ctx, &tag); err != nil {
+		loggerRepo.WithError(err).Error("Error creating record in the database")
+
+		return Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// GetTags returns all the tags of the user sorted by name.
+func (r *RepositoryImpl) GetTags(ctx context.Context, userID uint) ([]Tag, error) {
+	var tags []Tag
+	if err := r.db.Order("name ASC").WhereFind(ctx, &tags, "user_id = ?", userID); err != nil {
+		loggerRepo.WithError(err).Error("Error getting records from the database")
+
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// GetTagsByIDs returns the tags of the user with the given ids.
+func (r *RepositoryImpl) GetTagsByIDs(ctx context.Context, userID uint, ids []uint) ([]Tag, error) {
+	var tags []Tag
+	if err := r.db.WhereFind(ctx, &tags, "user_id = ? AND id IN ?", userID, ids); err != nil {
+		loggerRepo.WithError(err).Error("Error getting records from the database")
+
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// DeleteTag removes the tag from its transactions and soft deletes it, atomically.
+func (r *RepositoryImpl) DeleteTag(ctx context.Context, userID, id uint) error {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		rows, err := tx.WhereDelete(ctx, &Tag{}, "user_id = ? AND id = ?", userID, id)
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Tag not found", ErrTagNotFound)
+		}
+
+		_, err = tx.WhereDelete(ctx, &TransactionTag{}, "tag_id = ?", id)
+
+		return err
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error deleting the tag in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// GetTransactionTags returns the tags attached to the transaction sorted by name.
+func (r *RepositoryImpl) GetTransactionTags(ctx context.Context, transactionID uint) ([]Tag, error) {
+	var links []TransactionTag
+	if err := r.db.WhereFind(ctx, &links, "transaction_id = ?", transactionID); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the tags of the transaction")
+
+		return nil, err
+	}
+
+	tags := []Tag{}
+	if len(links) == 0 {
+		return tags, nil
+	}
+
+	ids := make([]uint, len(links))
+	for i, link := range links {
+		ids[i] = link.TagID
+	}
+
+	if err := r.db.Order("name ASC").WhereFind(ctx, &tags, "id IN ?", ids); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the tags of the transaction")
+
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// SetTransactionTags replaces the tags attached to the transaction, atomically.
+func (r *RepositoryImpl) SetTransactionTags(ctx context.Context, transactionID uint, tagIDs []uint) error {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		if _, err := tx.WhereDelete(ctx, &TransactionTag{}, "transaction_id = ?", transactionID); err != nil {
+			return err
+		}
+
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		links := make([]TransactionTag, len(tagIDs))
+		for i, tagID := range tagIDs {
+			links[i] = TransactionTag{TransactionID: transactionID, TagID: tagID}
+		}
+
+		return tx.Create(ctx, &links)
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error setting the tags of the transaction")
+
+		return err
+	}
+
+	return nil
+}
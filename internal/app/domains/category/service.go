@@ -0,0 +1,462 @@
+package category
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerService = logger.Setup("domain.category.service")
+	// ErrCategoryNotFound is returned when the category does not exist or belongs to another user.
+	ErrCategoryNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "CATEGORY_NOT_FOUND", "category not found error")
+	// ErrTagNotFound is returned when the tag does not exist or belongs to another user.
+	ErrTagNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "TAG_NOT_FOUND", "tag not found error")
+	// ErrInvalidCategory is returned when the fields or the position of a category are not valid.
+	ErrInvalidCategory = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_CATEGORY", "invalid category error")
+	// ErrCategoryHasChildren is returned when deleting a category with children.
+	ErrCategoryHasChildren = crosscuting.NewTypedError(crosscuting.ErrConflict, "CATEGORY_HAS_CHILDREN", "category has children error")
+)
+
+// Service is the interface for the category and tag service.
+type Service interface {
+	SeedDefaults(ctx context.Context, userID uint) error
+	Create(ctx context.Context, category Category) (Category, error)
+	GetTree(ctx context.Context, userID uint, includeArchived bool) ([]Category, error)
+	GetByID(ctx context.Context, userID, id uint) (Category, error)
+	GetDescendantIDs(ctx context.Context, userID, id uint) ([]uint, error)
+	Update(ctx context.Context, userID, id uint, patch CategoryPatch) (Category, error)
+	Move(ctx context.Context, userID, id uint, parentID *uint) (Category, error)
+	Merge(ctx context.Context, userID, sourceID, targetID uint) (Category, error)
+	Delete(ctx context.Context, userID, id uint) error
+	SetTransactionCategory(ctx context.Context, userID, transactionID uint, categoryID *uint) error
+
+	CreateTag(ctx context.Context, tag Tag) (Tag, error)
+	GetTags(ctx context.Context, userID uint) ([]Tag, error)
+	DeleteTag(ctx context.Context, userID, id uint) error
+	GetTransactionTags(ctx context.Context, userID, transactionID uint) ([]Tag, error)
+	SetTransactionTags(ctx context.Context, userID, transactionID uint, tagIDs []uint) ([]Tag, error)
+
+	WithTx(tx gorm.Gorm) Service
+}
+
+// ServiceImpl is the struct that contains the category service.
+// The database runs the units of work that read and change several categories.
+type ServiceImpl struct {
+	db            gorm.Gorm
+	repo          Repository
+	ledgerService ledger.Service
+}
+
+// NewCategoryService creates a new category service.
+func NewCategoryService(db gorm.Gorm, repo Repository, ledgerService ledger.Service) Service {
+	return &ServiceImpl{db: db, repo: repo, ledgerService: ledgerService}
+}
+
+// WithTx returns the service with its repository bound to the transaction, so its writes are part of it.
+func (s *ServiceImpl) WithTx(tx gorm.Gorm) Service {
+	return s.withTx(tx)
+}
+
+// SeedDefaults creates the default category set for a new user.
+func (s *ServiceImpl) SeedDefaults(ctx context.Context, userID uint) error {
+	if err := s.repo.CreateTree(ctx, userID, defaults); err != nil {
+		loggerService.WithError(err).Error("Error seeding the default categories")
+		return err
+	}
+
+	return nil
+}
+
+// Create creates a new category, the children must have the kind of their parent.
+func (s *ServiceImpl) Create(ctx context.Context, category Category) (Category, error) {
+	if strings.TrimSpace(category.Name) == "" {
+		return Category{}, invalidCategory("The name is required")
+	}
+
+	if !category.Kind.IsValid() {
+		return Category{}, invalidCategory(fmt.Sprintf("Unknown category kind %s", category.Kind))
+	}
+
+	if category.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, category.UserID, *category.ParentID)
+		if err != nil {
+			return Category{}, err
+		}
+
+		if parent.Kind != category.Kind {
+			return Category{}, invalidCategory("The category must have the kind of its parent")
+		}
+	}
+
+	category.Children = nil
+
+	created, err := s.repo.Create(ctx, category)
+	if err != nil {
+		loggerService.WithError(err).Error("Error creating the category")
+		return Category{}, err
+	}
+
+	return created, nil
+}
+
+// GetTree returns the root categories of the user with their children nested.
+// Archived categories and their children are excluded unless includeArchived is true.
+func (s *ServiceImpl) GetTree(ctx context.Context, userID uint, includeArchived bool) ([]Category, error) {
+	categories, err := s.repo.GetAll(ctx, userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the categories from the repo")
+		return nil, err
+	}
+
+	childrenOf := map[uint][]Category{}
+	var roots []Category
+
+	for _, category := range categories {
+		if category.Archived && !includeArchived {
+			continue
+		}
+
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+
+		childrenOf[*category.ParentID] = append(childrenOf[*category.ParentID], category)
+	}
+
+	return nest(roots, childrenOf), nil
+}
+
+// GetByID returns the category of the user with the given id.
+func (s *ServiceImpl) GetByID(ctx context.Context, userID, id uint) (Category, error) {
+	category, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the category from the repo")
+		return Category{}, err
+	}
+
+	return category, nil
+}
+
+// GetDescendantIDs returns the id of the category of the user and the ids of all its descendants.
+func (s *ServiceImpl) GetDescendantIDs(ctx context.Context, userID, id uint) ([]uint, error) {
+	categories, err := s.repo.GetAll(ctx, userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the categories from the repo")
+		return nil, err
+	}
+
+	childrenOf := map[uint][]uint{}
+	found := false
+
+	for _, category := range categories {
+		if category.ID == id {
+			found = true
+		}
+
+		if category.ParentID != nil {
+			childrenOf[*category.ParentID] = append(childrenOf[*category.ParentID], category.ID)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, "Category not found", ErrCategoryNotFound)
+	}
+
+	ids := []uint{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, childrenOf[ids[i]]...)
+	}
+
+	return ids, nil
+}
+
+func nest(categories []Category, childrenOf map[uint][]Category) []Category {
+	tree := make([]Category, len(categories))
+
+	for i, category := range categories {
+		category.Children = nest(childrenOf[category.ID], childrenOf)
+		tree[i] = category
+	}
+
+	return tree
+}
+
+// Update updates the category of the user with the given fields and returns it updated.
+func (s *ServiceImpl) Update(ctx context.Context, userID, id uint, patch CategoryPatch) (Category, error) {
+	if patch.IsEmpty() {
+		return Category{}, invalidCategory("No fields to update")
+	}
+
+	if patch.Name != nil && strings.TrimSpace(*patch.Name) == "" {
+		return Category{}, invalidCategory("The name can not be empty")
+	}
+
+	if err := s.repo.Update(ctx, userID, id, patch); err != nil {
+		loggerService.WithError(err).Error("Error updating the category")
+		return Category{}, err
+	}
+
+	return s.repo.GetByID(ctx, userID, id)
+}
+
+// Move changes the parent of the category, a nil parent makes it a root category.
+// The parent must have the same kind and can not be the category or one of its descendants.
+func (s *ServiceImpl) Move(ctx context.Context, userID, id uint, parentID *uint) (Category, error) {
+	category, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return Category{}, err
+	}
+
+	if parentID != nil {
+		parent, err := s.repo.GetByID(ctx, userID, *parentID)
+		if err != nil {
+			return Category{}, err
+		}
+
+		if parent.Kind != category.Kind {
+			return Category{}, invalidCategory("The category must have the kind of its parent")
+		}
+
+		if err := s.checkNotDescendant(ctx, userID, id, parent.ID); err != nil {
+			return Category{}, err
+		}
+	}
+
+	if err := s.repo.Move(ctx, userID, id, parentID); err != nil {
+		loggerService.WithError(err).Error("Error moving the category")
+		return Category{}, err
+	}
+
+	return s.repo.GetByID(ctx, userID, id)
+}
+
+// Merge moves the transactions and the children of the source category to the target and deletes the source.
+// Both must have the same kind and the target can not be a descendant of the source.
+func (s *ServiceImpl) Merge(ctx context.Context, userID, sourceID, targetID uint) (Category, error) {
+	var merged Category
+
+	// The checks, the merge and the read of the target run in one transaction, so a failed merge changes nothing.
+	err := s.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		var err error
+		merged, err = s.withTx(tx).merge(ctx, userID, sourceID, targetID)
+
+		return err
+	})
+	if err != nil {
+		return Category{}, err
+	}
+
+	return merged, nil
+}
+
+// merge moves the transactions and the children of the source category to the target, deletes the source
+// and returns the target after the merge.
+func (s *ServiceImpl) merge(ctx context.Context, userID, sourceID, targetID uint) (Category, error) {
+	source, err := s.repo.GetByID(ctx, userID, sourceID)
+	if err != nil {
+		return Category{}, err
+	}
+
+	target, err := s.repo.GetByID(ctx, userID, targetID)
+	if err != nil {
+		return Category{}, err
+	}
+
+	if source.Kind != target.Kind {
+		return Category{}, invalidCategory("Only categories of the same kind can be merged")
+	}
+
+	if err := s.checkNotDescendant(ctx, userID, sourceID, targetID); err != nil {
+		return Category{}, err
+	}
+
+	if err := s.repo.Merge(ctx, userID, sourceID, targetID); err != nil {
+		loggerService.WithError(err).Error("Error merging the categories")
+		return Category{}, err
+	}
+
+	return s.repo.GetByID(ctx, userID, targetID)
+}
+
+// Delete soft deletes a category without children, its transactions become uncategorized.
+func (s *ServiceImpl) Delete(ctx context.Context, userID, id uint) error {
+	categories, err := s.repo.GetAll(ctx, userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the categories from the repo")
+		return err
+	}
+
+	for _, category := range categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			desc := "Move or delete the children of the category first"
+			loggerService.WithError(ErrCategoryHasChildren).Error(desc)
+			return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrCategoryHasChildren)
+		}
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		loggerService.WithError(err).Error("Error deleting the category")
+		return err
+	}
+
+	return nil
+}
+
+// SetTransactionCategory sets the category of a transaction of the user, nil removes it.
+func (s *ServiceImpl) SetTransactionCategory(ctx context.Context, userID, transactionID uint, categoryID *uint) error {
+	if categoryID != nil {
+		category, err := s.repo.GetByID(ctx, userID, *categoryID)
+		if err != nil {
+			return err
+		}
+
+		if category.Archived {
+			return invalidCategory("The category is archived")
+		}
+	}
+
+	if err := s.repo.SetTransactionCategory(ctx, userID, transactionID, categoryID); err != nil {
+		loggerService.WithError(err).Error("Error setting the category of the transaction")
+		return err
+	}
+
+	return nil
+}
+
+// CreateTag creates a new tag for the user.
+func (s *ServiceImpl) CreateTag(ctx context.Context, tag Tag) (Tag, error) {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return Tag{}, invalidCategory("The name of the tag is required")
+	}
+
+	created, err := s.repo.CreateTag(ctx, tag)
+	if err != nil {
+		loggerService.WithError(err).Error("Error creating the tag")
+		return Tag{}, err
+	}
+
+	return created, nil
+}
+
+// GetTags returns the tags of the user.
+func (s *ServiceImpl) GetTags(ctx context.Context, userID uint) ([]Tag, error) {
+	tags, err := s.repo.GetTags(ctx, userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the tags from the repo")
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// DeleteTag soft deletes the tag of the user and removes it from its transactions.
+func (s *ServiceImpl) DeleteTag(ctx context.Context, userID, id uint) error {
+	if err := s.repo.DeleteTag(ctx, userID, id); err != nil {
+		loggerService.WithError(err).Error("Error deleting the tag")
+		return err
+	}
+
+	return nil
+}
+
+// GetTransactionTags returns the tags of a transaction of the user.
+func (s *ServiceImpl) GetTransactionTags(ctx context.Context, userID, transactionID uint) ([]Tag, error) {
+	if _, err := s.ledgerService.GetTransaction(ctx, userID, transactionID); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.GetTransactionTags(ctx, transactionID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the tags of the transaction")
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// SetTransactionTags replaces the tags of a transaction of the user and returns them.
+func (s *ServiceImpl) SetTransactionTags(ctx context.Context, userID, transactionID uint, tagIDs []uint) ([]Tag, error) {
+	if _, err := s.ledgerService.GetTransaction(ctx, userID, transactionID); err != nil {
+		return nil, err
+	}
+
+	tagIDs = unique(tagIDs)
+
+	if len(tagIDs) > 0 {
+		tags, err := s.repo.GetTagsByIDs(ctx, userID, tagIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(tags) != len(tagIDs) {
+			desc := "Some of the tags do not exist"
+			loggerService.WithError(ErrTagNotFound).Error(desc)
+			return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrTagNotFound)
+		}
+	}
+
+	if err := s.repo.SetTransactionTags(ctx, transactionID, tagIDs); err != nil {
+		loggerService.WithError(err).Error("Error setting the tags of the transaction")
+		return nil, err
+	}
+
+	return s.repo.GetTransactionTags(ctx, transactionID)
+}
+
+func (s *ServiceImpl) withTx(tx gorm.Gorm) *ServiceImpl {
+	return &ServiceImpl{db: tx, repo: s.repo.WithTx(tx), ledgerService: s.ledgerService}
+}
+
+// checkNotDescendant returns an error if the candidate is the category or one of its descendants.
+func (s *ServiceImpl) checkNotDescendant(ctx context.Context, userID, id, candidateID uint) error {
+	categories, err := s.repo.GetAll(ctx, userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the categories from the repo")
+		return err
+	}
+
+	parentOf := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parentOf[category.ID] = category.ParentID
+	}
+
+	// Walking up from the candidate, the category must not be found. The walk is bounded against corrupted trees.
+	current := &candidateID
+	for steps := 0; current != nil && steps <= len(categories); steps++ {
+		if *current == id {
+			return invalidCategory("A category can not be moved or merged into itself or its descendants")
+		}
+
+		current = parentOf[*current]
+	}
+
+	return nil
+}
+
+func unique(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+func invalidCategory(desc string) error {
+	loggerService.WithError(ErrInvalidCategory).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidCategory)
+}
@@ -0,0 +1,57 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
+
+func TestServiceMerge(t *testing.T) {
+	ctx := context.Background()
+	db := gorm.NewMemoryGorm()
+	service := NewCategoryService(db, NewCategoryRepository(db), nil)
+
+	create := func(name string, parentID *uint) Category {
+		t.Helper()
+
+		created, err := service.Create(ctx, Category{UserID: 1, Name: name, Kind: Expense, ParentID: parentID})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		return created
+	}
+
+	food := create("Food", nil)
+	eatingOut := create("Eating out", nil)
+	restaurants := create("Restaurants", &eatingOut.ID)
+
+	if _, err := service.Merge(ctx, 1, eatingOut.ID, restaurants.ID); !errors.Is(err, crosscuting.ErrValidation) {
+		t.Fatalf("Merge() into a descendant error = %v, want %v", err, crosscuting.ErrValidation)
+	}
+
+	if _, err := service.GetByID(ctx, 1, eatingOut.ID); err != nil {
+		t.Fatalf("the failed Merge() deleted the source: %v", err)
+	}
+
+	merged, err := service.Merge(ctx, 1, eatingOut.ID, food.ID)
+	if err != nil || merged.ID != food.ID {
+		t.Fatalf("Merge() = %+v, %v, want the target", merged, err)
+	}
+
+	if _, err := service.GetByID(ctx, 1, eatingOut.ID); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("GetByID() of the merged source error = %v, want %v", err, ErrCategoryNotFound)
+	}
+
+	child, err := service.GetByID(ctx, 1, restaurants.ID)
+	if err != nil || child.ParentID == nil || *child.ParentID != food.ID {
+		t.Errorf("the child of the source = %+v, %v, want it under the target", child, err)
+	}
+
+	if _, err := service.Merge(ctx, 1, food.ID, 99); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("Merge() into a missing category error = %v, want %v", err, ErrCategoryNotFound)
+	}
+}
@@ -1,6 +1,16 @@
 package finasset
 
-import "github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
+
+var errScanMetadata = errors.New("scan metadata error")
 
 const (
 	// Currency is the type for the currency.
@@ -18,9 +28,137 @@ type (
 	// Currency is the struct for the currency.
 	FinancialAsset struct {
 		gorm.Model
-		Symbol string    `json:"symbol" gorm:"not null;unique"`
-		Name   string    `json:"name" gorm:"not null"`
-		Desc   string    `json:"desc" gorm:"not null;column:description"`
-		Type   AssetType `json:"type" gorm:"not null"`
+		Symbol   string    `json:"symbol" gorm:"not null"`
+		Name     string    `json:"name" gorm:"not null"`
+		Desc     string    `json:"desc" gorm:"not null;column:description"`
+		Type     AssetType `json:"type" gorm:"not null"`
+		Metadata Metadata  `json:"metadata" gorm:"type:jsonb;not null;default:'{}'"`
+	}
+
+	// Metadata is the struct for the type specific data of a financial asset, stored as JSON.
+	// The fields allowed and required for each type are defined in the registry.
+	Metadata struct {
+		// Securities: stocks, bonds, ETFs and mutual funds.
+		ISIN        string `json:"isin,omitempty"`
+		CUSIP       string `json:"cusip,omitempty"`
+		ExchangeMIC string `json:"exchange_mic,omitempty"`
+		// Currencies, ISO 4217.
+		NumericCode string `json:"numeric_code,omitempty"`
+		MinorUnits  *int   `json:"minor_units,omitempty"`
+		// Crypto currencies.
+		Chain     string `json:"chain,omitempty"`
+		Precision *int   `json:"precision,omitempty"`
+	}
+
+	// Page is the struct for a page of financial assets.
+	Page struct {
+		Items      []FinancialAsset
+		NextCursor string
+		Total      int64
+	}
+
+	// FinancialAssetPatch is the struct for the partial update of a financial asset, nil fields are not updated.
+	FinancialAssetPatch struct {
+		Symbol   *string
+		Name     *string
+		Desc     *string
+		Type     *AssetType
+		Metadata *Metadata
 	}
 )
+
+// DefaultMinorUnits are the decimals used for the assets without minor units in the metadata.
+const DefaultMinorUnits = 2
+
+// MinorUnits returns the number of decimals of the amounts of the asset.
+// Currencies use their ISO 4217 minor units and crypto currencies their precision.
+func (a FinancialAsset) MinorUnits() int32 {
+	switch {
+	case a.Type == Currency && a.Metadata.MinorUnits != nil:
+		return int32(*a.Metadata.MinorUnits)
+	case a.Type == Crypto && a.Metadata.Precision != nil:
+		return int32(*a.Metadata.Precision)
+	default:
+		return DefaultMinorUnits
+	}
+}
+
+// IsEmpty reports if the patch does not update any field.
+func (p FinancialAssetPatch) IsEmpty() bool {
+	return p.Symbol == nil && p.Name == nil && p.Desc == nil && p.Type == nil && p.Metadata == nil
+}
+
+// Apply returns the financial asset with the non nil fields of the patch.
+func (p FinancialAssetPatch) Apply(finAsset FinancialAsset) FinancialAsset {
+	if p.Symbol != nil {
+		finAsset.Symbol = *p.Symbol
+	}
+
+	if p.Name != nil {
+		finAsset.Name = *p.Name
+	}
+
+	if p.Desc != nil {
+		finAsset.Desc = *p.Desc
+	}
+
+	if p.Type != nil {
+		finAsset.Type = *p.Type
+	}
+
+	if p.Metadata != nil {
+		finAsset.Metadata = *p.Metadata
+	}
+
+	return finAsset
+}
+
+// Value implements the driver.Valuer interface to store the metadata as JSON.
+func (m Metadata) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(bytes), nil
+}
+
+// Scan implements the sql.Scanner interface to read the metadata from JSON.
+func (m *Metadata) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = Metadata{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	default:
+		return fmt.Errorf("%w: unsupported type %T", errScanMetadata, value)
+	}
+}
+
+// fields returns the JSON names of the fields set in the metadata.
+func (m Metadata) fields() []string {
+	var fields []string
+
+	set := map[string]bool{
+		"isin":         m.ISIN != "",
+		"cusip":        m.CUSIP != "",
+		"exchange_mic": m.ExchangeMIC != "",
+		"numeric_code": m.NumericCode != "",
+		"minor_units":  m.MinorUnits != nil,
+		"chain":        m.Chain != "",
+		"precision":    m.Precision != nil,
+	}
+
+	for field, ok := range set {
+		if ok {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+
+	return fields
+}
@@ -0,0 +1,190 @@
+package finasset
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// IntervalRaw returns the prices as they are stored.
+	IntervalRaw Interval = "raw"
+	// IntervalDaily downsamples the prices to one candle per day.
+	IntervalDaily Interval = "daily"
+	// IntervalWeekly downsamples the prices to one candle per ISO week, starting on monday.
+	IntervalWeekly Interval = "weekly"
+	// IntervalMonthly downsamples the prices to one candle per month.
+	IntervalMonthly Interval = "monthly"
+
+	// MaxPricesBatch is the maximum number of prices in a single upsert.
+	MaxPricesBatch = 1000
+)
+
+var (
+	// ErrInvalidPrice is returned when a price candle is not consistent.
+	ErrInvalidPrice = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_PRICE", "invalid price error")
+
+	quoteCurrencyRegex = regexp.MustCompile(`^[A-Z0-9]{2,16}$`)
+)
+
+type (
+	// Interval is the size of the buckets used to downsample the price history.
+	Interval string
+
+	// Price is the struct for an OHLCV candle of a financial asset quoted in a currency.
+	// The prices are decimals instead of money.Money: the four of them share the quote currency, which is part of
+	// the unique key of the candles, and the volume is a quantity of the asset. They also serve as exchange rates
+	// for the currency pairs. Use money.New(price.Close, price.QuoteCurrency) to operate a price as an amount.
+	Price struct {
+		gorm.Model
+		AssetID       uint            `json:"asset_id" gorm:"not null"`
+		Timestamp     time.Time       `json:"timestamp" gorm:"not null"`
+		Open          decimal.Decimal `json:"open" gorm:"type:numeric;not null"`
+		High          decimal.Decimal `json:"high" gorm:"type:numeric;not null"`
+		Low           decimal.Decimal `json:"low" gorm:"type:numeric;not null"`
+		Close         decimal.Decimal `json:"close" gorm:"type:numeric;not null"`
+		Volume        decimal.Decimal `json:"volume" gorm:"type:numeric;not null"`
+		Source        string          `json:"source" gorm:"not null"`
+		QuoteCurrency string          `json:"quote_currency" gorm:"not null"`
+	}
+
+	// PriceQuery is the struct for the filters of the price history.
+	PriceQuery struct {
+		From          time.Time
+		To            time.Time
+		Interval      Interval
+		QuoteCurrency string
+	}
+)
+
+// TableName overrides the table name of the prices.
+func (Price) TableName() string {
+	return "asset_prices"
+}
+
+// Validate checks that the candle is consistent.
+func (p Price) Validate() error {
+	var desc string
+
+	switch {
+	case p.Timestamp.IsZero():
+		desc = "the timestamp is required"
+	case !quoteCurrencyRegex.MatchString(p.QuoteCurrency):
+		desc = "the quote_currency must be the symbol of the currency"
+	case p.Source == "":
+		desc = "the source is required"
+	case p.Low.IsNegative() || p.Volume.IsNegative():
+		desc = "the prices and the volume can not be negative"
+	case p.High.LessThan(p.Low):
+		desc = "the high can not be less than the low"
+	case p.Open.LessThan(p.Low) || p.Open.GreaterThan(p.High):
+		desc = "the open must be between the low and the high"
+	case p.Close.LessThan(p.Low) || p.Close.GreaterThan(p.High):
+		desc = "the close must be between the low and the high"
+	default:
+		return nil
+	}
+
+	return fmt.Errorf(crosscuting.WrapLabel, desc, ErrInvalidPrice, p.Timestamp.Format(time.RFC3339))
+}
+
+// uniquePrices returns the prices without the repeated quote currencies and timestamps, keeping the last price of each,
+// since an upsert can not update the same row twice.
+func uniquePrices(prices []Price) []Price {
+	type key struct {
+		quoteCurrency string
+		timestamp     time.Time
+	}
+
+	positions := make(map[key]int, len(prices))
+	unique := make([]Price, 0, len(prices))
+
+	for _, price := range prices {
+		k := key{quoteCurrency: price.QuoteCurrency, timestamp: price.Timestamp}
+
+		if i, ok := positions[k]; ok {
+			unique[i] = price
+			continue
+		}
+
+		positions[k] = len(unique)
+		unique = append(unique, price)
+	}
+
+	return unique
+}
+
+// IsValid reports if the interval is supported.
+func (i Interval) IsValid() bool {
+	switch i {
+	case IntervalRaw, IntervalDaily, IntervalWeekly, IntervalMonthly:
+		return true
+	default:
+		return false
+	}
+}
+
+// bucketStart returns the start of the bucket of the interval that contains the time, in UTC.
+func (i Interval) bucketStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch i {
+	case IntervalDaily:
+		return day
+	case IntervalWeekly:
+		// time.Weekday starts on sunday, the ISO week starts on monday.
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case IntervalMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return t
+	}
+}
+
+// downsample aggregates the prices, sorted by timestamp, into one candle per bucket and quote currency.
+// The open is the first open, the close the last close, the high the max, the low the min and the volume the sum.
+func downsample(prices []Price, interval Interval) []Price {
+	if interval == IntervalRaw || interval == "" {
+		return prices
+	}
+
+	type bucketKey struct {
+		quote string
+		start time.Time
+	}
+
+	var (
+		buckets []Price
+		index   = map[bucketKey]int{}
+	)
+
+	for _, price := range prices {
+		key := bucketKey{quote: price.QuoteCurrency, start: interval.bucketStart(price.Timestamp)}
+
+		i, ok := index[key]
+		if !ok {
+			candle := price
+			candle.Model = gorm.Model{}
+			candle.Timestamp = key.start
+			index[key] = len(buckets)
+			buckets = append(buckets, candle)
+
+			continue
+		}
+
+		candle := &buckets[i]
+		candle.High = decimal.Max(candle.High, price.High)
+		candle.Low = decimal.Min(candle.Low, price.Low)
+		candle.Close = price.Close
+		candle.Volume = candle.Volume.Add(price.Volume)
+		candle.Source = price.Source
+	}
+
+	return buckets
+}
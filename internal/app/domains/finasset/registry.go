@@ -0,0 +1,316 @@
+package finasset
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+)
+
+const (
+	// Bond is the type for the bonds.
+	Bond AssetType = "bond"
+	// ETF is the type for the exchange traded funds.
+	ETF AssetType = "etf"
+	// MutualFund is the type for the mutual funds.
+	MutualFund AssetType = "mutual_fund"
+	// Commodity is the type for the commodities like gold or oil.
+	Commodity AssetType = "commodity"
+	// RealEstate is the type for the real estate properties.
+	RealEstate AssetType = "real_estate"
+)
+
+var (
+	// ErrInvalidAssetType is returned when the type is not in the registry.
+	ErrInvalidAssetType = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_ASSET_TYPE", "invalid asset type error")
+	// ErrInvalidMetadata is returned when the metadata is not valid for the type of the asset.
+	ErrInvalidMetadata = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_ASSET_METADATA", "invalid asset metadata error")
+
+	currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+	numericCodeRegex  = regexp.MustCompile(`^[0-9]{3}$`)
+	isinRegex         = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{9}[0-9]$`)
+	cusipRegex        = regexp.MustCompile(`^[A-Z0-9*@#]{8}[0-9]$`)
+	micRegex          = regexp.MustCompile(`^[A-Z0-9]{4}$`)
+
+	registry = map[AssetType]TypeSpec{}
+)
+
+// TypeSpec is the definition of an asset type in the registry.
+type TypeSpec struct {
+	Type AssetType `json:"type"`
+	Desc string    `json:"desc"`
+	// Fields are the metadata fields allowed for the type.
+	Fields []string `json:"fields"`
+	// Validate checks the symbol and the metadata of an asset of the type.
+	Validate func(symbol string, metadata Metadata) error `json:"-"`
+}
+
+func init() {
+	Register(TypeSpec{
+		Type:     Currency,
+		Desc:     "Fiat currency identified by its ISO 4217 codes",
+		Fields:   []string{"numeric_code", "minor_units"},
+		Validate: validateCurrency,
+	})
+	Register(TypeSpec{
+		Type:     Stock,
+		Desc:     "Listed company share",
+		Fields:   []string{"isin", "cusip", "exchange_mic"},
+		Validate: validateSecurity(true),
+	})
+	Register(TypeSpec{
+		Type:     ETF,
+		Desc:     "Exchange traded fund",
+		Fields:   []string{"isin", "cusip", "exchange_mic"},
+		Validate: validateSecurity(true),
+	})
+	Register(TypeSpec{
+		Type:     Bond,
+		Desc:     "Government or corporate debt security",
+		Fields:   []string{"isin", "cusip", "exchange_mic"},
+		Validate: validateSecurity(false),
+	})
+	Register(TypeSpec{
+		Type:     MutualFund,
+		Desc:     "Mutual fund share",
+		Fields:   []string{"isin", "cusip", "exchange_mic"},
+		Validate: validateSecurity(false),
+	})
+	Register(TypeSpec{
+		Type:     Crypto,
+		Desc:     "Crypto currency or token",
+		Fields:   []string{"chain", "precision"},
+		Validate: validateCrypto,
+	})
+	Register(TypeSpec{
+		Type:     Commodity,
+		Desc:     "Physical good like gold or oil",
+		Fields:   []string{"exchange_mic"},
+		Validate: validateCommodity,
+	})
+	Register(TypeSpec{
+		Type:     RealEstate,
+		Desc:     "Real estate property",
+		Fields:   []string{},
+		Validate: validateRealEstate,
+	})
+}
+
+// Register adds the asset type to the registry, replacing it if it already exists.
+func Register(spec TypeSpec) {
+	registry[spec.Type] = spec
+}
+
+// LookupType returns the definition of the asset type.
+func LookupType(assetType AssetType) (TypeSpec, error) {
+	spec, ok := registry[assetType]
+	if !ok {
+		return TypeSpec{}, fmt.Errorf(crosscuting.WrapLabel, "Unknown asset type", ErrInvalidAssetType, string(assetType))
+	}
+
+	return spec, nil
+}
+
+// Types returns the definitions of all the registered asset types sorted by type.
+func Types() []TypeSpec {
+	specs := make([]TypeSpec, 0, len(registry))
+	for _, spec := range registry {
+		specs = append(specs, spec)
+	}
+
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Type < specs[j].Type })
+
+	return specs
+}
+
+// Validate checks that the type of the asset is registered and that its metadata is valid for it.
+func Validate(finAsset FinancialAsset) error {
+	spec, err := LookupType(finAsset.Type)
+	if err != nil {
+		return err
+	}
+
+	allowed := make(map[string]bool, len(spec.Fields))
+	for _, field := range spec.Fields {
+		allowed[field] = true
+	}
+
+	for _, field := range finAsset.Metadata.fields() {
+		if !allowed[field] {
+			return invalidMetadata(fmt.Sprintf("the field %s is not allowed for the type %s", field, spec.Type))
+		}
+	}
+
+	return spec.Validate(finAsset.Symbol, finAsset.Metadata)
+}
+
+// ValidateSymbol checks that the type of the asset is registered and that the symbol is valid for it,
+// without the metadata. The assets created before the metadata existed have an empty one.
+func ValidateSymbol(assetType AssetType, symbol string) error {
+	if _, err := LookupType(assetType); err != nil {
+		return err
+	}
+
+	if assetType == Currency {
+		return validateCurrencySymbol(symbol)
+	}
+
+	return nil
+}
+
+func validateCurrency(symbol string, metadata Metadata) error {
+	if err := validateCurrencySymbol(symbol); err != nil {
+		return err
+	}
+
+	if !numericCodeRegex.MatchString(metadata.NumericCode) {
+		return invalidMetadata("the numeric_code must be the ISO 4217 numeric code of three digits")
+	}
+
+	if metadata.MinorUnits == nil || *metadata.MinorUnits < 0 || *metadata.MinorUnits > 4 {
+		return invalidMetadata("the minor_units must be between 0 and 4")
+	}
+
+	return nil
+}
+
+func validateCurrencySymbol(symbol string) error {
+	if !currencyCodeRegex.MatchString(symbol) {
+		return invalidMetadata("the symbol of a currency must be its ISO 4217 alphabetic code")
+	}
+
+	return nil
+}
+
+// validateSecurity returns the validation of a security, identified by ISIN or CUSIP.
+// Exchange traded securities also require the MIC of the exchange.
+func validateSecurity(exchangeRequired bool) func(string, Metadata) error {
+	return func(_ string, metadata Metadata) error {
+		if metadata.ISIN == "" && metadata.CUSIP == "" {
+			return invalidMetadata("the isin or the cusip is required")
+		}
+
+		if metadata.ISIN != "" && !validISIN(metadata.ISIN) {
+			return invalidMetadata("the isin is not valid")
+		}
+
+		if metadata.CUSIP != "" && !validCUSIP(metadata.CUSIP) {
+			return invalidMetadata("the cusip is not valid")
+		}
+
+		return validateMIC(metadata.ExchangeMIC, exchangeRequired)
+	}
+}
+
+func validateCrypto(_ string, metadata Metadata) error {
+	if strings.TrimSpace(metadata.Chain) == "" {
+		return invalidMetadata("the chain is required")
+	}
+
+	if metadata.Precision == nil || *metadata.Precision < 0 || *metadata.Precision > 36 {
+		return invalidMetadata("the precision must be between 0 and 36")
+	}
+
+	return nil
+}
+
+func validateCommodity(_ string, metadata Metadata) error {
+	return validateMIC(metadata.ExchangeMIC, false)
+}
+
+func validateRealEstate(_ string, _ Metadata) error {
+	return nil
+}
+
+func validateMIC(mic string, required bool) error {
+	if mic == "" && !required {
+		return nil
+	}
+
+	if !micRegex.MatchString(mic) {
+		return invalidMetadata("the exchange_mic must be the ISO 10383 code of four characters")
+	}
+
+	return nil
+}
+
+// validISIN checks the format and the check digit of an ISIN.
+// The letters are expanded to numbers (A=10 ... Z=35) and the Luhn algorithm is applied.
+func validISIN(isin string) bool {
+	if !isinRegex.MatchString(isin) {
+		return false
+	}
+
+	var digits strings.Builder
+	for _, r := range isin {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(fmt.Sprint(r - 'A' + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	return luhn(digits.String())
+}
+
+// validCUSIP checks the format and the check digit of a CUSIP.
+func validCUSIP(cusip string) bool {
+	if !cusipRegex.MatchString(cusip) {
+		return false
+	}
+
+	sum := 0
+
+	for i, r := range cusip[:8] {
+		var value int
+
+		switch {
+		case r >= '0' && r <= '9':
+			value = int(r - '0')
+		case r >= 'A' && r <= 'Z':
+			value = int(r-'A') + 10
+		case r == '*':
+			value = 36
+		case r == '@':
+			value = 37
+		case r == '#':
+			value = 38
+		}
+
+		if i%2 == 1 {
+			value *= 2
+		}
+
+		sum += value/10 + value%10
+	}
+
+	return (10-sum%10)%10 == int(cusip[8]-'0')
+}
+
+// luhn checks the Luhn check digit of a string of digits.
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+func invalidMetadata(desc string) error {
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidMetadata)
+}
@@ -1,8 +1,13 @@
 package finasset
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
 	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
 	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
 )
@@ -11,8 +16,17 @@ var loggerRepo = logger.Setup("domain.finasset.repository")
 
 // Repository is the interface for the financial asset repository.
 type Repository interface {
-	Create(finAsset FinancialAsset) error
-	Get(finAsset FinancialAsset) ([]FinancialAsset, error)
+	Create(ctx context.Context, finAsset FinancialAsset) error
+	Get(ctx context.Context, finAsset FinancialAsset, pagination crosscuting.Pagination) (Page, error)
+	GetByID(ctx context.Context, id uint) (FinancialAsset, error)
+	Update(ctx context.Context, id uint, patch FinancialAssetPatch) error
+	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) error
+	UpsertPrices(ctx context.Context, prices []Price) error
+	GetPrices(ctx context.Context, assetID uint, query PriceQuery) ([]Price, error)
+	GetBySymbol(ctx context.Context, symbol string) (FinancialAsset, error)
+	GetLatestPrice(ctx context.Context, assetID uint, quoteCurrency string, asOf time.Time) (Price, bool, error)
+	WithTx(tx gorm.Gorm) Repository
 }
 
 // RepositoryImpl is the struct that contains the financial asset repository.
@@ -25,9 +39,14 @@ func NewCurrencyRepository(db gorm.Gorm) Repository {
 	return &RepositoryImpl{db: db}
 }
 
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
 // Create creates a new financial asset.
-func (r *RepositoryImpl) Create(finAsset FinancialAsset) error {
-	if err := r.db.Create(&finAsset); err != nil {
+func (r *RepositoryImpl) Create(ctx context.Context, finAsset FinancialAsset) error {
+	if err := r.db.Create(ctx, &finAsset); err != nil {
 		loggerRepo.WithError(err).Error("Error creating record in the database")
 
 		return err
@@ -36,9 +55,9 @@ func (r *RepositoryImpl) Create(finAsset FinancialAsset) error {
 	return nil
 }
 
-// Get returns all the financial assets given filters.
-// Filters as equal type, and like symbol and name.
-func (r *RepositoryImpl) Get(finAsset FinancialAsset) ([]FinancialAsset, error) {
+// Get returns a page of the financial assets given filters.
+// Filters as equal type, and like symbol and name. The page is sorted and starts after the cursor of the pagination.
+func (r *RepositoryImpl) Get(ctx context.Context, finAsset FinancialAsset, pagination crosscuting.Pagination) (Page, error) {
 	var finAssets []FinancialAsset
 
 	var queryConditions []string
@@ -59,13 +78,201 @@ func (r *RepositoryImpl) Get(finAsset FinancialAsset) ([]FinancialAsset, error)
 		args = append(args, "%"+finAsset.Name+"%")
 	}
 
+	total, err := r.db.WhereCount(ctx, &FinancialAsset{}, strings.Join(queryConditions, " AND "), args...)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error counting records from the database")
+
+		return Page{}, err
+	}
+
+	if keyset, keysetArgs := pagination.KeysetCondition(); keyset != "" {
+		queryConditions = append(queryConditions, keyset)
+		args = append(args, keysetArgs...)
+	}
+
 	query := strings.Join(queryConditions, " AND ")
 
-	if err := r.db.WhereFind(&finAssets, query, args...); err != nil {
+	// One more element is requested to know if there is a next page.
+	if err := r.db.Order(pagination.Order()).Limit(pagination.Limit+1).WhereFind(ctx, &finAssets, query, args...); err != nil {
 		loggerRepo.WithError(err).Error("Error getting records from the database")
 
+		return Page{}, err
+	}
+
+	page := Page{Items: finAssets, Total: total}
+
+	if len(finAssets) > pagination.Limit {
+		page.Items = finAssets[:pagination.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = pagination.NextCursor(last.ID, last.CreatedAt)
+	}
+
+	return page, nil
+}
+
+// GetByID returns the financial asset with the given id.
+func (r *RepositoryImpl) GetByID(ctx context.Context, id uint) (FinancialAsset, error) {
+	var finAsset FinancialAsset
+	if err := r.db.WhereFirst(ctx, &finAsset, "id = ?", id); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the financial asset by id")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return FinancialAsset{}, fmt.Errorf(crosscuting.WrapLabel, "Financial asset not found", ErrFinancialAssetNotFound, err.Error())
+		}
+
+		return FinancialAsset{}, err
+	}
+
+	return finAsset, nil
+}
+
+// Update updates the non nil fields of the patch in the financial asset with the given id.
+func (r *RepositoryImpl) Update(ctx context.Context, id uint, patch FinancialAssetPatch) error {
+	values := map[string]interface{}{}
+
+	if patch.Symbol != nil {
+		values["symbol"] = *patch.Symbol
+	}
+
+	if patch.Name != nil {
+		values["name"] = *patch.Name
+	}
+
+	if patch.Desc != nil {
+		values["description"] = *patch.Desc
+	}
+
+	if patch.Type != nil {
+		values["type"] = *patch.Type
+	}
+
+	if patch.Metadata != nil {
+		values["metadata"] = *patch.Metadata
+	}
+
+	rows, err := r.db.WhereUpdates(ctx, &FinancialAsset{}, values, "id = ?", id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error updating record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Financial asset not found", ErrFinancialAssetNotFound)
+	}
+
+	return nil
+}
+
+// Delete soft deletes the financial asset with the given id.
+func (r *RepositoryImpl) Delete(ctx context.Context, id uint) error {
+	rows, err := r.db.WhereDelete(ctx, &FinancialAsset{}, "id = ?", id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error deleting record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Financial asset not found", ErrFinancialAssetNotFound)
+	}
+
+	return nil
+}
+
+// Restore restores the soft deleted financial asset with the given id.
+func (r *RepositoryImpl) Restore(ctx context.Context, id uint) error {
+	rows, err := r.db.Unscoped().WhereUpdates(ctx, &FinancialAsset{}, map[string]interface{}{"deleted_at": nil},
+		"id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error restoring record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Deleted financial asset not found", ErrFinancialAssetNotFound)
+	}
+
+	return nil
+}
+
+// UpsertPrices creates the prices, overwriting the ones with the same asset, quote currency and timestamp.
+func (r *RepositoryImpl) UpsertPrices(ctx context.Context, prices []Price) error {
+	if err := r.db.Upsert(ctx, &prices,
+		[]string{"asset_id", "quote_currency", "timestamp"},
+		[]string{"open", "high", "low", "close", "volume", "source", "updated_at"},
+	); err != nil {
+		loggerRepo.WithError(err).Error("Error upserting the prices in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// GetPrices returns the prices of the asset sorted by timestamp.
+// Filters by the range of the query when given, and by equal quote currency.
+func (r *RepositoryImpl) GetPrices(ctx context.Context, assetID uint, query PriceQuery) ([]Price, error) {
+	var prices []Price
+
+	queryConditions := []string{"asset_id = ?"}
+	args := []interface{}{assetID}
+
+	if !query.From.IsZero() {
+		queryConditions = append(queryConditions, "timestamp >= ?")
+		args = append(args, query.From)
+	}
+
+	if !query.To.IsZero() {
+		queryConditions = append(queryConditions, "timestamp <= ?")
+		args = append(args, query.To)
+	}
+
+	if query.QuoteCurrency != "" {
+		queryConditions = append(queryConditions, "quote_currency = ?")
+		args = append(args, query.QuoteCurrency)
+	}
+
+	if err := r.db.Order("timestamp ASC").WhereFind(ctx, &prices, strings.Join(queryConditions, " AND "), args...); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the prices from the database")
+
 		return nil, err
 	}
 
-	return finAssets, nil
+	return prices, nil
+}
+
+// GetBySymbol returns the financial asset with the given symbol.
+func (r *RepositoryImpl) GetBySymbol(ctx context.Context, symbol string) (FinancialAsset, error) {
+	var finAsset FinancialAsset
+	if err := r.db.WhereFirst(ctx, &finAsset, "symbol = ?", symbol); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the financial asset by symbol")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return FinancialAsset{}, fmt.Errorf(crosscuting.WrapLabel, "Financial asset not found", ErrFinancialAssetNotFound, symbol)
+		}
+
+		return FinancialAsset{}, err
+	}
+
+	return finAsset, nil
+}
+
+// GetLatestPrice returns the newest price of the asset in the quote currency on or before the given time.
+// The bool result reports if there is a price.
+func (r *RepositoryImpl) GetLatestPrice(ctx context.Context, assetID uint, quoteCurrency string, asOf time.Time) (Price, bool, error) {
+	var prices []Price
+	if err := r.db.Order("timestamp DESC").Limit(1).WhereFind(ctx, &prices,
+		"asset_id = ? AND quote_currency = ? AND timestamp <= ?", assetID, quoteCurrency, asOf); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the latest price from the database")
+
+		return Price{}, false, err
+	}
+
+	if len(prices) == 0 {
+		return Price{}, false, nil
+	}
+
+	return prices[0], true, nil
 }
@@ -1,15 +1,36 @@
 package finasset
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
 	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
 )
 
-var loggerService = logger.Setup("domain.finasset.service")
+var (
+	loggerService = logger.Setup("domain.finasset.service")
+	// ErrFinancialAssetNotFound is returned when the financial asset does not exist.
+	ErrFinancialAssetNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "FINANCIAL_ASSET_NOT_FOUND", "financial asset not found error")
+	errEmptyPatch             = crosscuting.NewTypedError(crosscuting.ErrValidation, "EMPTY_UPDATE", "empty update error")
+	// ErrPriceNotFound is returned when the asset does not have a price in the quote currency.
+	ErrPriceNotFound     = crosscuting.NewTypedError(crosscuting.ErrNotFound, "PRICE_NOT_FOUND", "price not found error")
+	errInvalidPriceQuery = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_PRICE_QUERY", "invalid price query error")
+)
 
 // Service is the interface for the financial asset service.
 type Service interface {
-	Create(finAsset FinancialAsset) error
-	Get(finAsset FinancialAsset) ([]FinancialAsset, error)
+	Create(ctx context.Context, finAsset FinancialAsset) error
+	Get(ctx context.Context, finAsset FinancialAsset, pagination crosscuting.Pagination) (Page, error)
+	GetByID(ctx context.Context, id uint) (FinancialAsset, error)
+	Update(ctx context.Context, id uint, patch FinancialAssetPatch) (FinancialAsset, error)
+	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) (FinancialAsset, error)
+	UpsertPrices(ctx context.Context, assetID uint, prices []Price) error
+	GetPrices(ctx context.Context, assetID uint, query PriceQuery) ([]Price, error)
+	GetBySymbol(ctx context.Context, symbol string) (FinancialAsset, error)
+	GetLatestPrice(ctx context.Context, assetID uint, quoteCurrency string, asOf time.Time) (Price, error)
 }
 
 // ServiceImpl is the struct that contains the financial asset service.
@@ -23,8 +44,14 @@ func NewFinAssetService(repo Repository) Service {
 }
 
 // Create creates a new financial asset.
-func (s *ServiceImpl) Create(finAsset FinancialAsset) error {
-	if err := s.repo.Create(finAsset); err != nil {
+// The type and the metadata are validated against the registry.
+func (s *ServiceImpl) Create(ctx context.Context, finAsset FinancialAsset) error {
+	if err := Validate(finAsset); err != nil {
+		loggerService.WithError(err).Error("Error validating the financial asset")
+		return err
+	}
+
+	if err := s.repo.Create(ctx, finAsset); err != nil {
 		loggerService.WithError(err).Error("Error creating the financial asset")
 		return err
 	}
@@ -32,13 +59,177 @@ func (s *ServiceImpl) Create(finAsset FinancialAsset) error {
 	return nil
 }
 
-// Get returns all the financial assets given filters.
-func (s *ServiceImpl) Get(finAsset FinancialAsset) ([]FinancialAsset, error) {
-	finAssets, err := s.repo.Get(finAsset)
+// Get returns a page of the financial assets given filters.
+func (s *ServiceImpl) Get(ctx context.Context, finAsset FinancialAsset, pagination crosscuting.Pagination) (Page, error) {
+	if finAsset.Type != "" {
+		if _, err := LookupType(finAsset.Type); err != nil {
+			loggerService.WithError(err).Error("Error validating the type filter")
+			return Page{}, err
+		}
+	}
+
+	page, err := s.repo.Get(ctx, finAsset, pagination)
 	if err != nil {
 		loggerService.WithError(err).Error("Error getting the financial assets from the repo")
+		return Page{}, err
+	}
+
+	return page, nil
+}
+
+// GetByID returns the financial asset with the given id.
+func (s *ServiceImpl) GetByID(ctx context.Context, id uint) (FinancialAsset, error) {
+	finAsset, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the financial asset from the repo")
+		return FinancialAsset{}, err
+	}
+
+	return finAsset, nil
+}
+
+// Update updates the financial asset with the given fields and returns it updated.
+// The resulting type and metadata are validated against the registry when the patch changes them,
+// otherwise only the symbol is, so the assets without metadata can still be renamed.
+func (s *ServiceImpl) Update(ctx context.Context, id uint, patch FinancialAssetPatch) (FinancialAsset, error) {
+	if patch.IsEmpty() {
+		desc := "No fields to update"
+		loggerService.WithError(errEmptyPatch).Error(desc)
+		return FinancialAsset{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, errEmptyPatch)
+	}
+
+	current, err := s.GetByID(ctx, id)
+	if err != nil {
+		return FinancialAsset{}, err
+	}
+
+	updated := patch.Apply(current)
+
+	if patch.Type != nil || patch.Metadata != nil {
+		err = Validate(updated)
+	} else {
+		err = ValidateSymbol(updated.Type, updated.Symbol)
+	}
+
+	if err != nil {
+		loggerService.WithError(err).Error("Error validating the financial asset")
+		return FinancialAsset{}, err
+	}
+
+	if err := s.repo.Update(ctx, id, patch); err != nil {
+		loggerService.WithError(err).Error("Error updating the financial asset")
+		return FinancialAsset{}, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Delete soft deletes the financial asset with the given id.
+func (s *ServiceImpl) Delete(ctx context.Context, id uint) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		loggerService.WithError(err).Error("Error deleting the financial asset")
+		return err
+	}
+
+	return nil
+}
+
+// Restore restores the soft deleted financial asset with the given id and returns it.
+func (s *ServiceImpl) Restore(ctx context.Context, id uint) (FinancialAsset, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		loggerService.WithError(err).Error("Error restoring the financial asset")
+		return FinancialAsset{}, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// UpsertPrices validates and stores the prices of the asset. The timestamps are stored in UTC with the precision
+// of the database, and the prices of the batch with the same quote currency and timestamp are stored once, the last wins.
+func (s *ServiceImpl) UpsertPrices(ctx context.Context, assetID uint, prices []Price) error {
+	if len(prices) == 0 || len(prices) > MaxPricesBatch {
+		desc := fmt.Sprintf("The number of prices must be between 1 and %d", MaxPricesBatch)
+		loggerService.WithError(ErrInvalidPrice).Error(desc)
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidPrice)
+	}
+
+	if _, err := s.GetByID(ctx, assetID); err != nil {
+		return err
+	}
+
+	for i := range prices {
+		prices[i].Timestamp = prices[i].Timestamp.UTC().Truncate(time.Microsecond)
+
+		if err := prices[i].Validate(); err != nil {
+			loggerService.WithError(err).Error("Error validating the price")
+			return err
+		}
+
+		prices[i].AssetID = assetID
+	}
+
+	if err := s.repo.UpsertPrices(ctx, uniquePrices(prices)); err != nil {
+		loggerService.WithError(err).Error("Error upserting the prices")
+		return err
+	}
+
+	return nil
+}
+
+// GetPrices returns the price history of the asset downsampled to the interval of the query.
+func (s *ServiceImpl) GetPrices(ctx context.Context, assetID uint, query PriceQuery) ([]Price, error) {
+	if query.Interval == "" {
+		query.Interval = IntervalRaw
+	}
+
+	if !query.Interval.IsValid() {
+		desc := "Unknown interval"
+		loggerService.WithError(errInvalidPriceQuery).Error(desc)
+		return nil, fmt.Errorf(crosscuting.WrapLabel, desc, errInvalidPriceQuery, string(query.Interval))
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		desc := "The from date must be before the to date"
+		loggerService.WithError(errInvalidPriceQuery).Error(desc)
+		return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, errInvalidPriceQuery)
+	}
+
+	if _, err := s.GetByID(ctx, assetID); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.repo.GetPrices(ctx, assetID, query)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the prices from the repo")
 		return nil, err
 	}
 
-	return finAssets, nil
+	return downsample(prices, query.Interval), nil
+}
+
+// GetBySymbol returns the financial asset with the given symbol.
+func (s *ServiceImpl) GetBySymbol(ctx context.Context, symbol string) (FinancialAsset, error) {
+	finAsset, err := s.repo.GetBySymbol(ctx, symbol)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the financial asset by symbol from the repo")
+		return FinancialAsset{}, err
+	}
+
+	return finAsset, nil
+}
+
+// GetLatestPrice returns the newest price of the asset in the quote currency on or before the given time.
+func (s *ServiceImpl) GetLatestPrice(ctx context.Context, assetID uint, quoteCurrency string, asOf time.Time) (Price, error) {
+	price, found, err := s.repo.GetLatestPrice(ctx, assetID, quoteCurrency, asOf)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the latest price from the repo")
+		return Price{}, err
+	}
+
+	if !found {
+		desc := fmt.Sprintf("No price of the asset %d in %s on or before %s", assetID, quoteCurrency, asOf.Format(time.RFC3339))
+		return Price{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrPriceNotFound)
+	}
+
+	return price, nil
 }
@@ -0,0 +1,264 @@
+package finasset
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+func newTestService() Service {
+	return NewFinAssetService(NewCurrencyRepository(gorm.NewMemoryGorm()))
+}
+
+func currency(symbol, numericCode string) FinancialAsset {
+	minorUnits := 2
+
+	return FinancialAsset{
+		Symbol:   symbol,
+		Name:     symbol + " currency",
+		Type:     Currency,
+		Metadata: Metadata{NumericCode: numericCode, MinorUnits: &minorUnits},
+	}
+}
+
+func TestServiceCreate(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService()
+
+	if err := service.Create(ctx, currency("USD", "840")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		finAsset FinancialAsset
+		wantErr  error
+	}{
+		{name: "other symbol", finAsset: currency("EUR", "978")},
+		{name: "symbol taken", finAsset: currency("USD", "840"), wantErr: crosscuting.ErrConflict},
+		{name: "unknown type", finAsset: FinancialAsset{Symbol: "XYZ", Name: "xyz", Type: "collectible"}, wantErr: crosscuting.ErrValidation},
+		{name: "invalid metadata", finAsset: currency("COP", "17"), wantErr: crosscuting.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Create(ctx, tt.finAsset)
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Create() unexpected error = %v", err)
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceGetPages(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService()
+
+	for _, asset := range []FinancialAsset{currency("USD", "840"), currency("EUR", "978"), currency("GBP", "826"), currency("JPY", "392")} {
+		if err := service.Create(ctx, asset); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	var (
+		symbols []string
+		cursor  string
+	)
+
+	filter := FinancialAsset{Type: Currency}
+
+	for {
+		pagination, err := crosscuting.NewPagination(3, cursor, "-id", filter)
+		if err != nil {
+			t.Fatalf("NewPagination() error = %v", err)
+		}
+
+		page, err := service.Get(ctx, filter, pagination)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		if page.Total != 4 {
+			t.Errorf("Get() total = %d, want 4", page.Total)
+		}
+
+		for _, item := range page.Items {
+			symbols = append(symbols, item.Symbol)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		cursor = page.NextCursor
+	}
+
+	want := []string{"JPY", "GBP", "EUR", "USD"}
+	if len(symbols) != len(want) {
+		t.Fatalf("Get() pages = %v, want %v", symbols, want)
+	}
+
+	for i := range want {
+		if symbols[i] != want[i] {
+			t.Fatalf("Get() pages = %v, want %v", symbols, want)
+		}
+	}
+
+	page, err := service.Get(ctx, FinancialAsset{Symbol: "US"}, crosscuting.Pagination{Limit: 10})
+	if err != nil || len(page.Items) != 1 || page.Items[0].Symbol != "USD" {
+		t.Errorf("Get() by symbol = %+v, %v, want USD", page.Items, err)
+	}
+}
+
+func TestServiceUpdate(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService()
+
+	if err := service.Create(ctx, currency("USD", "840")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	name := "US dollar"
+
+	updated, err := service.Update(ctx, 1, FinancialAssetPatch{Name: &name})
+	if err != nil || updated.Name != name || updated.Metadata.NumericCode != "840" {
+		t.Fatalf("Update() = %+v, %v, want the name updated", updated, err)
+	}
+
+	invalid := Metadata{NumericCode: "84"}
+	if _, err := service.Update(ctx, 1, FinancialAssetPatch{Metadata: &invalid}); !errors.Is(err, crosscuting.ErrValidation) {
+		t.Errorf("Update() with invalid metadata error = %v, want %v", err, crosscuting.ErrValidation)
+	}
+
+	if _, err := service.Update(ctx, 1, FinancialAssetPatch{}); !errors.Is(err, crosscuting.ErrValidation) {
+		t.Errorf("Update() without fields error = %v, want %v", err, crosscuting.ErrValidation)
+	}
+
+	if _, err := service.Update(ctx, 2, FinancialAssetPatch{Name: &name}); !errors.Is(err, ErrFinancialAssetNotFound) {
+		t.Errorf("Update() of a missing asset error = %v, want %v", err, ErrFinancialAssetNotFound)
+	}
+}
+
+func TestServiceUpdateWithoutMetadata(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCurrencyRepository(gorm.NewMemoryGorm())
+	service := NewFinAssetService(repo)
+
+	// The assets created before the metadata existed have an empty one.
+	if err := repo.Create(ctx, FinancialAsset{Symbol: "USD", Name: "USD currency", Type: Currency}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	name := "US dollar"
+	if _, err := service.Update(ctx, 1, FinancialAssetPatch{Name: &name}); err != nil {
+		t.Errorf("Update() of the name error = %v", err)
+	}
+
+	symbol := "usd"
+	if _, err := service.Update(ctx, 1, FinancialAssetPatch{Symbol: &symbol}); !errors.Is(err, crosscuting.ErrValidation) {
+		t.Errorf("Update() with an invalid symbol error = %v, want %v", err, crosscuting.ErrValidation)
+	}
+
+	assetType := Stock
+	if _, err := service.Update(ctx, 1, FinancialAssetPatch{Type: &assetType}); !errors.Is(err, crosscuting.ErrValidation) {
+		t.Errorf("Update() of the type without its metadata error = %v, want %v", err, crosscuting.ErrValidation)
+	}
+}
+
+func TestServiceDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService()
+
+	if err := service.Create(ctx, currency("USD", "840")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := service.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := service.GetByID(ctx, 1); !errors.Is(err, ErrFinancialAssetNotFound) {
+		t.Errorf("GetByID() of a deleted asset error = %v, want %v", err, ErrFinancialAssetNotFound)
+	}
+
+	// The symbol is only unique among the active assets.
+	if err := service.Create(ctx, currency("USD", "840")); err != nil {
+		t.Fatalf("Create() with the symbol of a deleted asset error = %v", err)
+	}
+
+	if _, err := service.Restore(ctx, 1); !errors.Is(err, crosscuting.ErrConflict) {
+		t.Errorf("Restore() with the symbol taken error = %v, want %v", err, crosscuting.ErrConflict)
+	}
+
+	if err := service.Delete(ctx, 2); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	restored, err := service.Restore(ctx, 1)
+	if err != nil || restored.ID != 1 || restored.Symbol != "USD" {
+		t.Errorf("Restore() = %+v, %v, want the asset 1", restored, err)
+	}
+}
+
+func TestServicePrices(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService()
+
+	if err := service.Create(ctx, currency("EUR", "978")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	day := time.Date(2023, time.November, 1, 0, 0, 0, 0, time.UTC)
+	price := func(timestamp time.Time, closePrice int64) Price {
+		value := decimal.NewFromInt(closePrice)
+
+		return Price{Timestamp: timestamp, Open: value, High: value, Low: value, Close: value, Source: "manual", QuoteCurrency: "USD"}
+	}
+
+	if err := service.UpsertPrices(ctx, 1, []Price{price(day, 1), price(day.AddDate(0, 0, 1), 2)}); err != nil {
+		t.Fatalf("UpsertPrices() error = %v", err)
+	}
+
+	// The stored candle of the same timestamp is replaced.
+	if err := service.UpsertPrices(ctx, 1, []Price{price(day, 3)}); err != nil {
+		t.Fatalf("UpsertPrices() error = %v", err)
+	}
+
+	// The same instant in another zone is the same candle, and the last price of the batch wins.
+	bogota := time.FixedZone("COT", -5*60*60)
+	if err := service.UpsertPrices(ctx, 1, []Price{price(day.AddDate(0, 0, 1).In(bogota), 5), price(day.AddDate(0, 0, 1), 2)}); err != nil {
+		t.Fatalf("UpsertPrices() with repeated timestamps error = %v", err)
+	}
+
+	prices, err := service.GetPrices(ctx, 1, PriceQuery{QuoteCurrency: "USD"})
+	if err != nil || len(prices) != 2 || !prices[0].Close.Equal(decimal.NewFromInt(3)) || !prices[1].Close.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("GetPrices() = %+v, %v, want the replaced candles", prices, err)
+	}
+
+	if prices[1].Timestamp.Location() != time.UTC {
+		t.Errorf("GetPrices() timestamp = %v, want it in UTC", prices[1].Timestamp)
+	}
+
+	latest, err := service.GetLatestPrice(ctx, 1, "USD", day.Add(time.Hour))
+	if err != nil || !latest.Close.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("GetLatestPrice() = %+v, %v, want the candle of the first day", latest, err)
+	}
+
+	if _, err := service.GetLatestPrice(ctx, 1, "USD", day.Add(-time.Hour)); !errors.Is(err, ErrPriceNotFound) {
+		t.Errorf("GetLatestPrice() before the history error = %v, want %v", err, ErrPriceNotFound)
+	}
+
+	if err := service.UpsertPrices(ctx, 2, []Price{price(day, 1)}); !errors.Is(err, ErrFinancialAssetNotFound) {
+		t.Errorf("UpsertPrices() of a missing asset error = %v, want %v", err, ErrFinancialAssetNotFound)
+	}
+}
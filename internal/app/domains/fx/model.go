@@ -0,0 +1,27 @@
+package fx
+
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// Rate is the struct for the exchange rate between two currencies.
+	// Path are the currencies crossed to get the rate, from the source to the target.
+	Rate struct {
+		From string          `json:"from"`
+		To   string          `json:"to"`
+		Rate decimal.Decimal `json:"rate"`
+		Date time.Time       `json:"date"`
+		Path []string        `json:"path"`
+	}
+
+	// Conversion is the struct for an amount converted between two currencies.
+	Conversion struct {
+		Rate
+		Amount    money.Money `json:"amount"`
+		Converted money.Money `json:"converted"`
+	}
+)
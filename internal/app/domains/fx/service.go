@@ -0,0 +1,152 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+// rateDecimals is the precision of the rates computed by inverting or crossing prices.
+const rateDecimals = 16
+
+var (
+	loggerService = logger.Setup("domain.fx.service")
+	// ErrNotCurrency is returned when the asset to convert is not a currency.
+	ErrNotCurrency = crosscuting.NewTypedError(crosscuting.ErrValidation, "NOT_A_CURRENCY", "asset is not a currency error")
+	// ErrRateNotFound is returned when there is no direct, inverse or triangulated rate between the currencies.
+	ErrRateNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "RATE_NOT_FOUND", "exchange rate not found error")
+)
+
+// Service is the interface for the currency conversion service.
+type Service interface {
+	Rate(ctx context.Context, from, to string, date time.Time) (Rate, error)
+	Convert(ctx context.Context, amount money.Money, to string, date time.Time) (Conversion, error)
+}
+
+// ServiceImpl is the struct that contains the currency conversion service.
+type ServiceImpl struct {
+	finAssetService finasset.Service
+	pivot           string
+}
+
+// NewFXService creates a new currency conversion service that triangulates through the pivot currency.
+func NewFXService(finAssetService finasset.Service, pivot string) Service {
+	return &ServiceImpl{finAssetService: finAssetService, pivot: pivot}
+}
+
+// Rate returns the exchange rate between the currencies on the given date.
+// It uses the direct rate, the inverse rate or the cross rate through the pivot currency, in that order.
+func (s *ServiceImpl) Rate(ctx context.Context, from, to string, date time.Time) (Rate, error) {
+	fromAsset, err := s.currency(ctx, from)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	toAsset, err := s.currency(ctx, to)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	if from == to {
+		return Rate{From: from, To: to, Rate: decimal.NewFromInt(1), Date: date, Path: []string{from}}, nil
+	}
+
+	rate, err := s.pairRate(ctx, fromAsset, toAsset, date)
+	if err == nil {
+		return Rate{From: from, To: to, Rate: rate, Date: date, Path: []string{from, to}}, nil
+	}
+
+	if !errors.Is(err, ErrRateNotFound) || from == s.pivot || to == s.pivot {
+		return Rate{}, err
+	}
+
+	pivotAsset, err := s.currency(ctx, s.pivot)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	toPivot, err := s.pairRate(ctx, fromAsset, pivotAsset, date)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	fromPivot, err := s.pairRate(ctx, pivotAsset, toAsset, date)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{
+		From: from,
+		To:   to,
+		Rate: toPivot.Mul(fromPivot).Round(rateDecimals),
+		Date: date,
+		Path: []string{from, s.pivot, to},
+	}, nil
+}
+
+// Convert converts the amount to the currency with the rate of the given date.
+// The result is rounded to the minor units of the target currency.
+func (s *ServiceImpl) Convert(ctx context.Context, amount money.Money, to string, date time.Time) (Conversion, error) {
+	rate, err := s.Rate(ctx, amount.Currency, to, date)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the exchange rate")
+		return Conversion{}, err
+	}
+
+	toAsset, err := s.currency(ctx, to)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	converted := amount.Convert(rate.Rate, to).Round(toAsset.MinorUnits())
+
+	return Conversion{Rate: rate, Amount: amount, Converted: converted}, nil
+}
+
+// currency returns the currency asset with the given symbol.
+func (s *ServiceImpl) currency(ctx context.Context, symbol string) (finasset.FinancialAsset, error) {
+	asset, err := s.finAssetService.GetBySymbol(ctx, symbol)
+	if err != nil {
+		return finasset.FinancialAsset{}, err
+	}
+
+	if asset.Type != finasset.Currency {
+		desc := fmt.Sprintf("The asset %s is a %s", symbol, asset.Type)
+		loggerService.WithError(ErrNotCurrency).Error(desc)
+		return finasset.FinancialAsset{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrNotCurrency)
+	}
+
+	return asset, nil
+}
+
+// pairRate returns the rate between two currencies from the direct price or the inverse of the opposite price.
+func (s *ServiceImpl) pairRate(ctx context.Context, from, to finasset.FinancialAsset, date time.Time) (decimal.Decimal, error) {
+	direct, err := s.finAssetService.GetLatestPrice(ctx, from.ID, to.Symbol, date)
+	if err == nil {
+		return direct.Close, nil
+	}
+
+	if !errors.Is(err, finasset.ErrPriceNotFound) {
+		return decimal.Decimal{}, err
+	}
+
+	inverse, err := s.finAssetService.GetLatestPrice(ctx, to.ID, from.Symbol, date)
+	if err == nil && inverse.Close.IsPositive() {
+		return decimal.NewFromInt(1).DivRound(inverse.Close, rateDecimals), nil
+	}
+
+	if err != nil && !errors.Is(err, finasset.ErrPriceNotFound) {
+		return decimal.Decimal{}, err
+	}
+
+	desc := fmt.Sprintf("No rate between %s and %s on or before %s", from.Symbol, to.Symbol, date.Format(time.RFC3339))
+
+	return decimal.Decimal{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrRateNotFound)
+}
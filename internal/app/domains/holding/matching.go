@@ -0,0 +1,136 @@
+package holding
+
+import (
+	"sort"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/shopspring/decimal"
+)
+
+// match consumes the quantity from the open lots with the cost basis method and returns the lots updated
+// and the sales of each lot. The proceeds are split between the sales in proportion to their quantity,
+// and the amounts are rounded to the minor units. The lots must have enough remaining quantity.
+func match(lots []Lot, method account.CostBasisMethod, quantity decimal.Decimal, proceeds money.Money, minorUnits int32) ([]Lot, []LotSale) {
+	lots = append([]Lot(nil), lots...)
+
+	var consumed []decimal.Decimal
+
+	if method == account.AverageCost {
+		consumed = proportional(lots, quantity)
+	} else {
+		sortLots(lots, method)
+		consumed = sequential(lots, quantity)
+	}
+
+	var (
+		updated          []Lot
+		sales            []LotSale
+		proceedsLeft     = proceeds.Amount
+		quantityAssigned = decimal.Zero
+	)
+
+	for i, lot := range lots {
+		q := consumed[i]
+		if q.IsZero() {
+			continue
+		}
+
+		cost := lot.RemainingCost.Amount
+		if !q.Equal(lot.Remaining) {
+			cost = lot.RemainingCost.Amount.Mul(q).Div(lot.Remaining).Round(minorUnits)
+		}
+
+		// The last sale takes the rest of the proceeds, so they add up exactly.
+		quantityAssigned = quantityAssigned.Add(q)
+		share := proceedsLeft
+		if !quantityAssigned.Equal(quantity) {
+			share = proceeds.Amount.Mul(q).Div(quantity).Round(minorUnits)
+		}
+
+		proceedsLeft = proceedsLeft.Sub(share)
+
+		lot.Remaining = lot.Remaining.Sub(q)
+		lot.RemainingCost.Amount = lot.RemainingCost.Amount.Sub(cost)
+		updated = append(updated, lot)
+
+		sales = append(sales, LotSale{
+			LotID:     lot.ID,
+			Quantity:  q,
+			CostBasis: money.New(cost, proceeds.Currency),
+			Proceeds:  money.New(share, proceeds.Currency),
+			Gain:      money.New(share.Sub(cost), proceeds.Currency),
+		})
+	}
+
+	return updated, sales
+}
+
+// sortLots sorts the lots in the order they are sold by the method.
+func sortLots(lots []Lot, method account.CostBasisMethod) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, b := lots[i], lots[j]
+
+		switch method {
+		case account.LIFO:
+			if !a.Date.Equal(b.Date) {
+				return a.Date.After(b.Date)
+			}
+
+			return a.ID > b.ID
+		case account.HighestCost:
+			if cmp := a.unitCost().Cmp(b.unitCost()); cmp != 0 {
+				return cmp > 0
+			}
+		}
+
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+
+		return a.ID < b.ID
+	})
+}
+
+// sequential consumes the quantity from the lots in order, exhausting each one before the next.
+func sequential(lots []Lot, quantity decimal.Decimal) []decimal.Decimal {
+	consumed := make([]decimal.Decimal, len(lots))
+	left := quantity
+
+	for i, lot := range lots {
+		q := decimal.Min(left, lot.Remaining)
+		consumed[i] = q
+		left = left.Sub(q)
+	}
+
+	return consumed
+}
+
+// proportional consumes the quantity from all the lots in proportion to their remaining quantity,
+// so the cost basis is the average unit cost. The last lot takes the rest, so they add up exactly.
+func proportional(lots []Lot, quantity decimal.Decimal) []decimal.Decimal {
+	consumed := make([]decimal.Decimal, len(lots))
+
+	total := decimal.Zero
+	for _, lot := range lots {
+		total = total.Add(lot.Remaining)
+	}
+
+	if total.IsZero() {
+		return consumed
+	}
+
+	left := quantity
+
+	for i, lot := range lots {
+		q := left
+		if i < len(lots)-1 {
+			q = decimal.Min(quantity.Mul(lot.Remaining).Div(total), lot.Remaining)
+		}
+
+		consumed[i] = q
+		left = left.Sub(q)
+	}
+
+	return consumed
+}
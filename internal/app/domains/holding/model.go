@@ -0,0 +1,116 @@
+package holding
+
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// Buy is the side of the trades that open a tax lot.
+	Buy Side = "buy"
+	// Sell is the side of the trades that consume tax lots.
+	Sell Side = "sell"
+)
+
+type (
+	// Side can be buy or sell.
+	Side string
+
+	// Trade is the struct for a buy or a sell of a financial asset in a brokerage account of a user.
+	// The price and the fee are in the currency of the account.
+	Trade struct {
+		gorm.Model
+		UserID    uint            `json:"user_id" gorm:"not null"`
+		AccountID uint            `json:"account_id" gorm:"not null"`
+		AssetID   uint            `json:"asset_id" gorm:"not null"`
+		Side      Side            `json:"side" gorm:"not null"`
+		Date      time.Time       `json:"date" gorm:"not null"`
+		Quantity  decimal.Decimal `json:"quantity" gorm:"type:numeric;not null"`
+		Price     money.Money     `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+		Fee       money.Money     `json:"fee" gorm:"embedded;embeddedPrefix:fee_"`
+		Sales     []LotSale       `json:"sales,omitempty" gorm:"-"`
+	}
+
+	// Lot is the struct for a tax lot opened by a buy trade.
+	// The cost includes the fee of the buy, the remaining quantity and cost decrease as the lot is sold.
+	Lot struct {
+		gorm.Model
+		UserID        uint            `json:"user_id" gorm:"not null"`
+		AccountID     uint            `json:"account_id" gorm:"not null"`
+		AssetID       uint            `json:"asset_id" gorm:"not null"`
+		TradeID       uint            `json:"trade_id" gorm:"not null"`
+		Date          time.Time       `json:"date" gorm:"not null"`
+		Quantity      decimal.Decimal `json:"quantity" gorm:"type:numeric;not null"`
+		Cost          money.Money     `json:"cost" gorm:"embedded;embeddedPrefix:cost_"`
+		Remaining     decimal.Decimal `json:"remaining" gorm:"type:numeric;not null"`
+		RemainingCost money.Money     `json:"remaining_cost" gorm:"embedded;embeddedPrefix:remaining_cost_"`
+	}
+
+	// LotSale is the struct for the part of a tax lot consumed by a sell trade.
+	// The proceeds are net of the fee of the sell.
+	LotSale struct {
+		gorm.Model
+		TradeID   uint            `json:"trade_id" gorm:"not null"`
+		LotID     uint            `json:"lot_id" gorm:"not null"`
+		Quantity  decimal.Decimal `json:"quantity" gorm:"type:numeric;not null"`
+		CostBasis money.Money     `json:"cost_basis" gorm:"embedded;embeddedPrefix:cost_basis_"`
+		Proceeds  money.Money     `json:"proceeds" gorm:"embedded;embeddedPrefix:proceeds_"`
+		Gain      money.Money     `json:"gain" gorm:"embedded;embeddedPrefix:gain_"`
+	}
+
+	// Holding is the struct for the open position of a brokerage account in a financial asset.
+	Holding struct {
+		AccountID    uint            `json:"account_id"`
+		AssetID      uint            `json:"asset_id"`
+		Symbol       string          `json:"symbol"`
+		Quantity     decimal.Decimal `json:"quantity"`
+		CostBasis    money.Money     `json:"cost_basis"`
+		AveragePrice money.Money     `json:"average_price"`
+	}
+
+	// Realized is the struct for the gain of a tax lot sale, with the dates the lot was bought and sold.
+	Realized struct {
+		LotSale
+		AccountID  uint      `json:"account_id"`
+		AssetID    uint      `json:"asset_id"`
+		AcquiredAt time.Time `json:"acquired_at"`
+		SoldAt     time.Time `json:"sold_at"`
+	}
+
+	// TradeFilter is the struct for the filters of the trades and the lots of a user, zero fields do not filter.
+	// The dates filter the date of the trades and the date the lots were opened, both inclusive.
+	TradeFilter struct {
+		AccountID uint
+		AssetID   uint
+		Side      Side
+		From      time.Time
+		To        time.Time
+	}
+)
+
+// TableName overrides the table name of the tax lots.
+func (Lot) TableName() string {
+	return "tax_lots"
+}
+
+// TableName overrides the table name of the lot sales.
+func (LotSale) TableName() string {
+	return "tax_lot_sales"
+}
+
+// IsValid reports if the side is supported.
+func (s Side) IsValid() bool {
+	return s == Buy || s == Sell
+}
+
+// unitCost returns the remaining cost per unit of the lot.
+func (l Lot) unitCost() decimal.Decimal {
+	if l.Remaining.IsZero() {
+		return decimal.Zero
+	}
+
+	return l.RemainingCost.Amount.Div(l.Remaining)
+}
@@ -0,0 +1,225 @@
+package holding
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.holding.repository")
+
+// Repository is the interface for the holding repository.
+// All the operations are scoped to the trades and lots of the given user.
+type Repository interface {
+	CreateBuy(ctx context.Context, trade Trade, lot Lot) (Trade, error)
+	CreateSell(ctx context.Context, trade Trade, lots []Lot, sales []LotSale) (Trade, error)
+	GetTrades(ctx context.Context, userID uint, filter TradeFilter) ([]Trade, error)
+	GetLots(ctx context.Context, userID uint, filter TradeFilter) ([]Lot, error)
+	GetLotsByIDs(ctx context.Context, userID uint, ids []uint) ([]Lot, error)
+	GetOpenLots(ctx context.Context, userID uint, filter TradeFilter) ([]Lot, error)
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the holding repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewHoldingRepository creates a new holding repository.
+func NewHoldingRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// CreateBuy creates the buy trade and the lot it opens atomically, and returns the trade with its id.
+func (r *RepositoryImpl) CreateBuy(ctx context.Context, trade Trade, lot Lot) (Trade, error) {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		if err := tx.Create(ctx, &trade); err != nil {
+			return err
+		}
+
+		lot.TradeID = trade.ID
+
+		return tx.Create(ctx, &lot)
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error creating the buy trade in the database")
+
+		return Trade{}, err
+	}
+
+	return trade, nil
+}
+
+// CreateSell creates the sell trade, updates the remaining of the lots and creates the sales atomically,
+// and returns the trade with its sales. The sales are of the lots in the same order, and each lot is only
+// updated if it still has the remaining it had before its sale, so two sells can not consume the same quantity.
+func (r *RepositoryImpl) CreateSell(ctx context.Context, trade Trade, lots []Lot, sales []LotSale) (Trade, error) {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		if err := tx.Create(ctx, &trade); err != nil {
+			return err
+		}
+
+		for i, lot := range lots {
+			values := map[string]interface{}{
+				"remaining":             lot.Remaining,
+				"remaining_cost_amount": lot.RemainingCost.Amount,
+			}
+
+			previous := lot.Remaining.Add(sales[i].Quantity)
+
+			rows, err := tx.WhereUpdates(ctx, &Lot{}, values, "id = ? AND remaining = ?", lot.ID, previous)
+			if err != nil {
+				return err
+			}
+
+			if rows != 1 {
+				return fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("The lot %d was changed by another trade", lot.ID), ErrLotChanged)
+			}
+		}
+
+		for i := range sales {
+			sales[i].TradeID = trade.ID
+		}
+
+		return tx.Create(ctx, &sales)
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error creating the sell trade in the database")
+
+		return Trade{}, err
+	}
+
+	trade.Sales = sales
+
+	return trade, nil
+}
+
+// GetTrades returns the trades of the user given filters with the sales of the sell trades, sorted by date and id.
+func (r *RepositoryImpl) GetTrades(ctx context.Context, userID uint, filter TradeFilter) ([]Trade, error) {
+	var trades []Trade
+
+	queryConditions, args := filterConditions(userID, filter)
+
+	if filter.Side != "" {
+		queryConditions = append(queryConditions, "side = ?")
+		args = append(args, filter.Side)
+	}
+
+	if err := r.db.Order("date ASC, id ASC").WhereFind(ctx, &trades, strings.Join(queryConditions, " AND "), args...); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the trades from the database")
+
+		return nil, err
+	}
+
+	var sellIDs []uint
+	index := map[uint]int{}
+
+	for i, trade := range trades {
+		if trade.Side == Sell {
+			sellIDs = append(sellIDs, trade.ID)
+			index[trade.ID] = i
+		}
+	}
+
+	if len(sellIDs) == 0 {
+		return trades, nil
+	}
+
+	var sales []LotSale
+	if err := r.db.Order("id ASC").WhereFind(ctx, &sales, "trade_id IN ?", sellIDs); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the lot sales from the database")
+
+		return nil, err
+	}
+
+	for _, sale := range sales {
+		i := index[sale.TradeID]
+		trades[i].Sales = append(trades[i].Sales, sale)
+	}
+
+	return trades, nil
+}
+
+// GetLots returns the lots of the user given filters, including the ones already sold, sorted by date and id.
+func (r *RepositoryImpl) GetLots(ctx context.Context, userID uint, filter TradeFilter) ([]Lot, error) {
+	var lots []Lot
+
+	queryConditions, args := filterConditions(userID, filter)
+
+	if err := r.db.Order("date ASC, id ASC").WhereFind(ctx, &lots, strings.Join(queryConditions, " AND "), args...); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the lots from the database")
+
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// GetLotsByIDs returns the lots of the user with the given ids.
+func (r *RepositoryImpl) GetLotsByIDs(ctx context.Context, userID uint, ids []uint) ([]Lot, error) {
+	var lots []Lot
+
+	if len(ids) == 0 {
+		return lots, nil
+	}
+
+	if err := r.db.WhereFind(ctx, &lots, "user_id = ? AND id IN ?", userID, ids); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the lots by ids from the database")
+
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// GetOpenLots returns the lots of the user given filters with remaining quantity, sorted by date and id.
+func (r *RepositoryImpl) GetOpenLots(ctx context.Context, userID uint, filter TradeFilter) ([]Lot, error) {
+	var lots []Lot
+
+	queryConditions, args := filterConditions(userID, filter)
+	queryConditions = append(queryConditions, "remaining > 0")
+
+	if err := r.db.Order("date ASC, id ASC").WhereFind(ctx, &lots, strings.Join(queryConditions, " AND "), args...); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the open lots from the database")
+
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+func filterConditions(userID uint, filter TradeFilter) ([]string, []interface{}) {
+	queryConditions := []string{"user_id = ?"}
+	args := []interface{}{userID}
+
+	if filter.AccountID != 0 {
+		queryConditions = append(queryConditions, "account_id = ?")
+		args = append(args, filter.AccountID)
+	}
+
+	if filter.AssetID != 0 {
+		queryConditions = append(queryConditions, "asset_id = ?")
+		args = append(args, filter.AssetID)
+	}
+
+	if !filter.From.IsZero() {
+		queryConditions = append(queryConditions, "date >= ?")
+		args = append(args, filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		queryConditions = append(queryConditions, "date <= ?")
+		args = append(args, filter.To)
+	}
+
+	return queryConditions, args
+}
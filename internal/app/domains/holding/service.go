@@ -0,0 +1,418 @@
+package holding
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	loggerService = logger.Setup("domain.holding.service")
+	// ErrInvalidTrade is returned when the fields of the trade are not valid.
+	ErrInvalidTrade = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_TRADE", "invalid trade error")
+	// ErrInsufficientQuantity is returned when a sell exceeds the open quantity of the asset in the account.
+	ErrInsufficientQuantity = crosscuting.NewTypedError(crosscuting.ErrConflict, "INSUFFICIENT_QUANTITY", "insufficient quantity error")
+	// ErrLotChanged is returned when a lot consumed by a sell is changed by another trade at the same time.
+	ErrLotChanged = crosscuting.NewTypedError(crosscuting.ErrConflict, "LOT_CHANGED", "lot changed error")
+)
+
+// Service is the interface for the holding service.
+type Service interface {
+	CreateTrade(ctx context.Context, trade Trade) (Trade, error)
+	GetTrades(ctx context.Context, userID uint, filter TradeFilter) ([]Trade, error)
+	GetLots(ctx context.Context, userID uint, filter TradeFilter) ([]Lot, error)
+	GetHoldings(ctx context.Context, userID uint, filter TradeFilter) ([]Holding, error)
+	GetLotsAt(ctx context.Context, userID uint, asOf time.Time) ([]Lot, error)
+	GetRealized(ctx context.Context, userID uint, from, to time.Time) ([]Realized, error)
+}
+
+// ServiceImpl is the struct that contains the holding service.
+type ServiceImpl struct {
+	db              gorm.Gorm
+	repo            Repository
+	accountService  account.Service
+	finAssetService finasset.Service
+}
+
+// NewHoldingService creates a new holding service.
+func NewHoldingService(db gorm.Gorm, repo Repository, accountService account.Service, finAssetService finasset.Service) Service {
+	return &ServiceImpl{db: db, repo: repo, accountService: accountService, finAssetService: finAssetService}
+}
+
+// CreateTrade records a buy or a sell of a stock or a crypto currency in an open brokerage account of the user.
+// The price and the fee are in the currency of the account. A buy opens a tax lot that costs the quantity
+// times the price plus the fee, and a sell consumes the open lots with the cost basis method of the account.
+func (s *ServiceImpl) CreateTrade(ctx context.Context, trade Trade) (Trade, error) {
+	if !trade.Side.IsValid() {
+		return Trade{}, invalidTrade(fmt.Sprintf("Unknown trade side %s", trade.Side))
+	}
+
+	if trade.Date.IsZero() {
+		return Trade{}, invalidTrade("The date is required")
+	}
+
+	if !trade.Quantity.IsPositive() {
+		return Trade{}, invalidTrade("The quantity must be positive")
+	}
+
+	if trade.Price.IsNegative() {
+		return Trade{}, invalidTrade("The price can not be negative")
+	}
+
+	if trade.Fee.IsNegative() {
+		return Trade{}, invalidTrade("The fee can not be negative")
+	}
+
+	acc, err := s.accountService.GetByID(ctx, trade.UserID, trade.AccountID)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	if acc.Type != account.Brokerage {
+		return Trade{}, invalidTrade("The trades must be in a brokerage account")
+	}
+
+	if acc.Status != account.Open {
+		return Trade{}, invalidTrade("The account is closed")
+	}
+
+	asset, err := s.finAssetService.GetByID(ctx, trade.AssetID)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	if asset.Type != finasset.Stock && asset.Type != finasset.Crypto {
+		return Trade{}, invalidTrade("The asset of a trade must be a stock or a crypto currency")
+	}
+
+	currency, err := s.finAssetService.GetByID(ctx, acc.AssetID)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	trade.Price = money.New(trade.Price.Amount, currency.Symbol)
+	trade.Fee = money.New(trade.Fee.Amount, currency.Symbol).Round(currency.MinorUnits())
+
+	if trade.Side == Buy {
+		return s.buy(ctx, trade, currency.MinorUnits())
+	}
+
+	return s.sell(ctx, trade, acc.CostBasisMethod, currency.MinorUnits())
+}
+
+// GetTrades returns the trades of the user given filters.
+func (s *ServiceImpl) GetTrades(ctx context.Context, userID uint, filter TradeFilter) ([]Trade, error) {
+	trades, err := s.repo.GetTrades(ctx, userID, filter)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the trades from the repo")
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+// GetLots returns the open tax lots of the user given filters.
+func (s *ServiceImpl) GetLots(ctx context.Context, userID uint, filter TradeFilter) ([]Lot, error) {
+	lots, err := s.repo.GetOpenLots(ctx, userID, filter)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the lots from the repo")
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// GetHoldings returns the open positions of the user given filters, one per account and asset,
+// with the remaining quantity and cost basis of their lots and the average price they were bought at.
+func (s *ServiceImpl) GetHoldings(ctx context.Context, userID uint, filter TradeFilter) ([]Holding, error) {
+	lots, err := s.GetLots(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ accountID, assetID uint }
+
+	var (
+		keys     []key
+		holdings = map[key]*Holding{}
+	)
+
+	for _, lot := range lots {
+		k := key{lot.AccountID, lot.AssetID}
+
+		holding, ok := holdings[k]
+		if !ok {
+			holding = &Holding{
+				AccountID: lot.AccountID,
+				AssetID:   lot.AssetID,
+				Quantity:  decimal.Zero,
+				CostBasis: money.Zero(lot.RemainingCost.Currency),
+			}
+			holdings[k] = holding
+			keys = append(keys, k)
+		}
+
+		holding.Quantity = holding.Quantity.Add(lot.Remaining)
+		holding.CostBasis.Amount = holding.CostBasis.Amount.Add(lot.RemainingCost.Amount)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].accountID != keys[j].accountID {
+			return keys[i].accountID < keys[j].accountID
+		}
+
+		return keys[i].assetID < keys[j].assetID
+	})
+
+	assets := map[uint]finasset.FinancialAsset{}
+
+	result := make([]Holding, 0, len(keys))
+
+	for _, k := range keys {
+		holding := holdings[k]
+
+		asset, err := s.asset(ctx, assets, holding.AssetID)
+		if err != nil {
+			return nil, err
+		}
+
+		currency, err := s.finAssetService.GetBySymbol(ctx, holding.CostBasis.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		holding.Symbol = asset.Symbol
+		holding.AveragePrice = money.New(holding.CostBasis.Amount.Div(holding.Quantity), holding.CostBasis.Currency).
+			Round(currency.MinorUnits())
+		holding.CostBasis = holding.CostBasis.Round(currency.MinorUnits())
+
+		result = append(result, *holding)
+	}
+
+	return result, nil
+}
+
+// GetLotsAt returns the lots of the user that were open at the date, with the remaining quantity and cost
+// they had then. The sales of the trades after the date are added back to their lots.
+func (s *ServiceImpl) GetLotsAt(ctx context.Context, userID uint, asOf time.Time) ([]Lot, error) {
+	lots, err := s.repo.GetLots(ctx, userID, TradeFilter{To: asOf})
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the lots from the repo")
+		return nil, err
+	}
+
+	later, err := s.repo.GetTrades(ctx, userID, TradeFilter{Side: Sell, From: asOf.Add(time.Nanosecond)})
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the later trades from the repo")
+		return nil, err
+	}
+
+	index := make(map[uint]int, len(lots))
+	for i, lot := range lots {
+		index[lot.ID] = i
+	}
+
+	for _, trade := range later {
+		for _, sale := range trade.Sales {
+			i, ok := index[sale.LotID]
+			if !ok {
+				continue
+			}
+
+			lots[i].Remaining = lots[i].Remaining.Add(sale.Quantity)
+			lots[i].RemainingCost.Amount = lots[i].RemainingCost.Amount.Add(sale.CostBasis.Amount)
+		}
+	}
+
+	open := make([]Lot, 0, len(lots))
+	for _, lot := range lots {
+		if lot.Remaining.IsPositive() {
+			open = append(open, lot)
+		}
+	}
+
+	return open, nil
+}
+
+// GetRealized returns the gains of the lot sales of the user between the dates, both inclusive,
+// with the dates their lots were bought.
+func (s *ServiceImpl) GetRealized(ctx context.Context, userID uint, from, to time.Time) ([]Realized, error) {
+	trades, err := s.repo.GetTrades(ctx, userID, TradeFilter{Side: Sell, From: from, To: to})
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the sell trades from the repo")
+		return nil, err
+	}
+
+	var lotIDs []uint
+	for _, trade := range trades {
+		for _, sale := range trade.Sales {
+			lotIDs = append(lotIDs, sale.LotID)
+		}
+	}
+
+	lots, err := s.repo.GetLotsByIDs(ctx, userID, lotIDs)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the sold lots from the repo")
+		return nil, err
+	}
+
+	byID := make(map[uint]Lot, len(lots))
+	for _, lot := range lots {
+		byID[lot.ID] = lot
+	}
+
+	realized := []Realized{}
+
+	for _, trade := range trades {
+		for _, sale := range trade.Sales {
+			realized = append(realized, Realized{
+				LotSale:    sale,
+				AccountID:  trade.AccountID,
+				AssetID:    trade.AssetID,
+				AcquiredAt: byID[sale.LotID].Date,
+				SoldAt:     trade.Date,
+			})
+		}
+	}
+
+	return realized, nil
+}
+
+// buy creates the trade with the lot it opens.
+// A buy dated up to a sell of the asset in the account is rejected, since its lot could have been consumed
+// by that sell and the sells after it would need their lots matched again.
+func (s *ServiceImpl) buy(ctx context.Context, trade Trade, minorUnits int32) (Trade, error) {
+	cost := trade.Price.Mul(trade.Quantity).Round(minorUnits)
+	cost.Amount = cost.Amount.Add(trade.Fee.Amount)
+
+	lot := Lot{
+		UserID:        trade.UserID,
+		AccountID:     trade.AccountID,
+		AssetID:       trade.AssetID,
+		Date:          trade.Date,
+		Quantity:      trade.Quantity,
+		Cost:          cost,
+		Remaining:     trade.Quantity,
+		RemainingCost: cost,
+	}
+
+	var created Trade
+
+	err := s.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		repo := s.repo.WithTx(tx)
+
+		if err := checkLaterSells(ctx, repo, trade, trade.Date); err != nil {
+			return err
+		}
+
+		var err error
+		if created, err = repo.CreateBuy(ctx, trade, lot); err != nil {
+			loggerService.WithError(err).Error("Error creating the buy trade")
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Trade{}, err
+	}
+
+	return created, nil
+}
+
+// sell creates the trade with the sales of the lots it consumes.
+// Only the lots opened up to the date of the sell can be consumed. The lots are read, matched and updated
+// in the same transaction, and a sell dated before a later sell of the asset in the account is rejected,
+// since the later sells would need their lots matched again.
+func (s *ServiceImpl) sell(ctx context.Context, trade Trade, method account.CostBasisMethod, minorUnits int32) (Trade, error) {
+	var created Trade
+
+	err := s.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		repo := s.repo.WithTx(tx)
+
+		if err := checkLaterSells(ctx, repo, trade, trade.Date.Add(time.Nanosecond)); err != nil {
+			return err
+		}
+
+		lots, err := repo.GetOpenLots(ctx, trade.UserID, TradeFilter{AccountID: trade.AccountID, AssetID: trade.AssetID, To: trade.Date})
+		if err != nil {
+			loggerService.WithError(err).Error("Error getting the open lots from the repo")
+			return err
+		}
+
+		open := decimal.Zero
+		for _, lot := range lots {
+			open = open.Add(lot.Remaining)
+		}
+
+		if trade.Quantity.GreaterThan(open) {
+			desc := fmt.Sprintf("The quantity %s exceeds the open quantity %s", trade.Quantity, open)
+			loggerService.WithError(ErrInsufficientQuantity).Error(desc)
+
+			return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInsufficientQuantity)
+		}
+
+		proceeds := trade.Price.Mul(trade.Quantity).Round(minorUnits)
+		proceeds.Amount = proceeds.Amount.Sub(trade.Fee.Amount)
+
+		updated, sales := match(lots, method, trade.Quantity, proceeds, minorUnits)
+
+		if created, err = repo.CreateSell(ctx, trade, updated, sales); err != nil {
+			loggerService.WithError(err).Error("Error creating the sell trade")
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Trade{}, err
+	}
+
+	return created, nil
+}
+
+// asset returns the financial asset with the id, caching it in the map.
+func (s *ServiceImpl) asset(ctx context.Context, assets map[uint]finasset.FinancialAsset, id uint) (finasset.FinancialAsset, error) {
+	if asset, ok := assets[id]; ok {
+		return asset, nil
+	}
+
+	asset, err := s.finAssetService.GetByID(ctx, id)
+	if err != nil {
+		return finasset.FinancialAsset{}, err
+	}
+
+	assets[id] = asset
+
+	return asset, nil
+}
+
+// checkLaterSells returns an error if there are sells of the asset in the account of the trade from the date on.
+func checkLaterSells(ctx context.Context, repo Repository, trade Trade, from time.Time) error {
+	later, err := repo.GetTrades(ctx, trade.UserID, TradeFilter{AccountID: trade.AccountID, AssetID: trade.AssetID, Side: Sell, From: from})
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the later sell trades from the repo")
+		return err
+	}
+
+	if len(later) > 0 {
+		return invalidTrade(fmt.Sprintf("The %s can not be dated before the sell of %s", trade.Side, later[len(later)-1].Date.Format(time.DateOnly)))
+	}
+
+	return nil
+}
+
+func invalidTrade(desc string) error {
+	loggerService.WithError(ErrInvalidTrade).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidTrade)
+}
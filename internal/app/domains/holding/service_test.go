@@ -0,0 +1,93 @@
+package holding
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+func TestServiceCreateTradeBeforeSell(t *testing.T) {
+	ctx := context.Background()
+	db := gorm.NewMemoryGorm()
+
+	finAssetService := finasset.NewFinAssetService(finasset.NewCurrencyRepository(db))
+	accountService := account.NewAccountService(account.NewAccountRepository(db), finAssetService)
+	service := NewHoldingService(db, NewHoldingRepository(db), accountService, finAssetService)
+
+	minorUnits, precision := 2, 8
+	assets := []finasset.FinancialAsset{
+		{Symbol: "USD", Name: "US dollar", Type: finasset.Currency, Metadata: finasset.Metadata{NumericCode: "840", MinorUnits: &minorUnits}},
+		{Symbol: "BTC", Name: "Bitcoin", Type: finasset.Crypto, Metadata: finasset.Metadata{Chain: "bitcoin", Precision: &precision}},
+	}
+
+	for _, asset := range assets {
+		if err := finAssetService.Create(ctx, asset); err != nil {
+			t.Fatalf("Create() of %s error = %v", asset.Symbol, err)
+		}
+	}
+
+	acc, err := accountService.Create(ctx, account.Account{UserID: 1, AssetID: 1, Name: "Broker", Type: account.Brokerage})
+	if err != nil {
+		t.Fatalf("Create() of the account error = %v", err)
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2023, time.October, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	trade := func(side Side, date time.Time) Trade {
+		return Trade{
+			UserID: 1, AccountID: acc.ID, AssetID: 2, Side: side, Date: date,
+			Quantity: decimal.NewFromInt(1), Price: money.Money{Amount: decimal.NewFromInt(100)},
+		}
+	}
+
+	for _, setup := range []Trade{trade(Buy, day(1)), trade(Buy, day(2)), trade(Sell, day(10))} {
+		if _, err := service.CreateTrade(ctx, setup); err != nil {
+			t.Fatalf("CreateTrade() of the %s of %s error = %v", setup.Side, setup.Date, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		trade   Trade
+		wantErr bool
+	}{
+		{name: "buy before the sell", trade: trade(Buy, day(5)), wantErr: true},
+		{name: "buy at the time of the sell", trade: trade(Buy, day(10)), wantErr: true},
+		{name: "sell before the sell", trade: trade(Sell, day(5)), wantErr: true},
+		{name: "buy after the sell", trade: trade(Buy, day(11))},
+		{name: "sell after the sell", trade: trade(Sell, day(12))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateTrade(ctx, tt.trade)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTrade) {
+					t.Errorf("CreateTrade() error = %v, want %v", err, ErrInvalidTrade)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("CreateTrade() unexpected error = %v", err)
+			}
+		})
+	}
+
+	// The sells consumed the two lots bought before them first in first out, the rejected buys opened none.
+	lots, err := service.GetLots(ctx, 1, TradeFilter{AccountID: acc.ID})
+	if err != nil || len(lots) != 1 || !lots[0].Date.Equal(day(11)) {
+		t.Errorf("GetLots() = %+v, %v, want only the lot bought on the 11th", lots, err)
+	}
+}
@@ -0,0 +1,282 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/shopspring/decimal"
+)
+
+// utf8BOM is the byte order mark some spreadsheets add at the start of the UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// parseCSV reads the transactions of a CSV file with the column mapping of the profile.
+// The errors of the file make the whole parse fail, while the errors of a row are kept in its transaction.
+// The rows are the line numbers of the file. Without an id column, the external id is a hash of the fields
+// of the row and the number of identical rows before it, so importing the same file twice does not duplicate them.
+func parseCSV(profile Profile, r io.Reader) ([]StatementTransaction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error reading the file", ErrInvalidStatement, err.Error())
+	}
+
+	text, err := decode(data, profile.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error decoding the file", ErrInvalidStatement, err.Error())
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma, _ = utf8.DecodeRuneInString(profile.Delimiter)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var (
+		columns map[string]int
+		indexes []int
+		txns    []StatementTransaction
+		seen    = map[string]int{}
+		records = 0
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf(crosscuting.WrapLabel, "Error reading the file", ErrInvalidStatement, err.Error())
+		}
+
+		records++
+		if records <= profile.SkipRows {
+			continue
+		}
+
+		if profile.HasHeader && columns == nil {
+			columns = map[string]int{}
+			for i, name := range record {
+				columns[normalizeColumn(name)] = i
+			}
+
+			continue
+		}
+
+		if indexes == nil {
+			if indexes, err = resolveColumns(profile, columns); err != nil {
+				return nil, fmt.Errorf(crosscuting.WrapLabel, "Error mapping the columns", ErrInvalidStatement, err.Error())
+			}
+		}
+
+		if isBlank(record) {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		txn := parseRow(profile, indexes, record)
+		txn.Row = line
+
+		if txn.FITID == "" && txn.Error == "" {
+			hash := rowHash(txn)
+			txn.FITID = fmt.Sprintf("csv:%s:%d", hash, seen[hash])
+			seen[hash]++
+		}
+
+		txns = append(txns, txn)
+	}
+
+	if indexes == nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, "The file does not have rows", ErrInvalidStatement)
+	}
+
+	return txns, nil
+}
+
+// The positions of the columns of a profile in the indexes resolved for a file.
+const (
+	dateIndex = iota
+	amountIndex
+	debitIndex
+	creditIndex
+	payeeIndex
+	memoIndex
+	idIndex
+)
+
+// resolveColumns returns the index of each column of the profile in the order of Profile.columns,
+// or -1 for the columns the profile does not map. The names are looked up in the header of the file.
+func resolveColumns(profile Profile, header map[string]int) ([]int, error) {
+	columns := profile.columns()
+	indexes := make([]int, len(columns))
+
+	for i, column := range columns {
+		if column == "" {
+			indexes[i] = -1
+			continue
+		}
+
+		if index, err := strconv.Atoi(column); err == nil {
+			indexes[i] = index
+			continue
+		}
+
+		index, ok := header[normalizeColumn(column)]
+		if !ok {
+			return nil, fmt.Errorf("the header does not have the column %q", column)
+		}
+
+		indexes[i] = index
+	}
+
+	return indexes, nil
+}
+
+// parseRow reads a transaction from the fields of a row.
+func parseRow(profile Profile, indexes []int, record []string) StatementTransaction {
+	var errs []string
+
+	field := func(i int) string {
+		if indexes[i] < 0 {
+			return ""
+		}
+
+		if indexes[i] >= len(record) {
+			errs = append(errs, fmt.Sprintf("the row does not have the column %d", indexes[i]))
+			return ""
+		}
+
+		return strings.TrimSpace(record[indexes[i]])
+	}
+
+	txn := StatementTransaction{
+		FITID: field(idIndex),
+		Name:  field(payeeIndex),
+		Memo:  field(memoIndex),
+	}
+
+	if indexes[idIndex] >= 0 && txn.FITID == "" {
+		errs = append(errs, "the id is required")
+	}
+
+	date, err := time.Parse(profile.dateLayout(), field(dateIndex))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("the date must have the format %s", profile.DateFormat))
+	}
+
+	txn.Date = date
+
+	if indexes[amountIndex] >= 0 {
+		amount, err := parseCSVAmount(field(amountIndex), profile.DecimalSeparator)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		txn.Amount = amount
+	} else {
+		debit, debitErr := parseCSVAmount(field(debitIndex), profile.DecimalSeparator)
+		credit, creditErr := parseCSVAmount(field(creditIndex), profile.DecimalSeparator)
+
+		for _, err := range []error{debitErr, creditErr} {
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+
+		txn.Amount = credit.Abs().Sub(debit.Abs())
+	}
+
+	txn.Error = strings.Join(errs, ", ")
+
+	return txn
+}
+
+// parseCSVAmount parses an amount with the decimal separator, removing the other separator of the thousands.
+// Empty amounts are zero, and amounts between parentheses are negative.
+func parseCSVAmount(value, decimalSeparator string) (decimal.Decimal, error) {
+	cleaned := strings.ReplaceAll(value, " ", "")
+	if cleaned == "" {
+		return decimal.Zero, nil
+	}
+
+	negative := strings.HasPrefix(cleaned, "(") && strings.HasSuffix(cleaned, ")")
+	if negative {
+		cleaned = cleaned[1 : len(cleaned)-1]
+	}
+
+	thousandsSeparator := ","
+	if decimalSeparator == "," {
+		thousandsSeparator = "."
+	}
+
+	cleaned = strings.ReplaceAll(cleaned, thousandsSeparator, "")
+	cleaned = strings.Replace(cleaned, decimalSeparator, ".", 1)
+
+	amount, err := decimal.NewFromString(cleaned)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if negative {
+		amount = amount.Neg()
+	}
+
+	return amount, nil
+}
+
+// decode returns the text of the file in the encoding.
+func decode(data []byte, encoding Encoding) (string, error) {
+	if encoding == Latin1 {
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+
+		return string(runes), nil
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		return "", errors.New("the file is not valid UTF-8, try the latin-1 encoding")
+	}
+
+	return string(data), nil
+}
+
+// rowHash returns a hash of the fields of a transaction that identify it.
+func rowHash(txn StatementTransaction) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		txn.Date.Format(time.RFC3339),
+		txn.Amount.String(),
+		txn.Name,
+		txn.Memo,
+	}, "\x1f")))
+
+	return hex.EncodeToString(sum[:16])
+}
+
+// normalizeColumn returns the name of a column to compare it without case or surrounding spaces.
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// isBlank reports if all the fields of the record are empty.
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// Statement is the struct for a bank or credit card statement read from a file.
+	Statement struct {
+		Currency      string
+		Account       string
+		Transactions  []StatementTransaction
+		LedgerBalance *LedgerBalance
+	}
+
+	// StatementTransaction is the struct for a transaction of a statement.
+	// The row locates it in the file, and the error describes the fields that could not be read, if any.
+	StatementTransaction struct {
+		Row    int
+		FITID  string
+		Type   string
+		Date   time.Time
+		Amount decimal.Decimal
+		Name   string
+		Memo   string
+		Error  string
+	}
+
+	// LedgerBalance is the struct for the balance of the account reported by the statement.
+	LedgerBalance struct {
+		Amount decimal.Decimal
+		AsOf   time.Time
+	}
+
+	// ImportedTransaction is the struct that links a transaction of a statement to the ledger transaction
+	// created from it. The external id is unique per account, so a transaction is not imported twice.
+	ImportedTransaction struct {
+		gorm.Model
+		UserID        uint   `json:"user_id" gorm:"not null"`
+		AccountID     uint   `json:"account_id" gorm:"not null"`
+		ExternalID    string `json:"external_id" gorm:"not null"`
+		TransactionID *uint  `json:"transaction_id"`
+	}
+
+	// ImportOptions is the struct for the nominal accounts that balance the imported transactions.
+	// The credits of the statement are booked against the income account and the debits against the expense one.
+	ImportOptions struct {
+		IncomeAccountID  uint
+		ExpenseAccountID uint
+	}
+
+	// Summary is the struct for the result of an import.
+	Summary struct {
+		Imported        int              `json:"imported"`
+		Skipped         int              `json:"skipped"`
+		Failed          int              `json:"failed"`
+		Failures        []Failure        `json:"failures"`
+		Reconciliations []Reconciliation `json:"reconciliations"`
+	}
+
+	// Failure is the struct for a row of the file that could not be imported.
+	// The row counts the transactions of the OFX files from one, and is the line of the CSV files.
+	Failure struct {
+		Row        int    `json:"row"`
+		ExternalID string `json:"external_id"`
+		Error      string `json:"error"`
+	}
+
+	// Reconciliation is the struct for the comparison of the balance of a statement with the one of the ledger.
+	Reconciliation struct {
+		AsOf             time.Time   `json:"as_of"`
+		StatementBalance money.Money `json:"statement_balance"`
+		LedgerBalance    money.Money `json:"ledger_balance"`
+		Difference       money.Money `json:"difference"`
+		Reconciled       bool        `json:"reconciled"`
+	}
+)
@@ -0,0 +1,335 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidStatement is returned when the file is not a valid OFX or QFX statement.
+	ErrInvalidStatement = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_STATEMENT", "invalid statement error")
+
+	errNoOFX = errors.New("the file does not have an OFX element")
+
+	// ofxDate matches the OFX dates: YYYYMMDD, optionally followed by HHMMSS, milliseconds and a [offset:zone] suffix.
+	ofxDate = regexp.MustCompile(`^(\d{8})(\d{6})?(?:\.\d{1,3})?(?:\[([+-]?\d{1,2}(?:\.\d+)?)(?::[A-Za-z]+)?\])?$`)
+)
+
+// element is a node of an OFX document. Aggregates have children and leaves have a value.
+type element struct {
+	name     string
+	value    string
+	children []*element
+}
+
+// parseOFX parses the statements of an OFX 1.x (SGML), OFX 2.x (XML) or QFX file.
+// Bank and credit card statements are supported, the other messages are ignored.
+func parseOFX(r io.Reader) ([]Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error reading the statement", ErrInvalidStatement, err.Error())
+	}
+
+	root, err := parseElements(string(data))
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error parsing the statement", ErrInvalidStatement, err.Error())
+	}
+
+	var statements []Statement
+
+	row := 0
+
+	for _, node := range root.findAll("STMTRS", "CCSTMTRS") {
+		statement, err := parseStatement(node)
+		if err != nil {
+			return nil, fmt.Errorf(crosscuting.WrapLabel, "Error parsing the statement", ErrInvalidStatement, err.Error())
+		}
+
+		for i := range statement.Transactions {
+			row++
+			statement.Transactions[i].Row = row
+		}
+
+		statements = append(statements, statement)
+	}
+
+	if len(statements) == 0 {
+		return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, "The file does not have bank or credit card statements", ErrInvalidStatement)
+	}
+
+	return statements, nil
+}
+
+// parseElements builds the tree of the OFX element of the document, skipping the SGML or XML headers.
+// The SGML leaves do not have end tags, so the text after a start tag is the value of a leaf, and an element
+// without text is an aggregate only if it is closed before its parent, otherwise it is an empty leaf.
+// An end tag closes the aggregate with its name and any leaf left open inside it.
+func parseElements(doc string) (*element, error) {
+	start := strings.Index(strings.ToUpper(doc), "<OFX>")
+	if start < 0 {
+		return nil, errNoOFX
+	}
+
+	doc = doc[start:]
+
+	root := &element{}
+	stack := []*element{root}
+
+	for len(doc) > 0 {
+		open := strings.IndexByte(doc, '<')
+		if open < 0 {
+			break
+		}
+
+		end := strings.IndexByte(doc[open:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated tag at %q", truncate(doc[open:]))
+		}
+
+		tag := strings.TrimSpace(doc[open+1 : open+end])
+		doc = doc[open+end+1:]
+
+		switch {
+		case tag == "" || strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!"):
+			continue
+		case strings.HasPrefix(tag, "/"):
+			name := strings.ToUpper(strings.TrimSpace(tag[1:]))
+
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		default:
+			selfClosing := strings.HasSuffix(tag, "/")
+			name := strings.ToUpper(strings.Fields(strings.TrimSuffix(tag, "/"))[0])
+
+			text := doc
+			if next := strings.IndexByte(doc, '<'); next >= 0 {
+				text = doc[:next]
+			}
+
+			node := &element{name: name, value: html.UnescapeString(strings.TrimSpace(text))}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+
+			if node.value == "" && !selfClosing && closedBefore(doc, name, parent.name) {
+				stack = append(stack, node)
+			}
+		}
+	}
+
+	ofx := root.find("OFX")
+	if ofx == nil {
+		return nil, errNoOFX
+	}
+
+	return ofx, nil
+}
+
+// closedBefore reports if the rest of the document has the end tag of the element before the one of its parent.
+func closedBefore(doc, name, parent string) bool {
+	for {
+		open := strings.IndexByte(doc, '<')
+		if open < 0 {
+			return false
+		}
+
+		end := strings.IndexByte(doc[open:], '>')
+		if end < 0 {
+			return false
+		}
+
+		tag := strings.TrimSpace(doc[open+1 : open+end])
+		doc = doc[open+end+1:]
+
+		if !strings.HasPrefix(tag, "/") {
+			continue
+		}
+
+		switch strings.ToUpper(strings.TrimSpace(tag[1:])) {
+		case name:
+			return true
+		case parent:
+			return false
+		}
+	}
+}
+
+// parseStatement reads a bank or credit card statement.
+func parseStatement(node *element) (Statement, error) {
+	statement := Statement{
+		Currency: strings.ToUpper(node.get("CURDEF")),
+		Account:  node.get("ACCTID"),
+	}
+
+	if list := node.find("BANKTRANLIST"); list != nil {
+		for _, trn := range list.children {
+			if trn.name == "STMTTRN" {
+				statement.Transactions = append(statement.Transactions, parseTransaction(trn))
+			}
+		}
+	}
+
+	if balance := node.find("LEDGERBAL"); balance != nil {
+		amount, err := parseAmount(balance.get("BALAMT"))
+		if err != nil {
+			return Statement{}, err
+		}
+
+		date, err := parseOFXDate(balance.get("DTASOF"))
+		if err != nil {
+			return Statement{}, err
+		}
+
+		statement.LedgerBalance = &LedgerBalance{Amount: amount, AsOf: date}
+	}
+
+	return statement, nil
+}
+
+// parseTransaction reads a transaction of a statement. The errors of its fields are kept in the transaction,
+// so the rest of the statement can still be imported.
+func parseTransaction(node *element) StatementTransaction {
+	txn := StatementTransaction{
+		FITID: node.get("FITID"),
+		Type:  node.get("TRNTYPE"),
+		Name:  node.get("NAME"),
+		Memo:  node.get("MEMO"),
+	}
+
+	var errs []string
+
+	if txn.FITID == "" {
+		errs = append(errs, "the FITID is required")
+	}
+
+	date, err := parseOFXDate(node.get("DTPOSTED"))
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	amount, err := parseAmount(node.get("TRNAMT"))
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	txn.Date = date
+	txn.Amount = amount
+	txn.Error = strings.Join(errs, ", ")
+
+	return txn
+}
+
+// parseOFXDate parses an OFX date. Without an offset, the date is in UTC.
+func parseOFXDate(value string) (time.Time, error) {
+	match := ofxDate.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	location := time.UTC
+
+	if match[3] != "" {
+		hours, err := decimal.NewFromString(match[3])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date offset %q", value)
+		}
+
+		location = time.FixedZone("", int(hours.Mul(decimal.NewFromInt(3600)).IntPart()))
+	}
+
+	clock := match[2]
+	if clock == "" {
+		clock = "000000"
+	}
+
+	date, err := time.ParseInLocation("20060102150405", match[1]+clock, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	return date, nil
+}
+
+// parseAmount parses an OFX amount, which can use a comma as the decimal separator.
+func parseAmount(value string) (decimal.Decimal, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	return amount, nil
+}
+
+// find returns the first descendant with one of the names, depth first.
+func (e *element) find(names ...string) *element {
+	for _, child := range e.children {
+		for _, name := range names {
+			if child.name == name {
+				return child
+			}
+		}
+
+		if found := child.find(names...); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// findAll returns all the descendants with one of the names, without looking inside the matches.
+func (e *element) findAll(names ...string) []*element {
+	var found []*element
+
+	for _, child := range e.children {
+		matched := false
+
+		for _, name := range names {
+			if child.name == name {
+				found = append(found, child)
+				matched = true
+			}
+		}
+
+		if !matched {
+			found = append(found, child.findAll(names...)...)
+		}
+	}
+
+	return found
+}
+
+// get returns the value of the first descendant leaf with the name, or empty if there is none.
+func (e *element) get(name string) string {
+	if found := e.find(name); found != nil {
+		return found.value
+	}
+
+	return ""
+}
+
+// truncate shortens the text for the error messages.
+func truncate(text string) string {
+	const max = 20
+	if len(text) > max {
+		return text[:max] + "..."
+	}
+
+	return text
+}
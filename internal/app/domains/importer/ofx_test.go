@@ -0,0 +1,272 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+
+<OFX>
+<SIGNONMSGSRSV1>
+<SONRS>
+<STATUS>
+<CODE>0
+<SEVERITY>INFO
+</STATUS>
+<DTSERVER>20231105120000[-5:EST]
+<LANGUAGE>ENG
+</SONRS>
+</SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>1
+<STATUS>
+<CODE>0
+<SEVERITY>INFO
+<MESSAGE>
+</STATUS>
+<STMTRS>
+<CURDEF>USD
+<BANKACCTFROM>
+<BANKID>121000248
+<ACCTID>123456789
+<ACCTTYPE>CHECKING
+</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20231001
+<DTEND>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20231002120000[-5:EST]
+<TRNAMT>-42,50
+<FITID>2023100201
+<NAME>
+<MEMO>Grocery &amp; Co
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20231015
+<TRNAMT>1500.00
+<FITID>2023101501
+<NAME>Payroll
+<MEMO>
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>1457.50
+<DTASOF>20231031
+</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`
+
+const xmlStatement = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="211" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <TRNUID>1</TRNUID>
+      <CCSTMTRS>
+        <CURDEF>usd</CURDEF>
+        <CCACCTFROM>
+          <ACCTID>4111</ACCTID>
+        </CCACCTFROM>
+        <BANKTRANLIST>
+          <DTSTART>20231001000000.000[-3:BRT]</DTSTART>
+          <DTEND></DTEND>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20231003101500.000[-3:BRT]</DTPOSTED>
+            <TRNAMT>-10.00</TRNAMT>
+            <FITID>A1</FITID>
+            <NAME></NAME>
+            <MEMO/>
+          </STMTTRN>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>2023-10-04</DTPOSTED>
+            <TRNAMT>ten</TRNAMT>
+            <NAME>Broken</NAME>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL>
+          <BALAMT>-10.00</BALAMT>
+          <DTASOF>20231031235959</DTASOF>
+        </LEDGERBAL>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestParseOFXSGML(t *testing.T) {
+	statements, err := parseOFX(strings.NewReader(sgmlStatement))
+	if err != nil {
+		t.Fatalf("parseOFX() error = %v", err)
+	}
+
+	if len(statements) != 1 {
+		t.Fatalf("parseOFX() = %d statements, want 1", len(statements))
+	}
+
+	statement := statements[0]
+	if statement.Currency != "USD" || statement.Account != "123456789" {
+		t.Errorf("parseOFX() currency and account = %s, %s, want USD, 123456789", statement.Currency, statement.Account)
+	}
+
+	// The empty DTEND and NAME leaves do not swallow the elements after them.
+	want := []StatementTransaction{
+		{
+			Row: 1, FITID: "2023100201", Type: "DEBIT", Memo: "Grocery & Co",
+			Date:   time.Date(2023, time.October, 2, 17, 0, 0, 0, time.UTC),
+			Amount: decimal.RequireFromString("-42.50"),
+		},
+		{
+			Row: 2, FITID: "2023101501", Type: "CREDIT", Name: "Payroll",
+			Date:   time.Date(2023, time.October, 15, 0, 0, 0, 0, time.UTC),
+			Amount: decimal.RequireFromString("1500"),
+		},
+	}
+
+	assertTransactions(t, statement.Transactions, want)
+
+	// A DTASOF without time is the start of the day in UTC.
+	if statement.LedgerBalance == nil || !statement.LedgerBalance.Amount.Equal(decimal.RequireFromString("1457.50")) ||
+		!statement.LedgerBalance.AsOf.Equal(time.Date(2023, time.October, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseOFX() ledger balance = %+v, want 1457.50 as of 2023-10-31", statement.LedgerBalance)
+	}
+}
+
+func TestParseOFXXML(t *testing.T) {
+	statements, err := parseOFX(strings.NewReader(xmlStatement))
+	if err != nil {
+		t.Fatalf("parseOFX() error = %v", err)
+	}
+
+	if len(statements) != 1 {
+		t.Fatalf("parseOFX() = %d statements, want 1", len(statements))
+	}
+
+	statement := statements[0]
+	if statement.Currency != "USD" || statement.Account != "4111" {
+		t.Errorf("parseOFX() currency and account = %s, %s, want USD, 4111", statement.Currency, statement.Account)
+	}
+
+	want := []StatementTransaction{
+		{
+			Row: 1, FITID: "A1", Type: "DEBIT",
+			Date:   time.Date(2023, time.October, 3, 13, 15, 0, 0, time.UTC),
+			Amount: decimal.RequireFromString("-10"),
+		},
+		{
+			Row: 2, Type: "DEBIT", Name: "Broken",
+			Error: `the FITID is required, invalid date "2023-10-04", invalid amount "ten"`,
+		},
+	}
+
+	assertTransactions(t, statement.Transactions, want)
+
+	if statement.LedgerBalance == nil || !statement.LedgerBalance.AsOf.Equal(time.Date(2023, time.October, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("parseOFX() ledger balance = %+v, want as of 2023-10-31 23:59:59", statement.LedgerBalance)
+	}
+}
+
+func TestParseOFXErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{name: "not an OFX file", doc: "date,amount\n2023-10-01,10"},
+		{name: "without statements", doc: "<OFX><SIGNONMSGSRSV1><SONRS><CODE>0</SONRS></SIGNONMSGSRSV1></OFX>"},
+		{name: "unterminated tag", doc: "<OFX><BANKMSGSRSV1><STMTRS"},
+		{name: "invalid balance date", doc: "<OFX><STMTRS><CURDEF>USD<LEDGERBAL><BALAMT>1<DTASOF>yesterday</LEDGERBAL></STMTRS></OFX>"},
+		{name: "invalid balance amount", doc: "<OFX><STMTRS><CURDEF>USD<LEDGERBAL><BALAMT>one<DTASOF>20231031</LEDGERBAL></STMTRS></OFX>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseOFX(strings.NewReader(tt.doc)); !errors.Is(err, ErrInvalidStatement) {
+				t.Errorf("parseOFX() error = %v, want %v", err, ErrInvalidStatement)
+			}
+		})
+	}
+}
+
+func TestParseElementsEmptyLeaves(t *testing.T) {
+	root, err := parseElements("<OFX><A><B><C>1</A><D></D><E/><F>2</OFX>")
+	if err != nil {
+		t.Fatalf("parseElements() error = %v", err)
+	}
+
+	var names []string
+	for _, child := range root.children {
+		names = append(names, child.name)
+	}
+
+	if strings.Join(names, ",") != "A,D,E,F" {
+		t.Fatalf("parseElements() children of OFX = %v, want A,D,E,F", names)
+	}
+
+	if a := root.children[0]; len(a.children) != 2 || a.children[0].name != "B" || len(a.children[0].children) != 0 {
+		t.Errorf("parseElements() the empty leaf B must be a sibling of C inside A")
+	}
+}
+
+func TestImportOFXSkipsImportedFITIDs(t *testing.T) {
+	ctx := context.Background()
+	db := gorm.NewMemoryGorm()
+
+	service, _, ids := setupImporter(t, db, NewImporterRepository(db))
+
+	options := ImportOptions{IncomeAccountID: ids[1], ExpenseAccountID: ids[2]}
+
+	// The second transaction repeats the FITID of the first one.
+	doc := strings.Replace(sgmlStatement, "<FITID>2023101501", "<FITID>2023100201", 1)
+
+	summary, err := service.ImportOFX(ctx, 1, ids[0], options, strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("ImportOFX() error = %v", err)
+	}
+
+	if summary.Imported != 1 || summary.Skipped != 1 || summary.Failed != 0 {
+		t.Errorf("ImportOFX() = %+v, want 1 imported and 1 skipped", summary)
+	}
+
+	summary, err = service.ImportOFX(ctx, 1, ids[0], options, strings.NewReader(sgmlStatement))
+	if err != nil {
+		t.Fatalf("ImportOFX() error = %v", err)
+	}
+
+	if summary.Imported != 1 || summary.Skipped != 1 || summary.Failed != 0 {
+		t.Errorf("ImportOFX() again = %+v, want the new FITID imported and the other skipped", summary)
+	}
+}
+
+func assertTransactions(t *testing.T, got, want []StatementTransaction) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("parseOFX() = %d transactions, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Row != w.Row || g.FITID != w.FITID || g.Type != w.Type || g.Name != w.Name || g.Memo != w.Memo ||
+			!g.Date.Equal(w.Date) || !g.Amount.Equal(w.Amount) || g.Error != w.Error {
+			t.Errorf("parseOFX() transaction %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
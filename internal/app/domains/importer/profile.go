@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// UTF8 is the encoding of the files in UTF-8, with or without a byte order mark.
+	UTF8 Encoding = "utf-8"
+	// Latin1 is the encoding of the files in ISO-8859-1.
+	Latin1 Encoding = "latin-1"
+
+	// DefaultDateFormat is the date format of the profiles that do not set one.
+	DefaultDateFormat = "YYYY-MM-DD"
+)
+
+// dateTokens translates the tokens of the date formats of the profiles to the layouts of the time package.
+var dateTokens = strings.NewReplacer(
+	"YYYY", "2006",
+	"YY", "06",
+	"MM", "01",
+	"DD", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+type (
+	// Encoding can be utf-8 or latin-1.
+	Encoding string
+
+	// Profile is the struct for the column mapping of the CSV files of a bank, saved by a user.
+	// The columns are zero based indexes or, when the file has a header, the names of the columns.
+	// The amount is either in the amount column, positive for credits, or split in the debit and credit columns.
+	Profile struct {
+		gorm.Model
+		UserID           uint     `json:"user_id" gorm:"not null"`
+		Name             string   `json:"name" gorm:"not null"`
+		Delimiter        string   `json:"delimiter" gorm:"not null"`
+		Encoding         Encoding `json:"encoding" gorm:"not null"`
+		SkipRows         int      `json:"skip_rows" gorm:"not null"`
+		HasHeader        bool     `json:"has_header" gorm:"not null"`
+		DateColumn       string   `json:"date_column" gorm:"not null"`
+		DateFormat       string   `json:"date_format" gorm:"not null"`
+		AmountColumn     string   `json:"amount_column" gorm:"not null"`
+		DebitColumn      string   `json:"debit_column" gorm:"not null"`
+		CreditColumn     string   `json:"credit_column" gorm:"not null"`
+		PayeeColumn      string   `json:"payee_column" gorm:"not null"`
+		MemoColumn       string   `json:"memo_column" gorm:"not null"`
+		IDColumn         string   `json:"id_column" gorm:"not null"`
+		DecimalSeparator string   `json:"decimal_separator" gorm:"not null"`
+	}
+
+	// Preview is the struct for a row of a CSV file read with a profile, before importing it.
+	Preview struct {
+		Row        int             `json:"row"`
+		ExternalID string          `json:"external_id"`
+		Date       time.Time       `json:"date"`
+		Amount     decimal.Decimal `json:"amount"`
+		Payee      string          `json:"payee"`
+		Memo       string          `json:"memo"`
+		Duplicate  bool            `json:"duplicate"`
+		Error      string          `json:"error,omitempty"`
+	}
+)
+
+// TableName overrides the table name of the profiles.
+func (Profile) TableName() string {
+	return "import_profiles"
+}
+
+// IsValid reports if the encoding is supported.
+func (e Encoding) IsValid() bool {
+	return e == UTF8 || e == Latin1
+}
+
+// withDefaults returns the profile with the default delimiter, encoding, date format and decimal separator
+// for the fields that are empty.
+func (p Profile) withDefaults() Profile {
+	if p.Delimiter == "" {
+		p.Delimiter = ","
+	}
+
+	if p.Encoding == "" {
+		p.Encoding = UTF8
+	}
+
+	if p.DateFormat == "" {
+		p.DateFormat = DefaultDateFormat
+	}
+
+	if p.DecimalSeparator == "" {
+		p.DecimalSeparator = "."
+	}
+
+	return p
+}
+
+// validate checks the fields of the profile and returns the description of the first invalid one.
+func (p Profile) validate() string {
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		return "The name is required"
+	case len([]rune(p.Delimiter)) != 1:
+		return "The delimiter must be a single character"
+	case !p.Encoding.IsValid():
+		return fmt.Sprintf("Unknown encoding %s", p.Encoding)
+	case p.SkipRows < 0:
+		return "The skip rows can not be negative"
+	case p.DecimalSeparator != "." && p.DecimalSeparator != ",":
+		return "The decimal separator must be . or ,"
+	case p.DateColumn == "":
+		return "The date column is required"
+	case p.AmountColumn == "" && (p.DebitColumn == "" || p.CreditColumn == ""):
+		return "The amount column or both the debit and credit columns are required"
+	case p.AmountColumn != "" && (p.DebitColumn != "" || p.CreditColumn != ""):
+		return "The amount column can not be combined with the debit and credit columns"
+	}
+
+	for _, column := range p.columns() {
+		if column == "" {
+			continue
+		}
+
+		if _, err := strconv.Atoi(column); err != nil && !p.HasHeader {
+			return fmt.Sprintf("The column %s must be an index when the file does not have a header", column)
+		}
+
+		if index, err := strconv.Atoi(column); err == nil && index < 0 {
+			return fmt.Sprintf("The column %s can not be negative", column)
+		}
+	}
+
+	return ""
+}
+
+// columns returns the columns mapped by the profile.
+func (p Profile) columns() []string {
+	return []string{p.DateColumn, p.AmountColumn, p.DebitColumn, p.CreditColumn, p.PayeeColumn, p.MemoColumn, p.IDColumn}
+}
+
+// dateLayout returns the layout of the time package of the date format of the profile.
+func (p Profile) dateLayout() string {
+	return dateTokens.Replace(p.DateFormat)
+}
@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.importer.repository")
+
+// Repository is the interface for the importer repository.
+type Repository interface {
+	Claim(ctx context.Context, userID, accountID uint, externalID string) (ImportedTransaction, bool, error)
+	SetTransaction(ctx context.Context, id, transactionID uint) error
+	GetImported(ctx context.Context, accountID uint, externalIDs []string) (map[string]bool, error)
+	CreateProfile(ctx context.Context, profile Profile) (Profile, error)
+	GetProfiles(ctx context.Context, userID uint) ([]Profile, error)
+	GetProfileByID(ctx context.Context, userID, id uint) (Profile, error)
+	UpdateProfile(ctx context.Context, profile Profile) error
+	DeleteProfile(ctx context.Context, userID, id uint) error
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the importer repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewImporterRepository creates a new importer repository.
+func NewImporterRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// Claim records the external id of the account before importing it.
+// It returns false when the external id was already imported into the account.
+func (r *RepositoryImpl) Claim(ctx context.Context, userID, accountID uint, externalID string) (ImportedTransaction, bool, error) {
+	imported := ImportedTransaction{UserID: userID, AccountID: accountID, ExternalID: externalID}
+
+	// The insert has its own savepoint, so a conflict does not abort the transaction of the repository.
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		return tx.Create(ctx, &imported)
+	})
+	if err == nil {
+		return imported, true, nil
+	}
+
+	if errors.Is(err, crosscuting.ErrConflict) {
+		return ImportedTransaction{}, false, nil
+	}
+
+	loggerRepo.WithError(err).Error("Error creating the imported transaction in the database")
+
+	return ImportedTransaction{}, false, err
+}
+
+// SetTransaction links the claimed external id to the ledger transaction created from it.
+func (r *RepositoryImpl) SetTransaction(ctx context.Context, id, transactionID uint) error {
+	if _, err := r.db.WhereUpdates(ctx, &ImportedTransaction{}, map[string]interface{}{"transaction_id": transactionID}, "id = ?", id); err != nil {
+		loggerRepo.WithError(err).Error("Error updating the imported transaction in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// GetImported returns the external ids of the list that were already imported into the account.
+func (r *RepositoryImpl) GetImported(ctx context.Context, accountID uint, externalIDs []string) (map[string]bool, error) {
+	imported := map[string]bool{}
+	if len(externalIDs) == 0 {
+		return imported, nil
+	}
+
+	var records []ImportedTransaction
+	if err := r.db.WhereFind(ctx, &records, "account_id = ? AND external_id IN ?", accountID, externalIDs); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the imported transactions from the database")
+
+		return nil, err
+	}
+
+	for _, record := range records {
+		imported[record.ExternalID] = true
+	}
+
+	return imported, nil
+}
+
+// CreateProfile creates a new import profile and returns it with its id.
+func (r *RepositoryImpl) CreateProfile(ctx context.Context, profile Profile) (Profile, error) {
+	if err := r.db.Create(ctx, &profile); err != nil {
+		loggerRepo.WithError(err).Error("Error creating record in the database")
+
+		return Profile{}, err
+	}
+
+	return profile, nil
+}
+
+// GetProfiles returns the import profiles of the user, sorted by id.
+func (r *RepositoryImpl) GetProfiles(ctx context.Context, userID uint) ([]Profile, error) {
+	var profiles []Profile
+	if err := r.db.Order("id ASC").WhereFind(ctx, &profiles, "user_id = ?", userID); err != nil {
+		loggerRepo.WithError(err).Error("Error getting records from the database")
+
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// GetProfileByID returns the import profile of the user with the given id.
+func (r *RepositoryImpl) GetProfileByID(ctx context.Context, userID, id uint) (Profile, error) {
+	var profile Profile
+	if err := r.db.WhereFirst(ctx, &profile, "user_id = ? AND id = ?", userID, id); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the import profile by id")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return Profile{}, fmt.Errorf(crosscuting.WrapLabel, "Import profile not found", ErrProfileNotFound, err.Error())
+		}
+
+		return Profile{}, err
+	}
+
+	return profile, nil
+}
+
+// UpdateProfile replaces the settings of an import profile of the user.
+func (r *RepositoryImpl) UpdateProfile(ctx context.Context, profile Profile) error {
+	values := map[string]interface{}{
+		"name":              profile.Name,
+		"delimiter":         profile.Delimiter,
+		"encoding":          profile.Encoding,
+		"skip_rows":         profile.SkipRows,
+		"has_header":        profile.HasHeader,
+		"date_column":       profile.DateColumn,
+		"date_format":       profile.DateFormat,
+		"amount_column":     profile.AmountColumn,
+		"debit_column":      profile.DebitColumn,
+		"credit_column":     profile.CreditColumn,
+		"payee_column":      profile.PayeeColumn,
+		"memo_column":       profile.MemoColumn,
+		"id_column":         profile.IDColumn,
+		"decimal_separator": profile.DecimalSeparator,
+	}
+
+	rows, err := r.db.WhereUpdates(ctx, &Profile{}, values, "user_id = ? AND id = ?", profile.UserID, profile.ID)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error updating record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Import profile not found", ErrProfileNotFound)
+	}
+
+	return nil
+}
+
+// DeleteProfile deletes an import profile of the user.
+func (r *RepositoryImpl) DeleteProfile(ctx context.Context, userID, id uint) error {
+	rows, err := r.db.WhereDelete(ctx, &Profile{}, "user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error deleting record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Import profile not found", ErrProfileNotFound)
+	}
+
+	return nil
+}
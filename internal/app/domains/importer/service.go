@@ -0,0 +1,409 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerService = logger.Setup("domain.importer.service")
+	// ErrInvalidImport is returned when the accounts of the import are not valid.
+	ErrInvalidImport = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_IMPORT", "invalid import error")
+	// ErrInvalidProfile is returned when the column mapping of an import profile is not valid.
+	ErrInvalidProfile = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_IMPORT_PROFILE", "invalid import profile error")
+	// ErrProfileNotFound is returned when the import profile is not found.
+	ErrProfileNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "IMPORT_PROFILE_NOT_FOUND", "import profile not found error")
+)
+
+// Service is the interface for the importer service.
+type Service interface {
+	ImportOFX(ctx context.Context, userID, accountID uint, options ImportOptions, file io.Reader) (Summary, error)
+	PreviewCSV(ctx context.Context, userID, accountID, profileID uint, file io.Reader) ([]Preview, error)
+	ImportCSV(ctx context.Context, userID, accountID, profileID uint, options ImportOptions, file io.Reader) (Summary, error)
+	CreateProfile(ctx context.Context, profile Profile) (Profile, error)
+	GetProfiles(ctx context.Context, userID uint) ([]Profile, error)
+	GetProfile(ctx context.Context, userID, id uint) (Profile, error)
+	UpdateProfile(ctx context.Context, profile Profile) (Profile, error)
+	DeleteProfile(ctx context.Context, userID, id uint) error
+}
+
+// ServiceImpl is the struct that contains the importer service.
+// The database runs the units of work that import each transaction.
+type ServiceImpl struct {
+	db              gorm.Gorm
+	repo            Repository
+	accountService  account.Service
+	ledgerService   ledger.Service
+	finAssetService finasset.Service
+}
+
+// NewImporterService creates a new importer service.
+func NewImporterService(db gorm.Gorm, repo Repository, accountService account.Service, ledgerService ledger.Service, finAssetService finasset.Service) Service {
+	return &ServiceImpl{db: db, repo: repo, accountService: accountService, ledgerService: ledgerService, finAssetService: finAssetService}
+}
+
+// ImportOFX imports the transactions of the statements of an OFX or QFX file into the account of the user.
+// Each transaction becomes a ledger transaction between the account and the income or expense account of the options,
+// and the ones whose FITID was already imported into the account are skipped. The transactions that fail do not stop
+// the others. Then the ledger balance of each statement is compared with the balance of the account.
+func (s *ServiceImpl) ImportOFX(ctx context.Context, userID, accountID uint, options ImportOptions, file io.Reader) (Summary, error) {
+	acc, currency, err := s.importAccounts(ctx, userID, accountID, options)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	statements, err := parseOFX(file)
+	if err != nil {
+		loggerService.WithError(err).Error("Error parsing the statement")
+		return Summary{}, err
+	}
+
+	for _, statement := range statements {
+		if statement.Currency != "" && statement.Currency != currency.Symbol {
+			desc := fmt.Sprintf("The statement is in %s and the account in %s", statement.Currency, currency.Symbol)
+			loggerService.WithError(ErrInvalidStatement).Error(desc)
+
+			return Summary{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidStatement)
+		}
+	}
+
+	summary := Summary{Failures: []Failure{}, Reconciliations: []Reconciliation{}}
+
+	for _, statement := range statements {
+		s.importTransactions(ctx, &summary, acc, options, statement.Transactions)
+	}
+
+	for _, statement := range statements {
+		if statement.LedgerBalance == nil {
+			continue
+		}
+
+		reconciliation, err := s.reconcile(ctx, acc, currency.Symbol, *statement.LedgerBalance)
+		if err != nil {
+			return Summary{}, err
+		}
+
+		summary.Reconciliations = append(summary.Reconciliations, reconciliation)
+	}
+
+	return summary, nil
+}
+
+// PreviewCSV reads the rows of a CSV file with an import profile of the user without importing them.
+// The rows whose external id was already imported into the account are marked as duplicated.
+func (s *ServiceImpl) PreviewCSV(ctx context.Context, userID, accountID, profileID uint, file io.Reader) ([]Preview, error) {
+	acc, err := s.accountService.GetByID(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := s.readCSV(ctx, userID, profileID, file)
+	if err != nil {
+		return nil, err
+	}
+
+	externalIDs := make([]string, 0, len(txns))
+	for _, txn := range txns {
+		if txn.FITID != "" {
+			externalIDs = append(externalIDs, txn.FITID)
+		}
+	}
+
+	imported, err := s.repo.GetImported(ctx, acc.ID, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]Preview, 0, len(txns))
+	for _, txn := range txns {
+		previews = append(previews, Preview{
+			Row:        txn.Row,
+			ExternalID: txn.FITID,
+			Date:       txn.Date,
+			Amount:     txn.Amount,
+			Payee:      txn.Name,
+			Memo:       txn.Memo,
+			Duplicate:  imported[txn.FITID],
+			Error:      txn.Error,
+		})
+	}
+
+	return previews, nil
+}
+
+// ImportCSV imports the rows of a CSV file read with an import profile of the user into the account,
+// the same way ImportOFX imports the transactions of a statement. CSV files do not report balances to reconcile.
+func (s *ServiceImpl) ImportCSV(ctx context.Context, userID, accountID, profileID uint, options ImportOptions, file io.Reader) (Summary, error) {
+	acc, _, err := s.importAccounts(ctx, userID, accountID, options)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	txns, err := s.readCSV(ctx, userID, profileID, file)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{Failures: []Failure{}, Reconciliations: []Reconciliation{}}
+	s.importTransactions(ctx, &summary, acc, options, txns)
+
+	return summary, nil
+}
+
+// readCSV reads the transactions of a CSV file with an import profile of the user.
+func (s *ServiceImpl) readCSV(ctx context.Context, userID, profileID uint, file io.Reader) ([]StatementTransaction, error) {
+	profile, err := s.GetProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := parseCSV(profile, file)
+	if err != nil {
+		loggerService.WithError(err).Error("Error parsing the CSV file")
+		return nil, err
+	}
+
+	return txns, nil
+}
+
+// CreateProfile validates and creates a new import profile, filling the empty settings with their defaults.
+func (s *ServiceImpl) CreateProfile(ctx context.Context, profile Profile) (Profile, error) {
+	profile = profile.withDefaults()
+	if desc := profile.validate(); desc != "" {
+		return Profile{}, invalidProfile(desc)
+	}
+
+	created, err := s.repo.CreateProfile(ctx, profile)
+	if err != nil {
+		loggerService.WithError(err).Error("Error creating the import profile")
+		return Profile{}, err
+	}
+
+	return created, nil
+}
+
+// GetProfiles returns the import profiles of the user.
+func (s *ServiceImpl) GetProfiles(ctx context.Context, userID uint) ([]Profile, error) {
+	profiles, err := s.repo.GetProfiles(ctx, userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the import profiles from the repo")
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// GetProfile returns the import profile of the user with the given id.
+func (s *ServiceImpl) GetProfile(ctx context.Context, userID, id uint) (Profile, error) {
+	profile, err := s.repo.GetProfileByID(ctx, userID, id)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the import profile from the repo")
+		return Profile{}, err
+	}
+
+	return profile, nil
+}
+
+// UpdateProfile validates and replaces the settings of an import profile of the user, and returns it updated.
+func (s *ServiceImpl) UpdateProfile(ctx context.Context, profile Profile) (Profile, error) {
+	profile = profile.withDefaults()
+	if desc := profile.validate(); desc != "" {
+		return Profile{}, invalidProfile(desc)
+	}
+
+	if err := s.repo.UpdateProfile(ctx, profile); err != nil {
+		loggerService.WithError(err).Error("Error updating the import profile")
+		return Profile{}, err
+	}
+
+	return s.GetProfile(ctx, profile.UserID, profile.ID)
+}
+
+// DeleteProfile deletes an import profile of the user.
+func (s *ServiceImpl) DeleteProfile(ctx context.Context, userID, id uint) error {
+	if err := s.repo.DeleteProfile(ctx, userID, id); err != nil {
+		loggerService.WithError(err).Error("Error deleting the import profile")
+		return err
+	}
+
+	return nil
+}
+
+// importTransactions imports the transactions read from a file and counts them in the summary.
+func (s *ServiceImpl) importTransactions(ctx context.Context, summary *Summary, acc account.Account, options ImportOptions, txns []StatementTransaction) {
+	for _, txn := range txns {
+		s.importTransaction(ctx, summary, acc, options, txn)
+	}
+}
+
+// importTransaction imports a transaction read from a file and counts it in the summary.
+// The external id is claimed, the ledger transaction created and linked to the claim in one unit of work,
+// so a failure at any step leaves the external id free to be imported again.
+func (s *ServiceImpl) importTransaction(ctx context.Context, summary *Summary, acc account.Account, options ImportOptions, txn StatementTransaction) {
+	fail := func(desc string) {
+		summary.Failed++
+		summary.Failures = append(summary.Failures, Failure{Row: txn.Row, ExternalID: txn.FITID, Error: desc})
+	}
+
+	if txn.Error != "" {
+		fail(txn.Error)
+		return
+	}
+
+	if txn.Amount.IsZero() {
+		fail("the amount is zero")
+		return
+	}
+
+	var skipped bool
+
+	err := s.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		repo := s.repo.WithTx(tx)
+
+		claim, claimed, err := repo.Claim(ctx, acc.UserID, acc.ID, txn.FITID)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			skipped = true
+			return nil
+		}
+
+		created, err := s.ledgerService.WithTx(tx).CreateTransaction(ctx, ledgerTransaction(acc, options, txn))
+		if err != nil {
+			return err
+		}
+
+		return repo.SetTransaction(ctx, claim.ID, created.ID)
+	})
+	if err != nil {
+		loggerService.WithError(err).Errorf("Error importing the transaction %s", txn.FITID)
+		fail(err.Error())
+
+		return
+	}
+
+	if skipped {
+		summary.Skipped++
+		return
+	}
+
+	summary.Imported++
+}
+
+// reconcile compares the balance reported by a statement with the balance of the account at the same time.
+func (s *ServiceImpl) reconcile(ctx context.Context, acc account.Account, currency string, balance LedgerBalance) (Reconciliation, error) {
+	ledgerBalance, err := s.ledgerService.GetBalance(ctx, acc.UserID, acc.ID, balance.AsOf)
+	if err != nil {
+		return Reconciliation{}, err
+	}
+
+	statementBalance := money.New(balance.Amount, currency)
+
+	difference, err := statementBalance.Sub(ledgerBalance.Balance)
+	if err != nil {
+		return Reconciliation{}, err
+	}
+
+	return Reconciliation{
+		AsOf:             balance.AsOf,
+		StatementBalance: statementBalance,
+		LedgerBalance:    ledgerBalance.Balance,
+		Difference:       difference,
+		Reconciled:       difference.IsZero(),
+	}, nil
+}
+
+// importAccounts returns the account to import into and its currency, after checking that the account holds
+// money of the user and that the accounts of the options are an income and an expense account in the same currency.
+func (s *ServiceImpl) importAccounts(ctx context.Context, userID, accountID uint, options ImportOptions) (account.Account, finasset.FinancialAsset, error) {
+	acc, err := s.accountService.GetByID(ctx, userID, accountID)
+	if err != nil {
+		return account.Account{}, finasset.FinancialAsset{}, err
+	}
+
+	if acc.Type.IsNominal() {
+		return account.Account{}, finasset.FinancialAsset{}, invalidImport("Statements can not be imported into income, expense or equity accounts")
+	}
+
+	counters := []struct {
+		id          uint
+		accountType account.AccountType
+	}{
+		{options.IncomeAccountID, account.Income},
+		{options.ExpenseAccountID, account.Expense},
+	}
+
+	for _, counter := range counters {
+		counterAcc, err := s.accountService.GetByID(ctx, userID, counter.id)
+		if err != nil {
+			return account.Account{}, finasset.FinancialAsset{}, err
+		}
+
+		if counterAcc.Type != counter.accountType {
+			return account.Account{}, finasset.FinancialAsset{}, invalidImport(fmt.Sprintf("The account %d must be an %s account", counter.id, counter.accountType))
+		}
+
+		if counterAcc.AssetID != acc.AssetID {
+			return account.Account{}, finasset.FinancialAsset{}, invalidImport(fmt.Sprintf("The account %d must be in the currency of the imported account", counter.id))
+		}
+	}
+
+	currency, err := s.finAssetService.GetByID(ctx, acc.AssetID)
+	if err != nil {
+		return account.Account{}, finasset.FinancialAsset{}, err
+	}
+
+	return acc, currency, nil
+}
+
+// ledgerTransaction builds the ledger transaction of a transaction of a statement.
+// The credits are incomes and the debits are expenses.
+func ledgerTransaction(acc account.Account, options ImportOptions, txn StatementTransaction) ledger.Transaction {
+	kind, counterID := ledger.Income, options.IncomeAccountID
+	if txn.Amount.IsNegative() {
+		kind, counterID = ledger.Expense, options.ExpenseAccountID
+	}
+
+	description := txn.Name
+	if description == "" {
+		description = txn.Memo
+	}
+
+	if description == "" {
+		description = txn.Type
+	}
+
+	return ledger.Transaction{
+		UserID:      acc.UserID,
+		Date:        txn.Date,
+		Kind:        kind,
+		Description: description,
+		Postings: []ledger.Posting{
+			{AccountID: acc.ID, Amount: money.Money{Amount: txn.Amount}, Memo: txn.Memo},
+			{AccountID: counterID, Amount: money.Money{Amount: txn.Amount.Neg()}, Memo: txn.Memo},
+		},
+	}
+}
+
+func invalidImport(desc string) error {
+	loggerService.WithError(ErrInvalidImport).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidImport)
+}
+
+func invalidProfile(desc string) error {
+	loggerService.WithError(ErrInvalidProfile).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidProfile)
+}
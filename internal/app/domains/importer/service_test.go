@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
+
+var errLinkFailed = errors.New("link failed error")
+
+// failingRepository fails the given number of links of the claimed external ids to their transactions.
+type failingRepository struct {
+	Repository
+	failures *int
+}
+
+func (r *failingRepository) WithTx(tx gorm.Gorm) Repository {
+	return &failingRepository{Repository: r.Repository.WithTx(tx), failures: r.failures}
+}
+
+func (r *failingRepository) SetTransaction(ctx context.Context, id, transactionID uint) error {
+	if *r.failures > 0 {
+		*r.failures--
+		return errLinkFailed
+	}
+
+	return r.Repository.SetTransaction(ctx, id, transactionID)
+}
+
+func TestImportCSVRetriesFailedRows(t *testing.T) {
+	ctx := context.Background()
+	db := gorm.NewMemoryGorm()
+
+	failures := 1
+	repo := &failingRepository{Repository: NewImporterRepository(db), failures: &failures}
+	service, ledgerService, ids := setupImporter(t, db, repo)
+
+	profile, err := service.CreateProfile(ctx, Profile{UserID: 1, Name: "Bank", HasHeader: true, DateColumn: "date", AmountColumn: "amount", PayeeColumn: "payee"})
+	if err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+
+	doc := "date,amount,payee\n2023-10-02,-42.50,Grocery\n2023-10-15,1500.00,Payroll\n"
+	options := ImportOptions{IncomeAccountID: ids[1], ExpenseAccountID: ids[2]}
+
+	tests := []struct {
+		name         string
+		want         Summary
+		wantLedgered int
+	}{
+		{name: "the link of the first row fails", want: Summary{Imported: 1, Failed: 1}, wantLedgered: 1},
+		{name: "the failed row is imported again", want: Summary{Imported: 1, Skipped: 1}, wantLedgered: 2},
+		{name: "both rows were imported", want: Summary{Skipped: 2}, wantLedgered: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := service.ImportCSV(ctx, 1, ids[0], profile.ID, options, strings.NewReader(doc))
+			if err != nil {
+				t.Fatalf("ImportCSV() error = %v", err)
+			}
+
+			if summary.Imported != tt.want.Imported || summary.Skipped != tt.want.Skipped || summary.Failed != tt.want.Failed {
+				t.Errorf("ImportCSV() = %+v, want %d imported, %d skipped and %d failed", summary, tt.want.Imported, tt.want.Skipped, tt.want.Failed)
+			}
+
+			// The ledger transaction of the failed row is rolled back with its claim.
+			txns, err := ledgerService.GetTransactions(ctx, 1, ledger.TransactionFilter{})
+			if err != nil || len(txns) != tt.wantLedgered {
+				t.Errorf("GetTransactions() = %d transactions, %v, want %d", len(txns), err, tt.wantLedgered)
+			}
+		})
+	}
+}
+
+// setupImporter creates the importer service on the database with a USD checking account of the user 1,
+// and the income and expense accounts to import into, returned in that order.
+func setupImporter(t *testing.T, db gorm.Gorm, repo Repository) (Service, ledger.Service, []uint) {
+	t.Helper()
+
+	ctx := context.Background()
+	finAssetService := finasset.NewFinAssetService(finasset.NewCurrencyRepository(db))
+	accountService := account.NewAccountService(account.NewAccountRepository(db), finAssetService)
+	ledgerService := ledger.NewLedgerService(ledger.NewLedgerRepository(db), accountService, finAssetService)
+
+	minorUnits := 2
+	usd := finasset.FinancialAsset{Symbol: "USD", Name: "US dollar", Type: finasset.Currency, Metadata: finasset.Metadata{NumericCode: "840", MinorUnits: &minorUnits}}
+
+	if err := finAssetService.Create(ctx, usd); err != nil {
+		t.Fatalf("Create() of the currency error = %v", err)
+	}
+
+	var ids []uint
+
+	for _, accountType := range []account.AccountType{account.Checking, account.Income, account.Expense} {
+		acc, err := accountService.Create(ctx, account.Account{UserID: 1, AssetID: 1, Name: string(accountType), Type: accountType})
+		if err != nil {
+			t.Fatalf("Create() of the %s account error = %v", accountType, err)
+		}
+
+		ids = append(ids, acc.ID)
+	}
+
+	return NewImporterService(db, repo, accountService, ledgerService, finAssetService), ledgerService, ids
+}
@@ -0,0 +1,132 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/shopspring/decimal"
+)
+
+var errCSV = errors.New("csv provider error")
+
+// csvColumns are the columns required in the header of the CSV files.
+var csvColumns = []string{"timestamp", "open", "high", "low", "close", "volume", "quote_currency"}
+
+// CSVProvider reads the prices from a directory with one <SYMBOL>.csv file per asset.
+// The files have a header with the columns timestamp (RFC3339), open, high, low, close, volume and quote_currency.
+type CSVProvider struct {
+	dir string
+}
+
+// NewCSVProvider creates a new provider that reads the CSV files of the directory.
+func NewCSVProvider(dir string) PriceProvider {
+	return &CSVProvider{dir: dir}
+}
+
+// Name returns the name of the provider.
+func (p *CSVProvider) Name() string {
+	return "csv"
+}
+
+// Fetch reads the prices of the asset after the given time from its CSV file.
+func (p *CSVProvider) Fetch(_ context.Context, asset finasset.FinancialAsset, since time.Time) ([]finasset.Price, error) {
+	// The symbol is part of the path, it must not be able to leave the directory.
+	if asset.Symbol == "" || strings.ContainsAny(asset.Symbol, `/\`) || strings.HasPrefix(asset.Symbol, ".") {
+		return nil, ErrSymbolNotFound
+	}
+
+	file, err := os.Open(filepath.Join(p.dir, asset.Symbol+".csv"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSymbolNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error opening the csv file", errCSV, err.Error())
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error reading the csv header", ErrPermanent, err.Error())
+	}
+
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	for _, column := range csvColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf(crosscuting.WrapLabel, "Missing csv column", ErrPermanent, column)
+		}
+	}
+
+	var prices []finasset.Price
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf(crosscuting.WrapLabel, "Error reading the csv file", ErrPermanent, err.Error())
+		}
+
+		price, err := parseCSVRecord(record, index)
+		if err != nil {
+			return nil, fmt.Errorf(crosscuting.WrapLabel, fmt.Sprintf("Error parsing the line %d of %s.csv", line, asset.Symbol), ErrPermanent, err.Error())
+		}
+
+		if !price.Timestamp.After(since) {
+			continue
+		}
+
+		price.Source = p.Name()
+		prices = append(prices, price)
+	}
+
+	return prices, nil
+}
+
+func parseCSVRecord(record []string, index map[string]int) (finasset.Price, error) {
+	field := func(column string) string {
+		return strings.TrimSpace(record[index[column]])
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, field("timestamp"))
+	if err != nil {
+		return finasset.Price{}, err
+	}
+
+	values := make(map[string]decimal.Decimal, 5)
+	for _, column := range []string{"open", "high", "low", "close", "volume"} {
+		value, err := decimal.NewFromString(field(column))
+		if err != nil {
+			return finasset.Price{}, fmt.Errorf("column %s: %w", column, err)
+		}
+
+		values[column] = value
+	}
+
+	return finasset.Price{
+		Timestamp:     timestamp,
+		Open:          values["open"],
+		High:          values["high"],
+		Low:           values["low"],
+		Close:         values["close"],
+		Volume:        values["volume"],
+		QuoteCurrency: field("quote_currency"),
+	}, nil
+}
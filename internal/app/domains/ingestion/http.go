@@ -0,0 +1,111 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/shopspring/decimal"
+)
+
+var errHTTP = errors.New("http provider error")
+
+// HTTPProvider fetches the prices from an HTTP API.
+// It calls GET {baseURL}/prices/{symbol}?since={RFC3339} and expects {"prices": [...]} in the response.
+type HTTPProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+type httpPrice struct {
+	Timestamp     time.Time       `json:"timestamp"`
+	Open          decimal.Decimal `json:"open"`
+	High          decimal.Decimal `json:"high"`
+	Low           decimal.Decimal `json:"low"`
+	Close         decimal.Decimal `json:"close"`
+	Volume        decimal.Decimal `json:"volume"`
+	QuoteCurrency string          `json:"quote_currency"`
+}
+
+type httpPricesResponse struct {
+	Prices []httpPrice `json:"prices"`
+}
+
+// NewHTTPProvider creates a new provider for the API in the base URL.
+func NewHTTPProvider(baseURL string, timeout time.Duration) PriceProvider {
+	return &HTTPProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the name of the provider.
+func (p *HTTPProvider) Name() string {
+	return "http"
+}
+
+// Fetch requests the prices of the asset after the given time.
+// Server errors and rate limits can be retried, other client errors and prices without quote currency are permanent.
+// The prices not after the given time are skipped, in case the API ignores the since parameter.
+func (p *HTTPProvider) Fetch(ctx context.Context, asset finasset.FinancialAsset, since time.Time) ([]finasset.Price, error) {
+	endpoint := fmt.Sprintf("%s/prices/%s", p.baseURL, url.PathEscape(asset.Symbol))
+	if !since.IsZero() {
+		endpoint += "?since=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error creating the request", ErrPermanent, err.Error())
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error calling the provider", errHTTP, err.Error())
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrSymbolNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Unexpected status from the provider", errHTTP, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Unexpected status from the provider", ErrPermanent, resp.Status)
+	}
+
+	var body httpPricesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error decoding the response", ErrPermanent, err.Error())
+	}
+
+	prices := make([]finasset.Price, 0, len(body.Prices))
+	for i, price := range body.Prices {
+		if price.QuoteCurrency == "" {
+			return nil, fmt.Errorf(crosscuting.WrapLabel, "Invalid price from the provider", ErrPermanent, fmt.Sprintf("the price %d has no quote currency", i))
+		}
+
+		if !price.Timestamp.After(since) {
+			continue
+		}
+
+		prices = append(prices, finasset.Price{
+			Timestamp:     price.Timestamp,
+			Open:          price.Open,
+			High:          price.High,
+			Low:           price.Low,
+			Close:         price.Close,
+			Volume:        price.Volume,
+			Source:        p.Name(),
+			QuoteCurrency: price.QuoteCurrency,
+		})
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,86 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+)
+
+func TestHTTPProviderFetch(t *testing.T) {
+	since := time.Date(2023, time.October, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantClose  []string
+		wantErr    bool
+		wantStatus int
+	}{
+		{
+			name: "prices not after since are skipped",
+			body: `{"prices": [
+				{"timestamp": "2023-10-01T00:00:00Z", "open": "1", "high": "1", "low": "1", "close": "1", "volume": "0", "quote_currency": "USD"},
+				{"timestamp": "2023-10-02T00:00:00Z", "open": "2", "high": "2", "low": "2", "close": "2", "volume": "0", "quote_currency": "USD"},
+				{"timestamp": "2023-10-03T00:00:00Z", "open": "3", "high": "3", "low": "3", "close": "3", "volume": "0", "quote_currency": "USD"}
+			]}`,
+			wantClose: []string{"3"},
+		},
+		{
+			name: "price without quote currency",
+			body: `{"prices": [
+				{"timestamp": "2023-10-03T00:00:00Z", "open": "3", "high": "3", "low": "3", "close": "3", "volume": "0", "quote_currency": ""}
+			]}`,
+			wantErr: true,
+		},
+		{name: "malformed response", body: `{"prices": `, wantErr: true},
+		{name: "client error", wantStatus: http.StatusBadRequest, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/prices/BTC" || r.URL.Query().Get("since") != "2023-10-02T00:00:00Z" {
+					t.Errorf("request to %s, want /prices/BTC since 2023-10-02T00:00:00Z", r.URL)
+				}
+
+				if tt.wantStatus != 0 {
+					w.WriteHeader(tt.wantStatus)
+					return
+				}
+
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewHTTPProvider(server.URL, time.Second)
+
+			prices, err := provider.Fetch(context.Background(), finasset.FinancialAsset{Symbol: "BTC"}, since)
+			if tt.wantErr {
+				if !errors.Is(err, ErrPermanent) {
+					t.Errorf("Fetch() error = %v, want %v", err, ErrPermanent)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+
+			if len(prices) != len(tt.wantClose) {
+				t.Fatalf("Fetch() = %d prices, want %d", len(prices), len(tt.wantClose))
+			}
+
+			for i, price := range prices {
+				if price.Close.String() != tt.wantClose[i] || price.Source != "http" {
+					t.Errorf("Fetch() price %d = close %s from %s, want %s from http", i, price.Close, price.Source, tt.wantClose[i])
+				}
+			}
+		})
+	}
+}
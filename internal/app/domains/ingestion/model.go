@@ -0,0 +1,25 @@
+package ingestion
+
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
+
+type (
+	// Fetch is the struct for the record of the last price fetch of an asset from a provider.
+	Fetch struct {
+		gorm.Model
+		AssetID       uint       `json:"asset_id" gorm:"not null"`
+		Provider      string     `json:"provider" gorm:"not null"`
+		LastAttemptAt time.Time  `json:"last_attempt_at" gorm:"not null"`
+		LastSuccessAt *time.Time `json:"last_success_at"`
+		LastPriceAt   *time.Time `json:"last_price_at"`
+		LastError     string     `json:"last_error" gorm:"not null"`
+	}
+)
+
+// TableName overrides the table name of the fetches.
+func (Fetch) TableName() string {
+	return "price_fetches"
+}
@@ -0,0 +1,78 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+)
+
+var (
+	// ErrSymbolNotFound is returned by the providers that do not have prices for the asset.
+	ErrSymbolNotFound = errors.New("symbol not found in provider error")
+	// ErrPermanent is wrapped by the provider errors that must not be retried.
+	ErrPermanent = errors.New("permanent provider error")
+)
+
+// PriceProvider is the interface for the sources of asset prices.
+type PriceProvider interface {
+	// Name identifies the provider, it is stored as the source of the prices.
+	Name() string
+	// Fetch returns the prices of the asset after the given time, all of them when since is zero.
+	Fetch(ctx context.Context, asset finasset.FinancialAsset, since time.Time) ([]finasset.Price, error)
+}
+
+// ProviderConfig is the struct for a provider and the settings to call it.
+type ProviderConfig struct {
+	Provider PriceProvider
+	// RateLimit is the minimum time between two calls to the provider, zero means no limit.
+	RateLimit time.Duration
+	// MaxRetries is the number of retries after a failed call.
+	MaxRetries int
+	// Backoff is the wait before the first retry, it is doubled on every retry.
+	Backoff time.Duration
+}
+
+// rateLimiter spaces the calls to a provider by a minimum interval.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// Wait blocks until the next call is allowed or the context is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	wait := l.next.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	l.next = now.Add(wait + l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, wait)
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
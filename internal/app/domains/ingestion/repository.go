@@ -0,0 +1,62 @@
+package ingestion
+
+import (
+	"context"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.ingestion.repository")
+
+// Repository is the interface for the price fetches repository.
+type Repository interface {
+	GetFetch(ctx context.Context, assetID uint, provider string) (Fetch, bool, error)
+	SaveFetch(ctx context.Context, fetch Fetch) error
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the price fetches repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewIngestionRepository creates a new price fetches repository.
+func NewIngestionRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// GetFetch returns the last fetch of the asset from the provider.
+// The bool result reports if the asset was fetched before.
+func (r *RepositoryImpl) GetFetch(ctx context.Context, assetID uint, provider string) (Fetch, bool, error) {
+	var fetches []Fetch
+	if err := r.db.WhereFind(ctx, &fetches, "asset_id = ? AND provider = ?", assetID, provider); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the fetch of the asset")
+
+		return Fetch{}, false, err
+	}
+
+	if len(fetches) == 0 {
+		return Fetch{}, false, nil
+	}
+
+	return fetches[0], true, nil
+}
+
+// SaveFetch creates or overwrites the fetch of the asset from the provider.
+func (r *RepositoryImpl) SaveFetch(ctx context.Context, fetch Fetch) error {
+	if err := r.db.Upsert(ctx, &fetch,
+		[]string{"asset_id", "provider"},
+		[]string{"last_attempt_at", "last_success_at", "last_price_at", "last_error", "updated_at"},
+	); err != nil {
+		loggerRepo.WithError(err).Error("Error saving the fetch in the database")
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerScheduler    = logger.Setup("domain.ingestion.scheduler")
+	errInvalidInterval = errors.New("invalid interval error")
+)
+
+// Scheduler is the interface for the periodic ingestion of prices.
+type Scheduler interface {
+	Run(ctx context.Context)
+	RunOnce(ctx context.Context)
+}
+
+// SchedulerImpl is the struct that contains the providers and the dependencies of the ingestion.
+type SchedulerImpl struct {
+	finAssetService finasset.Service
+	repo            Repository
+	interval        time.Duration
+	providers       []providerRunner
+}
+
+// providerRunner is a provider with its own rate limiter.
+type providerRunner struct {
+	ProviderConfig
+	limiter *rateLimiter
+}
+
+// NewScheduler creates a new ingestion scheduler that runs every interval, which must be positive.
+func NewScheduler(finAssetService finasset.Service, repo Repository, interval time.Duration, providers ...ProviderConfig) (Scheduler, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("The ingestion interval %s must be positive", interval), errInvalidInterval)
+	}
+
+	runners := make([]providerRunner, len(providers))
+	for i, config := range providers {
+		runners[i] = providerRunner{ProviderConfig: config, limiter: &rateLimiter{interval: config.RateLimit}}
+	}
+
+	return &SchedulerImpl{
+		finAssetService: finAssetService,
+		repo:            repo,
+		interval:        interval,
+		providers:       runners,
+	}, nil
+}
+
+// Run ingests the prices right away and then every interval until the context is done.
+func (s *SchedulerImpl) Run(ctx context.Context) {
+	loggerScheduler.Infof("Starting the price ingestion every %s with %d providers", s.interval, len(s.providers))
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			loggerScheduler.Info("Stopping the price ingestion")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce ingests the prices of all the active assets from all the providers.
+func (s *SchedulerImpl) RunOnce(ctx context.Context) {
+	assets, err := s.activeAssets(ctx)
+	if err != nil {
+		loggerScheduler.WithError(err).Error("Error getting the active assets")
+		return
+	}
+
+	for _, runner := range s.providers {
+		for _, asset := range assets {
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.ingest(ctx, runner, asset)
+		}
+	}
+}
+
+// activeAssets returns all the financial assets that are not deleted, walking all the pages.
+func (s *SchedulerImpl) activeAssets(ctx context.Context) ([]finasset.FinancialAsset, error) {
+	var (
+		assets []finasset.FinancialAsset
+		cursor string
+	)
+
+	for {
+		filter := finasset.FinancialAsset{}
+
+		pagination, err := crosscuting.NewPagination(crosscuting.MaxLimit, cursor, crosscuting.SortByID, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := s.finAssetService.Get(ctx, filter, pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, page.Items...)
+
+		if page.NextCursor == "" {
+			return assets, nil
+		}
+
+		cursor = page.NextCursor
+	}
+}
+
+// ingest fetches the new prices of the asset from the provider, stores them and records the fetch.
+func (s *SchedulerImpl) ingest(ctx context.Context, runner providerRunner, asset finasset.FinancialAsset) {
+	name := runner.Provider.Name()
+	log := loggerScheduler.WithField("block", name+":"+asset.Symbol)
+
+	fetch, _, err := s.repo.GetFetch(ctx, asset.ID, name)
+	if err != nil {
+		log.WithError(err).Error("Error getting the last fetch")
+		return
+	}
+
+	var since time.Time
+	if fetch.LastPriceAt != nil {
+		since = *fetch.LastPriceAt
+	}
+
+	fetch.AssetID = asset.ID
+	fetch.Provider = name
+	fetch.LastAttemptAt = time.Now()
+
+	prices, err := s.fetchWithRetries(ctx, runner, asset, since)
+	if errors.Is(err, ErrSymbolNotFound) {
+		return
+	}
+
+	if err == nil {
+		err = s.storePrices(ctx, asset.ID, prices)
+	}
+
+	if err != nil {
+		log.WithError(err).Error("Error ingesting the prices")
+		fetch.LastError = err.Error()
+	} else {
+		// The newest price is the start of the next fetch.
+		lastPrice := latestTimestamp(prices, since)
+		fetch.LastSuccessAt = &fetch.LastAttemptAt
+		fetch.LastPriceAt = &lastPrice
+		fetch.LastError = ""
+		log.Infof("Ingested %d prices", len(prices))
+	}
+
+	if err := s.repo.SaveFetch(ctx, fetch); err != nil {
+		log.WithError(err).Error("Error saving the fetch")
+	}
+}
+
+// fetchWithRetries calls the provider respecting its rate limit and retrying with exponential backoff.
+func (s *SchedulerImpl) fetchWithRetries(ctx context.Context, runner providerRunner, asset finasset.FinancialAsset, since time.Time) ([]finasset.Price, error) {
+	backoff := runner.Backoff
+
+	for attempt := 0; ; attempt++ {
+		if err := runner.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		prices, err := runner.Provider.Fetch(ctx, asset, since)
+		if err == nil || errors.Is(err, ErrSymbolNotFound) || errors.Is(err, ErrPermanent) || attempt >= runner.MaxRetries {
+			return prices, err
+		}
+
+		loggerScheduler.WithError(err).Warnf("Retrying the fetch of %s from %s in %s", asset.Symbol, runner.Provider.Name(), backoff)
+
+		if err := sleep(ctx, backoff); err != nil {
+			return nil, err
+		}
+
+		backoff *= 2
+	}
+}
+
+// storePrices upserts the prices in batches of the maximum size allowed.
+func (s *SchedulerImpl) storePrices(ctx context.Context, assetID uint, prices []finasset.Price) error {
+	for start := 0; start < len(prices); start += finasset.MaxPricesBatch {
+		end := start + finasset.MaxPricesBatch
+		if end > len(prices) {
+			end = len(prices)
+		}
+
+		if err := s.finAssetService.UpsertPrices(ctx, assetID, prices[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// latestTimestamp returns the newest timestamp of the prices, or the given default if there are none.
+func latestTimestamp(prices []finasset.Price, latest time.Time) time.Time {
+	for _, price := range prices {
+		if price.Timestamp.After(latest) {
+			latest = price.Timestamp
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,88 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
+
+const (
+	// Income is the kind for the money received by the user.
+	Income TransactionKind = "income"
+	// Expense is the kind for the money spent by the user.
+	Expense TransactionKind = "expense"
+	// Transfer is the kind for the money moved between accounts of the user.
+	Transfer TransactionKind = "transfer"
+	// Adjustment is the kind for the corrections of the balances.
+	Adjustment TransactionKind = "adjustment"
+)
+
+type (
+	// TransactionKind can be income, expense, transfer or adjustment.
+	TransactionKind string
+
+	// Transaction is the struct for a double-entry transaction of a user.
+	// The amounts of its postings sum zero per currency.
+	Transaction struct {
+		gorm.Model
+		UserID      uint            `json:"user_id" gorm:"not null"`
+		Date        time.Time       `json:"date" gorm:"not null"`
+		Kind        TransactionKind `json:"kind" gorm:"not null"`
+		Description string          `json:"description" gorm:"not null"`
+		CategoryID  *uint           `json:"category_id"`
+		Postings    []Posting       `json:"postings" gorm:"-"`
+	}
+
+	// Posting is the struct for the movement of an account in a transaction.
+	// Positive amounts increase the balance of the account and negative amounts decrease it.
+	Posting struct {
+		gorm.Model
+		TransactionID uint        `json:"transaction_id" gorm:"not null"`
+		AccountID     uint        `json:"account_id" gorm:"not null"`
+		Date          time.Time   `json:"date" gorm:"not null"`
+		Amount        money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+		Memo          string      `json:"memo" gorm:"not null"`
+	}
+
+	// TransactionFilter is the struct for the filters of the transactions of a user, empty fields do not filter.
+	TransactionFilter struct {
+		Kind        TransactionKind
+		CategoryIDs []uint
+		From        time.Time
+		To          time.Time
+	}
+
+	// Balance is the struct for the balance of an account at a date.
+	Balance struct {
+		AccountID uint        `json:"account_id"`
+		AsOf      time.Time   `json:"as_of"`
+		Balance   money.Money `json:"balance"`
+	}
+
+	// Entry is the struct for a posting with the running balance of its account after it.
+	Entry struct {
+		Posting
+		Balance money.Money `json:"balance"`
+	}
+)
+
+// TableName overrides the table name of the transactions.
+func (Transaction) TableName() string {
+	return "ledger_transactions"
+}
+
+// TableName overrides the table name of the postings.
+func (Posting) TableName() string {
+	return "ledger_postings"
+}
+
+// IsValid reports if the transaction kind is supported.
+func (k TransactionKind) IsValid() bool {
+	switch k {
+	case Income, Expense, Transfer, Adjustment:
+		return true
+	default:
+		return false
+	}
+}
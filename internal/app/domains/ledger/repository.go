@@ -0,0 +1,201 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.ledger.repository")
+
+// Repository is the interface for the ledger repository.
+type Repository interface {
+	CreateTransaction(ctx context.Context, txn Transaction) (Transaction, error)
+	GetTransaction(ctx context.Context, userID, id uint) (Transaction, error)
+	GetTransactions(ctx context.Context, userID uint, filter TransactionFilter) ([]Transaction, error)
+	DeleteTransaction(ctx context.Context, userID, id uint) error
+	GetPostings(ctx context.Context, accountID uint, from, to time.Time) ([]Posting, error)
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the ledger repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewLedgerRepository creates a new ledger repository.
+func NewLedgerRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// CreateTransaction creates the transaction and its postings atomically and returns them with their ids.
+func (r *RepositoryImpl) CreateTransaction(ctx context.Context, txn Transaction) (Transaction, error) {
+	postings := txn.Postings
+
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		if err := tx.Create(ctx, &txn); err != nil {
+			return err
+		}
+
+		for i := range postings {
+			postings[i].TransactionID = txn.ID
+			postings[i].Date = txn.Date
+		}
+
+		return tx.Create(ctx, &postings)
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error creating the transaction in the database")
+
+		return Transaction{}, err
+	}
+
+	txn.Postings = postings
+
+	return txn, nil
+}
+
+// GetTransaction returns the transaction of the user with its postings.
+func (r *RepositoryImpl) GetTransaction(ctx context.Context, userID, id uint) (Transaction, error) {
+	var txn Transaction
+	if err := r.db.WhereFirst(ctx, &txn, "user_id = ? AND id = ?", userID, id); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the transaction by id")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return Transaction{}, fmt.Errorf(crosscuting.WrapLabel, "Transaction not found", ErrTransactionNotFound, err.Error())
+		}
+
+		return Transaction{}, err
+	}
+
+	if err := r.db.Order("id ASC").WhereFind(ctx, &txn.Postings, "transaction_id = ?", txn.ID); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the postings of the transaction")
+
+		return Transaction{}, err
+	}
+
+	return txn, nil
+}
+
+// GetTransactions returns the transactions of the user given filters with their postings, sorted by date and id.
+func (r *RepositoryImpl) GetTransactions(ctx context.Context, userID uint, filter TransactionFilter) ([]Transaction, error) {
+	var txns []Transaction
+
+	queryConditions := []string{"user_id = ?"}
+	args := []interface{}{userID}
+
+	if filter.Kind != "" {
+		queryConditions = append(queryConditions, "kind = ?")
+		args = append(args, filter.Kind)
+	}
+
+	if filter.CategoryIDs != nil {
+		queryConditions = append(queryConditions, "category_id IN ?")
+		args = append(args, filter.CategoryIDs)
+	}
+
+	if !filter.From.IsZero() {
+		queryConditions = append(queryConditions, "date >= ?")
+		args = append(args, filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		queryConditions = append(queryConditions, "date <= ?")
+		args = append(args, filter.To)
+	}
+
+	if err := r.db.Order("date ASC, id ASC").WhereFind(ctx, &txns, strings.Join(queryConditions, " AND "), args...); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the transactions from the database")
+
+		return nil, err
+	}
+
+	if len(txns) == 0 {
+		return txns, nil
+	}
+
+	ids := make([]uint, len(txns))
+	index := make(map[uint]int, len(txns))
+
+	for i, txn := range txns {
+		ids[i] = txn.ID
+		index[txn.ID] = i
+	}
+
+	var postings []Posting
+	if err := r.db.Order("id ASC").WhereFind(ctx, &postings, "transaction_id IN ?", ids); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the postings of the transactions from the database")
+
+		return nil, err
+	}
+
+	for _, posting := range postings {
+		i := index[posting.TransactionID]
+		txns[i].Postings = append(txns[i].Postings, posting)
+	}
+
+	return txns, nil
+}
+
+// DeleteTransaction soft deletes the transaction of the user and its postings atomically.
+func (r *RepositoryImpl) DeleteTransaction(ctx context.Context, userID, id uint) error {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		rows, err := tx.WhereDelete(ctx, &Transaction{}, "user_id = ? AND id = ?", userID, id)
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Transaction not found", ErrTransactionNotFound)
+		}
+
+		_, err = tx.WhereDelete(ctx, &Posting{}, "transaction_id = ?", id)
+
+		return err
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error deleting the transaction in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// GetPostings returns the postings of the account between the dates, sorted by date and id.
+// Zero dates do not limit the range.
+func (r *RepositoryImpl) GetPostings(ctx context.Context, accountID uint, from, to time.Time) ([]Posting, error) {
+	var postings []Posting
+
+	queryConditions := []string{"account_id = ?"}
+	args := []interface{}{accountID}
+
+	if !from.IsZero() {
+		queryConditions = append(queryConditions, "date >= ?")
+		args = append(args, from)
+	}
+
+	if !to.IsZero() {
+		queryConditions = append(queryConditions, "date <= ?")
+		args = append(args, to)
+	}
+
+	if err := r.db.Order("date ASC, id ASC").WhereFind(ctx, &postings, strings.Join(queryConditions, " AND "), args...); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the postings from the database")
+
+		return nil, err
+	}
+
+	return postings, nil
+}
@@ -0,0 +1,295 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerService = logger.Setup("domain.ledger.service")
+	// ErrTransactionNotFound is returned when the transaction does not exist or belongs to another user.
+	ErrTransactionNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "TRANSACTION_NOT_FOUND", "transaction not found error")
+	// ErrUnbalancedTransaction is returned when the postings of a transaction do not sum zero per currency.
+	ErrUnbalancedTransaction = crosscuting.NewTypedError(crosscuting.ErrValidation, "UNBALANCED_TRANSACTION", "unbalanced transaction error")
+	// ErrInvalidTransaction is returned when the fields of the transaction are not valid.
+	ErrInvalidTransaction = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_TRANSACTION", "invalid transaction error")
+)
+
+// Service is the interface for the ledger service.
+type Service interface {
+	CreateTransaction(ctx context.Context, txn Transaction) (Transaction, error)
+	ValidateTransaction(ctx context.Context, txn Transaction) (Transaction, error)
+	GetTransaction(ctx context.Context, userID, id uint) (Transaction, error)
+	GetTransactions(ctx context.Context, userID uint, filter TransactionFilter) ([]Transaction, error)
+	DeleteTransaction(ctx context.Context, userID, id uint) error
+	GetBalance(ctx context.Context, userID, accountID uint, asOf time.Time) (Balance, error)
+	GetEntries(ctx context.Context, userID, accountID uint, from, to time.Time) ([]Entry, error)
+	GetAccountBalance(ctx context.Context, acc account.Account, asOf time.Time) (Balance, error)
+	GetAccountEntries(ctx context.Context, acc account.Account, from, to time.Time) ([]Entry, error)
+
+	WithTx(tx gorm.Gorm) Service
+}
+
+// ServiceImpl is the struct that contains the ledger service.
+type ServiceImpl struct {
+	repo            Repository
+	accountService  account.Service
+	finAssetService finasset.Service
+}
+
+// NewLedgerService creates a new ledger service.
+func NewLedgerService(repo Repository, accountService account.Service, finAssetService finasset.Service) Service {
+	return &ServiceImpl{repo: repo, accountService: accountService, finAssetService: finAssetService}
+}
+
+// WithTx returns the service with its repository bound to the transaction, so the transactions it creates
+// are committed or rolled back with the rest of the unit of work.
+func (s *ServiceImpl) WithTx(tx gorm.Gorm) Service {
+	return &ServiceImpl{repo: s.repo.WithTx(tx), accountService: s.accountService, finAssetService: s.finAssetService}
+}
+
+// CreateTransaction validates and stores a transaction of the user.
+func (s *ServiceImpl) CreateTransaction(ctx context.Context, txn Transaction) (Transaction, error) {
+	txn, err := s.ValidateTransaction(ctx, txn)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	created, err := s.repo.CreateTransaction(ctx, txn)
+	if err != nil {
+		loggerService.WithError(err).Error("Error creating the transaction")
+		return Transaction{}, err
+	}
+
+	return created, nil
+}
+
+// ValidateTransaction checks a transaction of the user and returns it with the amounts in the currency of each account.
+// It must have two or more postings in open accounts of the user, in the currency of each account,
+// without more decimals than the minor units of the currency, and summing zero per currency.
+func (s *ServiceImpl) ValidateTransaction(ctx context.Context, txn Transaction) (Transaction, error) {
+	if !txn.Kind.IsValid() {
+		return Transaction{}, invalidTransaction(fmt.Sprintf("Unknown transaction kind %s", txn.Kind))
+	}
+
+	if len(txn.Postings) < 2 {
+		return Transaction{}, invalidTransaction("A transaction needs two or more postings")
+	}
+
+	if txn.Date.IsZero() {
+		txn.Date = time.Now()
+	}
+
+	totals := map[string]money.Money{}
+
+	for i, posting := range txn.Postings {
+		acc, err := s.accountService.GetByID(ctx, txn.UserID, posting.AccountID)
+		if err != nil {
+			return Transaction{}, err
+		}
+
+		if acc.Status != account.Open {
+			return Transaction{}, invalidTransaction(fmt.Sprintf("The account %d is closed", acc.ID))
+		}
+
+		asset, err := s.finAssetService.GetByID(ctx, acc.AssetID)
+		if err != nil {
+			return Transaction{}, err
+		}
+
+		amount := money.New(posting.Amount.Amount, asset.Symbol)
+
+		if amount.IsZero() {
+			return Transaction{}, invalidTransaction(fmt.Sprintf("The posting of the account %d has a zero amount", acc.ID))
+		}
+
+		if !amount.Round(asset.MinorUnits()).Amount.Equal(amount.Amount) {
+			return Transaction{}, invalidTransaction(fmt.Sprintf("The amount %s has more than %d decimals", amount, asset.MinorUnits()))
+		}
+
+		total, ok := totals[asset.Symbol]
+		if !ok {
+			total = money.Zero(asset.Symbol)
+		}
+
+		if totals[asset.Symbol], err = total.Add(amount); err != nil {
+			return Transaction{}, err
+		}
+
+		txn.Postings[i].Amount = amount
+	}
+
+	if err := checkBalanced(totals); err != nil {
+		return Transaction{}, err
+	}
+
+	return txn, nil
+}
+
+// GetTransaction returns the transaction of the user with its postings.
+func (s *ServiceImpl) GetTransaction(ctx context.Context, userID, id uint) (Transaction, error) {
+	txn, err := s.repo.GetTransaction(ctx, userID, id)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the transaction from the repo")
+		return Transaction{}, err
+	}
+
+	return txn, nil
+}
+
+// GetTransactions returns the transactions of the user given filters with their postings.
+// A non nil empty list of categories does not match any transaction.
+func (s *ServiceImpl) GetTransactions(ctx context.Context, userID uint, filter TransactionFilter) ([]Transaction, error) {
+	if filter.Kind != "" && !filter.Kind.IsValid() {
+		return nil, invalidTransaction(fmt.Sprintf("Unknown transaction kind %s", filter.Kind))
+	}
+
+	if filter.CategoryIDs != nil && len(filter.CategoryIDs) == 0 {
+		return []Transaction{}, nil
+	}
+
+	txns, err := s.repo.GetTransactions(ctx, userID, filter)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the transactions from the repo")
+		return nil, err
+	}
+
+	return txns, nil
+}
+
+// DeleteTransaction soft deletes the transaction of the user with its postings.
+func (s *ServiceImpl) DeleteTransaction(ctx context.Context, userID, id uint) error {
+	if err := s.repo.DeleteTransaction(ctx, userID, id); err != nil {
+		loggerService.WithError(err).Error("Error deleting the transaction")
+		return err
+	}
+
+	return nil
+}
+
+// GetBalance returns the balance of the account of the user at the end of the given time.
+// It is the postings up to that time, plus the opening balance if the account was opened by then.
+func (s *ServiceImpl) GetBalance(ctx context.Context, userID, accountID uint, asOf time.Time) (Balance, error) {
+	acc, err := s.accountService.GetByID(ctx, userID, accountID)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	return s.GetAccountBalance(ctx, acc, asOf)
+}
+
+// GetAccountBalance returns the balance of an account already loaded at the end of the given time,
+// which can be a soft deleted account of the history.
+func (s *ServiceImpl) GetAccountBalance(ctx context.Context, acc account.Account, asOf time.Time) (Balance, error) {
+	postings, err := s.repo.GetPostings(ctx, acc.ID, time.Time{}, asOf)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the postings from the repo")
+		return Balance{}, err
+	}
+
+	balance := money.Zero(acc.OpeningBalance.Currency)
+	if !asOf.Before(acc.OpeningDate) {
+		balance = acc.OpeningBalance
+	}
+
+	for _, posting := range postings {
+		if balance, err = balance.Add(posting.Amount); err != nil {
+			return Balance{}, err
+		}
+	}
+
+	return Balance{AccountID: acc.ID, AsOf: asOf, Balance: balance}, nil
+}
+
+// GetEntries returns the postings of the account of the user between the dates with the running balance after each one.
+// The opening balance is added to the running balance from the opening date of the account.
+func (s *ServiceImpl) GetEntries(ctx context.Context, userID, accountID uint, from, to time.Time) ([]Entry, error) {
+	acc, err := s.accountService.GetByID(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAccountEntries(ctx, acc, from, to)
+}
+
+// GetAccountEntries returns the postings of an account already loaded between the dates with the running balance,
+// which can be a soft deleted account of the history.
+func (s *ServiceImpl) GetAccountEntries(ctx context.Context, acc account.Account, from, to time.Time) ([]Entry, error) {
+	var err error
+
+	balance := Balance{Balance: money.Zero(acc.OpeningBalance.Currency)}
+	opened := false
+
+	if !from.IsZero() {
+		if balance, err = s.GetAccountBalance(ctx, acc, from.Add(-time.Nanosecond)); err != nil {
+			return nil, err
+		}
+
+		opened = !from.Add(-time.Nanosecond).Before(acc.OpeningDate)
+	}
+
+	postings, err := s.repo.GetPostings(ctx, acc.ID, from, to)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the postings from the repo")
+		return nil, err
+	}
+
+	running := balance.Balance
+	entries := make([]Entry, 0, len(postings))
+
+	for _, posting := range postings {
+		if !opened && !posting.Date.Before(acc.OpeningDate) {
+			if running, err = running.Add(acc.OpeningBalance); err != nil {
+				return nil, err
+			}
+
+			opened = true
+		}
+
+		if running, err = running.Add(posting.Amount); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{Posting: posting, Balance: running})
+	}
+
+	return entries, nil
+}
+
+// checkBalanced returns an error listing the currencies whose postings do not sum zero.
+func checkBalanced(totals map[string]money.Money) error {
+	var unbalanced []string
+
+	for _, total := range totals {
+		if !total.IsZero() {
+			unbalanced = append(unbalanced, total.String())
+		}
+	}
+
+	if len(unbalanced) == 0 {
+		return nil
+	}
+
+	sort.Strings(unbalanced)
+
+	desc := "The postings do not sum zero: " + strings.Join(unbalanced, ", ")
+	loggerService.WithError(ErrUnbalancedTransaction).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrUnbalancedTransaction)
+}
+
+func invalidTransaction(desc string) error {
+	loggerService.WithError(ErrInvalidTransaction).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidTransaction)
+}
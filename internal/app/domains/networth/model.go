@@ -0,0 +1,123 @@
+package networth
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
+
+const (
+	// Daily groups the history by day.
+	Daily Interval = "daily"
+	// Weekly groups the history by week, starting on monday.
+	Weekly Interval = "weekly"
+	// Monthly groups the history by calendar month.
+	Monthly Interval = "monthly"
+
+	// CashClass is the asset class of the balances of the accounts denominated in currencies.
+	CashClass = "cash"
+	// DebtClass is the asset class of the balances of the liability accounts.
+	DebtClass = "debt"
+)
+
+var errScanBreakdown = errors.New("scan breakdown error")
+
+type (
+	// Interval can be daily, weekly or monthly.
+	Interval string
+
+	// Breakdown is the net worth of a user split by a key, stored as JSON.
+	Breakdown map[string]money.Money
+
+	// Snapshot is the struct for the net worth of a user at the end of a day, in the currency of the user.
+	// The liabilities are the amount owed, so the net worth is the assets minus the liabilities.
+	Snapshot struct {
+		gorm.Model
+		UserID        uint        `json:"user_id" gorm:"not null"`
+		Date          time.Time   `json:"date" gorm:"not null"`
+		Assets        money.Money `json:"assets" gorm:"embedded;embeddedPrefix:assets_"`
+		Liabilities   money.Money `json:"liabilities" gorm:"embedded;embeddedPrefix:liabilities_"`
+		NetWorth      money.Money `json:"net_worth" gorm:"embedded;embeddedPrefix:net_worth_"`
+		ByAccountType Breakdown   `json:"by_account_type" gorm:"type:jsonb;not null"`
+		ByAssetClass  Breakdown   `json:"by_asset_class" gorm:"type:jsonb;not null"`
+	}
+)
+
+// TableName overrides the table name of the snapshots.
+func (Snapshot) TableName() string {
+	return "net_worth_snapshots"
+}
+
+// IsValid reports if the interval is supported.
+func (i Interval) IsValid() bool {
+	return i == Daily || i == Weekly || i == Monthly
+}
+
+// bucket returns the start of the interval that contains the date.
+func (i Interval) bucket(date time.Time) time.Time {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	switch i {
+	case Weekly:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case Monthly:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// Value implements the driver.Valuer interface to store the breakdown as JSON.
+func (b Breakdown) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(bytes), nil
+}
+
+// Scan implements the sql.Scanner interface to read the breakdown from JSON.
+func (b *Breakdown) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*b = Breakdown{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, b)
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	default:
+		return fmt.Errorf("%w: unsupported type %T", errScanBreakdown, value)
+	}
+}
+
+// add adds the amount to the key of the breakdown.
+func (b Breakdown) add(key string, amount money.Money) error {
+	current, ok := b[key]
+	if !ok {
+		b[key] = amount
+		return nil
+	}
+
+	sum, err := current.Add(amount)
+	if err != nil {
+		return err
+	}
+
+	b[key] = sum
+
+	return nil
+}
+
+// day returns the start of the day of the date in UTC.
+func day(date time.Time) time.Time {
+	date = date.UTC()
+
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
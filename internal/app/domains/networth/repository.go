@@ -0,0 +1,80 @@
+package networth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.networth.repository")
+
+// Repository is the interface for the net worth repository.
+type Repository interface {
+	Upsert(ctx context.Context, snapshot Snapshot) error
+	Get(ctx context.Context, userID uint, from, to time.Time) ([]Snapshot, error)
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the net worth repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewNetWorthRepository creates a new net worth repository.
+func NewNetWorthRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// Upsert creates the snapshot, overwriting the one of the same user and date.
+func (r *RepositoryImpl) Upsert(ctx context.Context, snapshot Snapshot) error {
+	if err := r.db.Upsert(ctx, &snapshot,
+		[]string{"user_id", "date"},
+		[]string{
+			"assets_amount", "assets_currency",
+			"liabilities_amount", "liabilities_currency",
+			"net_worth_amount", "net_worth_currency",
+			"by_account_type", "by_asset_class", "updated_at",
+		},
+	); err != nil {
+		loggerRepo.WithError(err).Error("Error upserting the snapshot in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// Get returns the snapshots of the user between the dates, both inclusive, sorted by date.
+// Zero dates do not limit the range.
+func (r *RepositoryImpl) Get(ctx context.Context, userID uint, from, to time.Time) ([]Snapshot, error) {
+	var snapshots []Snapshot
+
+	queryConditions := []string{"user_id = ?"}
+	args := []interface{}{userID}
+
+	if !from.IsZero() {
+		queryConditions = append(queryConditions, "date >= ?")
+		args = append(args, from)
+	}
+
+	if !to.IsZero() {
+		queryConditions = append(queryConditions, "date <= ?")
+		args = append(args, to)
+	}
+
+	if err := r.db.Order("date ASC").WhereFind(ctx, &snapshots, strings.Join(queryConditions, " AND "), args...); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the snapshots from the database")
+
+		return nil, err
+	}
+
+	return snapshots, nil
+}
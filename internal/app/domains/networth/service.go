@@ -0,0 +1,344 @@
+package networth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/domains/fx"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/app/domains/portfolio"
+	"github.com/jho3r/finanger-back/internal/app/domains/user"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerService = logger.Setup("domain.networth.service")
+	// ErrInvalidRange is returned when the dates or the interval of the history are not valid.
+	ErrInvalidRange = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_RANGE", "invalid range error")
+)
+
+// Service is the interface for the net worth service.
+type Service interface {
+	Compute(ctx context.Context, userID uint, date time.Time) (Snapshot, error)
+	TakeSnapshot(ctx context.Context, userID uint, date time.Time) (Snapshot, error)
+	GetHistory(ctx context.Context, userID uint, from, to time.Time, interval Interval) ([]Snapshot, error)
+	Backfill(ctx context.Context, userID uint, from, to time.Time) (int, error)
+}
+
+// ServiceImpl is the struct that contains the net worth service.
+type ServiceImpl struct {
+	repo             Repository
+	userService      user.Service
+	accountService   account.Service
+	ledgerService    ledger.Service
+	portfolioService portfolio.Service
+	finAssetService  finasset.Service
+	fxService        fx.Service
+}
+
+// NewNetWorthService creates a new net worth service.
+func NewNetWorthService(
+	repo Repository,
+	userService user.Service,
+	accountService account.Service,
+	ledgerService ledger.Service,
+	portfolioService portfolio.Service,
+	finAssetService finasset.Service,
+	fxService fx.Service,
+) Service {
+	return &ServiceImpl{
+		repo:             repo,
+		userService:      userService,
+		accountService:   accountService,
+		ledgerService:    ledgerService,
+		portfolioService: portfolioService,
+		finAssetService:  finAssetService,
+		fxService:        fxService,
+	}
+}
+
+// Compute returns the net worth of the user at the end of the day of the date, in the currency of the user,
+// without storing it. It adds the balances of all the accounts that existed then in the ledger, except the nominal ones,
+// and the value of the holdings of the brokerage accounts. Holdings without a price count at their cost basis.
+// The amounts are converted with the rates of the date.
+func (s *ServiceImpl) Compute(ctx context.Context, userID uint, date time.Time) (Snapshot, error) {
+	date = day(date)
+	asOf := date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	owner, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	currency := owner.Currency
+
+	// The accounts deleted after the date are part of its net worth, so the snapshots do not depend on when they are taken.
+	accounts, err := s.accountService.Get(ctx, userID, account.AccountFilter{DeletedAfter: &asOf})
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot := Snapshot{
+		UserID:        userID,
+		Date:          date,
+		Assets:        money.Zero(currency),
+		Liabilities:   money.Zero(currency),
+		ByAccountType: Breakdown{},
+		ByAssetClass:  Breakdown{},
+	}
+
+	assets := map[uint]finasset.FinancialAsset{}
+	accountTypes := map[uint]account.AccountType{}
+
+	for _, acc := range accounts {
+		if acc.Type.IsNominal() {
+			continue
+		}
+
+		existed, err := s.existed(ctx, acc, asOf)
+		if err != nil {
+			return Snapshot{}, err
+		}
+
+		if !existed {
+			continue
+		}
+
+		accountTypes[acc.ID] = acc.Type
+
+		balance, err := s.ledgerService.GetAccountBalance(ctx, acc, asOf)
+		if err != nil {
+			return Snapshot{}, err
+		}
+
+		converted, err := s.convert(ctx, balance.Balance, currency, asOf)
+		if err != nil {
+			return Snapshot{}, err
+		}
+
+		class := DebtClass
+
+		if !acc.Type.IsLiability() {
+			asset, err := s.asset(ctx, assets, acc.AssetID)
+			if err != nil {
+				return Snapshot{}, err
+			}
+
+			class = assetClass(asset)
+		}
+
+		if err := snapshot.add(string(acc.Type), class, converted, acc.Type.IsLiability()); err != nil {
+			return Snapshot{}, err
+		}
+	}
+
+	valuation, err := s.portfolioService.GetValuation(ctx, userID, asOf)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	for _, position := range valuation.Positions {
+		accountType, ok := accountTypes[position.AccountID]
+		if !ok {
+			continue
+		}
+
+		value := position.CostBasis
+		if position.Priced {
+			value = *position.Value
+		}
+
+		asset, err := s.asset(ctx, assets, position.AssetID)
+		if err != nil {
+			return Snapshot{}, err
+		}
+
+		if err := snapshot.add(string(accountType), assetClass(asset), value, false); err != nil {
+			return Snapshot{}, err
+		}
+	}
+
+	if snapshot.NetWorth, err = snapshot.Assets.Sub(snapshot.Liabilities); err != nil {
+		return Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// TakeSnapshot computes the net worth of the user at the end of the day of the date and stores it,
+// overwriting the snapshot of the same day if any.
+func (s *ServiceImpl) TakeSnapshot(ctx context.Context, userID uint, date time.Time) (Snapshot, error) {
+	snapshot, err := s.Compute(ctx, userID, date)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := s.repo.Upsert(ctx, snapshot); err != nil {
+		loggerService.WithError(err).Error("Error storing the snapshot")
+		return Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// GetHistory returns the snapshots of the user between the dates, both inclusive, one per interval.
+// Each interval is represented by its last snapshot.
+func (s *ServiceImpl) GetHistory(ctx context.Context, userID uint, from, to time.Time, interval Interval) ([]Snapshot, error) {
+	if !interval.IsValid() {
+		return nil, invalidRange(fmt.Sprintf("Unknown interval %s", interval))
+	}
+
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, invalidRange("The end date must be after the start date")
+	}
+
+	snapshots, err := s.repo.Get(ctx, userID, from, to)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the snapshots from the repo")
+		return nil, err
+	}
+
+	history := []Snapshot{}
+
+	for _, snapshot := range snapshots {
+		last := len(history) - 1
+		if last >= 0 && interval.bucket(history[last].Date).Equal(interval.bucket(snapshot.Date)) {
+			history[last] = snapshot
+			continue
+		}
+
+		history = append(history, snapshot)
+	}
+
+	return history, nil
+}
+
+// Backfill takes the snapshots of every day between the dates, both inclusive, from the stored balances
+// and prices. It covers the given user, or all the users when it is zero, and returns the number of snapshots.
+// It stops at the first error or when the context is done.
+func (s *ServiceImpl) Backfill(ctx context.Context, userID uint, from, to time.Time) (int, error) {
+	from, to = day(from), day(to)
+	if to.Before(from) {
+		return 0, invalidRange("The end date must be after the start date")
+	}
+
+	var users []user.User
+
+	if userID != 0 {
+		owner, err := s.userService.GetByID(ctx, userID)
+		if err != nil {
+			return 0, err
+		}
+
+		users = []user.User{owner}
+	} else {
+		var err error
+		if users, err = s.userService.GetAll(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+
+	for _, owner := range users {
+		for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+			if err := ctx.Err(); err != nil {
+				return count, err
+			}
+
+			if _, err := s.TakeSnapshot(ctx, owner.ID, date); err != nil {
+				loggerService.WithError(err).Errorf("Error backfilling the snapshot of the user %d on %s", owner.ID, date.Format(time.DateOnly))
+				return count, err
+			}
+
+			count++
+		}
+
+		loggerService.Infof("Backfilled the snapshots of the user %d", owner.ID)
+	}
+
+	return count, nil
+}
+
+// add adds the amount to the assets or the liabilities of the snapshot and to its breakdowns.
+// The liabilities are stored as the amount owed, which is the negative of the balance.
+func (s *Snapshot) add(accountType, class string, amount money.Money, liability bool) error {
+	var err error
+
+	if liability {
+		s.Liabilities, err = s.Liabilities.Sub(amount)
+	} else {
+		s.Assets, err = s.Assets.Add(amount)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.ByAccountType.add(accountType, amount); err != nil {
+		return err
+	}
+
+	return s.ByAssetClass.add(class, amount)
+}
+
+// convert converts the amount to the currency with the rate of the date.
+func (s *ServiceImpl) convert(ctx context.Context, amount money.Money, currency string, date time.Time) (money.Money, error) {
+	conversion, err := s.fxService.Convert(ctx, amount, currency, date)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return conversion.Converted, nil
+}
+
+// existed reports if the account existed in the ledger at the time, that is, if it was opened by then
+// or has postings up to then. The accounts created later can have a backdated opening date or postings.
+func (s *ServiceImpl) existed(ctx context.Context, acc account.Account, asOf time.Time) (bool, error) {
+	if !acc.OpeningDate.After(asOf) {
+		return true, nil
+	}
+
+	entries, err := s.ledgerService.GetAccountEntries(ctx, acc, time.Time{}, asOf)
+	if err != nil {
+		return false, err
+	}
+
+	return len(entries) > 0, nil
+}
+
+// asset returns the financial asset with the id, caching it in the map.
+func (s *ServiceImpl) asset(ctx context.Context, assets map[uint]finasset.FinancialAsset, id uint) (finasset.FinancialAsset, error) {
+	if asset, ok := assets[id]; ok {
+		return asset, nil
+	}
+
+	asset, err := s.finAssetService.GetByID(ctx, id)
+	if err != nil {
+		return finasset.FinancialAsset{}, err
+	}
+
+	assets[id] = asset
+
+	return asset, nil
+}
+
+// assetClass returns the asset class of the amounts denominated in the asset.
+func assetClass(asset finasset.FinancialAsset) string {
+	if asset.Type == finasset.Currency {
+		return CashClass
+	}
+
+	return string(asset.Type)
+}
+
+func invalidRange(desc string) error {
+	loggerService.WithError(ErrInvalidRange).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidRange)
+}
@@ -0,0 +1,87 @@
+package networth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/category"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/domains/fx"
+	"github.com/jho3r/finanger-back/internal/app/domains/holding"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/app/domains/portfolio"
+	"github.com/jho3r/finanger-back/internal/app/domains/user"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/jwt"
+	"github.com/shopspring/decimal"
+)
+
+func TestServiceComputeDeletedAccounts(t *testing.T) {
+	ctx := context.Background()
+	db := gorm.NewMemoryGorm()
+
+	finAssetService := finasset.NewFinAssetService(finasset.NewCurrencyRepository(db))
+	fxService := fx.NewFXService(finAssetService, "USD")
+	accountService := account.NewAccountService(account.NewAccountRepository(db), finAssetService)
+	ledgerService := ledger.NewLedgerService(ledger.NewLedgerRepository(db), accountService, finAssetService)
+	categoryService := category.NewCategoryService(db, category.NewCategoryRepository(db), ledgerService)
+	userService := user.NewUserService(db, user.NewUserRepository(db), jwt.NewJWT("secret", "test", time.Minute), time.Hour, categoryService)
+	holdingService := holding.NewHoldingService(db, holding.NewHoldingRepository(db), accountService, finAssetService)
+	portfolioService := portfolio.NewPortfolioService(userService, holdingService, finAssetService, fxService)
+	service := NewNetWorthService(NewNetWorthRepository(db), userService, accountService, ledgerService, portfolioService, finAssetService, fxService)
+
+	minorUnits := 2
+	usd := finasset.FinancialAsset{Symbol: "USD", Name: "US dollar", Type: finasset.Currency, Metadata: finasset.Metadata{NumericCode: "840", MinorUnits: &minorUnits}}
+
+	if err := finAssetService.Create(ctx, usd); err != nil {
+		t.Fatalf("Create() of the currency error = %v", err)
+	}
+
+	if err := userService.Signup(ctx, user.User{Name: "Ana", Email: "ana@example.com", Currency: "USD", Password: "password"}); err != nil {
+		t.Fatalf("Signup() error = %v", err)
+	}
+
+	opened := time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, balance := range []int64{100, 50} {
+		acc, err := accountService.Create(ctx, account.Account{
+			UserID: 1, AssetID: 1, Name: "Checking", Type: account.Checking,
+			OpeningBalance: money.New(decimal.NewFromInt(balance), "USD"), OpeningDate: opened,
+		})
+		if err != nil {
+			t.Fatalf("Create() of the account error = %v", err)
+		}
+
+		// The account of 50 is deleted now, after the past dates of the snapshots.
+		if balance == 50 {
+			if err := accountService.Delete(ctx, 1, acc.ID); err != nil {
+				t.Fatalf("Delete() of the account error = %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name       string
+		date       time.Time
+		wantAssets string
+	}{
+		{name: "before the deletion", date: opened.AddDate(0, 0, 14), wantAssets: "150"},
+		{name: "after the deletion", date: time.Now().AddDate(0, 0, 2), wantAssets: "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := service.Compute(ctx, 1, tt.date)
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+
+			if !snapshot.Assets.Amount.Equal(decimal.RequireFromString(tt.wantAssets)) {
+				t.Errorf("Compute() assets = %s, want %s USD", snapshot.Assets, tt.wantAssets)
+			}
+		})
+	}
+}
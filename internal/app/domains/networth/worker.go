@@ -0,0 +1,83 @@
+package networth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/user"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerWorker       = logger.Setup("domain.networth.worker")
+	errInvalidInterval = errors.New("invalid interval error")
+)
+
+// Worker is the interface for the periodic snapshots of the net worth of the users.
+type Worker interface {
+	Run(ctx context.Context)
+	RunOnce(ctx context.Context)
+}
+
+// WorkerImpl is the struct that contains the dependencies of the snapshots.
+type WorkerImpl struct {
+	service     Service
+	userService user.Service
+	interval    time.Duration
+}
+
+// NewWorker creates a new worker that takes the snapshots of all the users every interval, which must be positive.
+func NewWorker(service Service, userService user.Service, interval time.Duration) (Worker, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("The net worth interval %s must be positive", interval), errInvalidInterval)
+	}
+
+	return &WorkerImpl{service: service, userService: userService, interval: interval}, nil
+}
+
+// Run takes the snapshots right away and then every interval until the context is done.
+func (w *WorkerImpl) Run(ctx context.Context) {
+	loggerWorker.Infof("Starting the net worth snapshots every %s", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			loggerWorker.Info("Stopping the net worth snapshots")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce takes the snapshots of yesterday and today of all the users.
+// Yesterday is taken again so it includes the movements recorded after the last run of the day.
+// A failure of a user is logged and does not stop the others.
+func (w *WorkerImpl) RunOnce(ctx context.Context) {
+	users, err := w.userService.GetAll(ctx)
+	if err != nil {
+		loggerWorker.WithError(err).Error("Error getting the users")
+		return
+	}
+
+	today := day(time.Now())
+
+	for _, owner := range users {
+		for _, date := range []time.Time{today.AddDate(0, 0, -1), today} {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if _, err := w.service.TakeSnapshot(ctx, owner.ID, date); err != nil {
+				loggerWorker.WithError(err).Errorf("Error taking the snapshot of the user %d on %s", owner.ID, date.Format(time.DateOnly))
+			}
+		}
+	}
+}
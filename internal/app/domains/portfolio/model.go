@@ -0,0 +1,125 @@
+package portfolio
+
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// ShortTerm is the term of the gains of lots held for one year or less.
+	ShortTerm Term = "short_term"
+	// LongTerm is the term of the gains of lots held for more than one year.
+	LongTerm Term = "long_term"
+)
+
+type (
+	// Term can be short_term or long_term.
+	Term string
+
+	// Position is the struct for the value of a holding at a date.
+	// The price is in the currency of the account, the amounts in the currency of the user.
+	// A position without a price on or before the date is not priced and has no value nor gains.
+	Position struct {
+		AccountID uint            `json:"account_id"`
+		AssetID   uint            `json:"asset_id"`
+		Symbol    string          `json:"symbol"`
+		Quantity  decimal.Decimal `json:"quantity"`
+		Priced    bool            `json:"priced"`
+		Price     *money.Money    `json:"price,omitempty"`
+		PriceDate *time.Time      `json:"price_date,omitempty"`
+		CostBasis money.Money     `json:"cost_basis"`
+		Value     *money.Money    `json:"value,omitempty"`
+		Gains     *Gains          `json:"unrealized,omitempty"`
+	}
+
+	// Valuation is the struct for the value of the holdings of a user at a date, in the currency of the user.
+	// The totals only add up the priced positions.
+	Valuation struct {
+		AsOf       time.Time   `json:"as_of"`
+		Currency   string      `json:"currency"`
+		Positions  []Position  `json:"positions"`
+		CostBasis  money.Money `json:"cost_basis"`
+		Value      money.Money `json:"value"`
+		Unrealized Gains       `json:"unrealized"`
+	}
+
+	// Gains is the struct for the gains split by the term the lots were held.
+	Gains struct {
+		ShortTerm money.Money `json:"short_term"`
+		LongTerm  money.Money `json:"long_term"`
+		Total     money.Money `json:"total"`
+	}
+
+	// Sale is the struct for the realized gain of a tax lot sale, in the currency of the user.
+	Sale struct {
+		TradeID    uint            `json:"trade_id"`
+		LotID      uint            `json:"lot_id"`
+		AccountID  uint            `json:"account_id"`
+		AssetID    uint            `json:"asset_id"`
+		Symbol     string          `json:"symbol"`
+		Quantity   decimal.Decimal `json:"quantity"`
+		AcquiredAt time.Time       `json:"acquired_at"`
+		SoldAt     time.Time       `json:"sold_at"`
+		Term       Term            `json:"term"`
+		CostBasis  money.Money     `json:"cost_basis"`
+		Proceeds   money.Money     `json:"proceeds"`
+		Gain       money.Money     `json:"gain"`
+	}
+
+	// PnL is the struct for the profit and loss of the holdings of a user, in the currency of the user.
+	// The realized gains are the ones of the sales between the dates, converted at the date of each sale.
+	// The unrealized gains are the ones of the holdings at the end date.
+	PnL struct {
+		From       time.Time `json:"from"`
+		To         time.Time `json:"to"`
+		Currency   string    `json:"currency"`
+		Realized   Gains     `json:"realized"`
+		Unrealized Gains     `json:"unrealized"`
+		Total      Gains     `json:"total"`
+		Sales      []Sale    `json:"sales"`
+	}
+)
+
+// zeroGains returns the gains in the currency with zero amounts.
+func zeroGains(currency string) Gains {
+	return Gains{ShortTerm: money.Zero(currency), LongTerm: money.Zero(currency), Total: money.Zero(currency)}
+}
+
+// add adds the gain to the gains of its term.
+func (g *Gains) add(term Term, gain money.Money) error {
+	var err error
+
+	if term == LongTerm {
+		if g.LongTerm, err = g.LongTerm.Add(gain); err != nil {
+			return err
+		}
+	} else {
+		if g.ShortTerm, err = g.ShortTerm.Add(gain); err != nil {
+			return err
+		}
+	}
+
+	g.Total, err = g.Total.Add(gain)
+
+	return err
+}
+
+// merge adds the gains of each term of the other gains.
+func (g *Gains) merge(other Gains) error {
+	if err := g.add(ShortTerm, other.ShortTerm); err != nil {
+		return err
+	}
+
+	return g.add(LongTerm, other.LongTerm)
+}
+
+// termOf returns the term of a lot bought and sold, or still held, at the dates.
+func termOf(acquiredAt, soldAt time.Time) Term {
+	if soldAt.After(acquiredAt.AddDate(1, 0, 0)) {
+		return LongTerm
+	}
+
+	return ShortTerm
+}
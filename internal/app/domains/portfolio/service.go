@@ -0,0 +1,331 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/domains/fx"
+	"github.com/jho3r/finanger-back/internal/app/domains/holding"
+	"github.com/jho3r/finanger-back/internal/app/domains/user"
+	"github.com/jho3r/finanger-back/internal/app/money"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	loggerService = logger.Setup("domain.portfolio.service")
+	// ErrInvalidPeriod is returned when the dates of the profit and loss are not valid.
+	ErrInvalidPeriod = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_PERIOD", "invalid period error")
+)
+
+// Service is the interface for the portfolio service.
+type Service interface {
+	GetValuation(ctx context.Context, userID uint, asOf time.Time) (Valuation, error)
+	GetPnL(ctx context.Context, userID uint, from, to time.Time) (PnL, error)
+}
+
+// ServiceImpl is the struct that contains the portfolio service.
+type ServiceImpl struct {
+	userService     user.Service
+	holdingService  holding.Service
+	finAssetService finasset.Service
+	fxService       fx.Service
+}
+
+// NewPortfolioService creates a new portfolio service.
+func NewPortfolioService(
+	userService user.Service,
+	holdingService holding.Service,
+	finAssetService finasset.Service,
+	fxService fx.Service,
+) Service {
+	return &ServiceImpl{
+		userService:     userService,
+		holdingService:  holdingService,
+		finAssetService: finAssetService,
+		fxService:       fxService,
+	}
+}
+
+// GetValuation returns the value of the holdings of the user at the date, in the currency of the user.
+// Each holding is valued at the latest close price on or before the date in the currency of its account,
+// and the amounts are converted with the rate of the date.
+func (s *ServiceImpl) GetValuation(ctx context.Context, userID uint, asOf time.Time) (Valuation, error) {
+	owner, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return Valuation{}, err
+	}
+
+	lots, err := s.holdingService.GetLotsAt(ctx, userID, asOf)
+	if err != nil {
+		return Valuation{}, err
+	}
+
+	valuation := Valuation{
+		AsOf:       asOf,
+		Currency:   owner.Currency,
+		Positions:  []Position{},
+		CostBasis:  money.Zero(owner.Currency),
+		Value:      money.Zero(owner.Currency),
+		Unrealized: zeroGains(owner.Currency),
+	}
+
+	assets := map[uint]finasset.FinancialAsset{}
+
+	for _, group := range groupLots(lots) {
+		asset, err := s.asset(ctx, assets, group[0].AssetID)
+		if err != nil {
+			return Valuation{}, err
+		}
+
+		position, err := s.position(ctx, asset, group, owner.Currency, asOf)
+		if err != nil {
+			return Valuation{}, err
+		}
+
+		valuation.Positions = append(valuation.Positions, position)
+
+		if !position.Priced {
+			continue
+		}
+
+		if valuation.CostBasis, err = valuation.CostBasis.Add(position.CostBasis); err != nil {
+			return Valuation{}, err
+		}
+
+		if valuation.Value, err = valuation.Value.Add(*position.Value); err != nil {
+			return Valuation{}, err
+		}
+
+		if err := valuation.Unrealized.merge(*position.Gains); err != nil {
+			return Valuation{}, err
+		}
+	}
+
+	return valuation, nil
+}
+
+// GetPnL returns the realized gains of the sales of the user between the dates, both inclusive,
+// and the unrealized gains of the holdings at the end date, in the currency of the user.
+func (s *ServiceImpl) GetPnL(ctx context.Context, userID uint, from, to time.Time) (PnL, error) {
+	if !from.IsZero() && to.Before(from) {
+		desc := "The end date must be after the start date"
+		loggerService.WithError(ErrInvalidPeriod).Error(desc)
+
+		return PnL{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidPeriod)
+	}
+
+	valuation, err := s.GetValuation(ctx, userID, to)
+	if err != nil {
+		return PnL{}, err
+	}
+
+	currency := valuation.Currency
+
+	realized, err := s.holdingService.GetRealized(ctx, userID, from, to)
+	if err != nil {
+		return PnL{}, err
+	}
+
+	pnl := PnL{
+		From:       from,
+		To:         to,
+		Currency:   currency,
+		Realized:   zeroGains(currency),
+		Unrealized: valuation.Unrealized,
+		Total:      zeroGains(currency),
+		Sales:      make([]Sale, 0, len(realized)),
+	}
+
+	assets := map[uint]finasset.FinancialAsset{}
+
+	for _, r := range realized {
+		asset, err := s.asset(ctx, assets, r.AssetID)
+		if err != nil {
+			return PnL{}, err
+		}
+
+		sale, err := s.sale(ctx, asset, r, currency)
+		if err != nil {
+			return PnL{}, err
+		}
+
+		if err := pnl.Realized.add(sale.Term, sale.Gain); err != nil {
+			return PnL{}, err
+		}
+
+		pnl.Sales = append(pnl.Sales, sale)
+	}
+
+	if err := pnl.Total.merge(pnl.Realized); err != nil {
+		return PnL{}, err
+	}
+
+	if err := pnl.Total.merge(pnl.Unrealized); err != nil {
+		return PnL{}, err
+	}
+
+	return pnl, nil
+}
+
+// position values the lots of a holding at the date in the currency.
+// The total gain is the value minus the cost basis, so the converted amounts add up,
+// and the long term gain is the rest of the short term one.
+func (s *ServiceImpl) position(ctx context.Context, asset finasset.FinancialAsset, lots []holding.Lot, currency string, asOf time.Time) (Position, error) {
+	quoteCurrency := lots[0].RemainingCost.Currency
+
+	quantity := decimal.Zero
+	cost := money.Zero(quoteCurrency)
+
+	for _, lot := range lots {
+		quantity = quantity.Add(lot.Remaining)
+		cost.Amount = cost.Amount.Add(lot.RemainingCost.Amount)
+	}
+
+	costBasis, err := s.convert(ctx, cost, currency, asOf)
+	if err != nil {
+		return Position{}, err
+	}
+
+	position := Position{
+		AccountID: lots[0].AccountID,
+		AssetID:   asset.ID,
+		Symbol:    asset.Symbol,
+		Quantity:  quantity,
+		CostBasis: costBasis,
+	}
+
+	price, err := s.finAssetService.GetLatestPrice(ctx, asset.ID, quoteCurrency, asOf)
+	if errors.Is(err, finasset.ErrPriceNotFound) {
+		loggerService.WithError(err).Warnf("Holding of %s without a price", asset.Symbol)
+		return position, nil
+	}
+
+	if err != nil {
+		return Position{}, err
+	}
+
+	shortTerm := money.Zero(quoteCurrency)
+
+	for _, lot := range lots {
+		if termOf(lot.Date, asOf) == ShortTerm {
+			shortTerm.Amount = shortTerm.Amount.Add(price.Close.Mul(lot.Remaining).Sub(lot.RemainingCost.Amount))
+		}
+	}
+
+	value, err := s.convert(ctx, money.New(price.Close.Mul(quantity), quoteCurrency), currency, asOf)
+	if err != nil {
+		return Position{}, err
+	}
+
+	gains := Gains{}
+
+	if gains.Total, err = value.Sub(costBasis); err != nil {
+		return Position{}, err
+	}
+
+	if gains.ShortTerm, err = s.convert(ctx, shortTerm, currency, asOf); err != nil {
+		return Position{}, err
+	}
+
+	if gains.LongTerm, err = gains.Total.Sub(gains.ShortTerm); err != nil {
+		return Position{}, err
+	}
+
+	unitPrice := money.New(price.Close, quoteCurrency)
+
+	position.Priced = true
+	position.Price = &unitPrice
+	position.PriceDate = &price.Timestamp
+	position.Value = &value
+	position.Gains = &gains
+
+	return position, nil
+}
+
+// sale converts the realized gain of a lot sale to the currency with the rate of the date of the sale.
+func (s *ServiceImpl) sale(ctx context.Context, asset finasset.FinancialAsset, realized holding.Realized, currency string) (Sale, error) {
+	costBasis, err := s.convert(ctx, realized.CostBasis, currency, realized.SoldAt)
+	if err != nil {
+		return Sale{}, err
+	}
+
+	proceeds, err := s.convert(ctx, realized.Proceeds, currency, realized.SoldAt)
+	if err != nil {
+		return Sale{}, err
+	}
+
+	gain, err := proceeds.Sub(costBasis)
+	if err != nil {
+		return Sale{}, err
+	}
+
+	return Sale{
+		TradeID:    realized.TradeID,
+		LotID:      realized.LotID,
+		AccountID:  realized.AccountID,
+		AssetID:    asset.ID,
+		Symbol:     asset.Symbol,
+		Quantity:   realized.Quantity,
+		AcquiredAt: realized.AcquiredAt,
+		SoldAt:     realized.SoldAt,
+		Term:       termOf(realized.AcquiredAt, realized.SoldAt),
+		CostBasis:  costBasis,
+		Proceeds:   proceeds,
+		Gain:       gain,
+	}, nil
+}
+
+// convert converts the amount to the currency with the rate of the date.
+func (s *ServiceImpl) convert(ctx context.Context, amount money.Money, currency string, date time.Time) (money.Money, error) {
+	conversion, err := s.fxService.Convert(ctx, amount, currency, date)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return conversion.Converted, nil
+}
+
+// asset returns the financial asset with the id, caching it in the map.
+func (s *ServiceImpl) asset(ctx context.Context, assets map[uint]finasset.FinancialAsset, id uint) (finasset.FinancialAsset, error) {
+	if asset, ok := assets[id]; ok {
+		return asset, nil
+	}
+
+	asset, err := s.finAssetService.GetByID(ctx, id)
+	if err != nil {
+		return finasset.FinancialAsset{}, err
+	}
+
+	assets[id] = asset
+
+	return asset, nil
+}
+
+// groupLots groups the lots by account and asset, keeping the order of the first lot of each group.
+func groupLots(lots []holding.Lot) [][]holding.Lot {
+	type key struct{ accountID, assetID uint }
+
+	var groups [][]holding.Lot
+
+	index := map[key]int{}
+
+	for _, lot := range lots {
+		k := key{lot.AccountID, lot.AssetID}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, nil)
+		}
+
+		groups[i] = append(groups[i], lot)
+	}
+
+	return groups
+}
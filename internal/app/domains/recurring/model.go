@@ -0,0 +1,140 @@
+package recurring
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+var errScanPostings = errors.New("scan postings error")
+
+const (
+	// Scheduled is the status of the future occurrences not stored yet.
+	Scheduled OccurrenceStatus = "scheduled"
+	// Pending is the status of the occurrences claimed by the worker while their transaction is created.
+	Pending OccurrenceStatus = "pending"
+	// Posted is the status of the occurrences with a stored transaction.
+	Posted OccurrenceStatus = "posted"
+	// Skipped is the status of the occurrences skipped by the user.
+	Skipped OccurrenceStatus = "skipped"
+	// Failed is the status of the occurrences whose transaction could not be created, they are retried.
+	Failed OccurrenceStatus = "failed"
+)
+
+type (
+	// OccurrenceStatus can be scheduled, pending, posted, skipped or failed.
+	OccurrenceStatus string
+
+	// Template is the struct for a transaction of a user that repeats on a schedule.
+	// NextDate is the first occurrence not materialized yet, nil when the schedule is over.
+	Template struct {
+		gorm.Model
+		UserID      uint                   `json:"user_id" gorm:"not null"`
+		Name        string                 `json:"name" gorm:"not null"`
+		Kind        ledger.TransactionKind `json:"kind" gorm:"not null"`
+		Description string                 `json:"description" gorm:"not null"`
+		CategoryID  *uint                  `json:"category_id"`
+		Postings    TemplatePostings       `json:"postings" gorm:"type:jsonb;not null"`
+		Schedule    Schedule               `json:"schedule" gorm:"embedded;embeddedPrefix:schedule_"`
+		NextDate    *time.Time             `json:"next_date"`
+	}
+
+	// TemplatePosting is the struct for a posting of the transactions created from a template.
+	TemplatePosting struct {
+		AccountID uint            `json:"account_id"`
+		Amount    decimal.Decimal `json:"amount"`
+		Memo      string          `json:"memo"`
+	}
+
+	// TemplatePostings is the list of postings of a template, stored as JSON.
+	TemplatePostings []TemplatePosting
+
+	// TemplatePatch is the struct for the changes of a template from an occurrence on, nil fields are not changed.
+	TemplatePatch struct {
+		Name        *string
+		Description *string
+		CategoryID  *uint
+		Postings    TemplatePostings
+		Frequency   *Frequency
+		Interval    *int
+		ByDay       *string
+		EndDate     *time.Time
+		Count       *int
+	}
+
+	// Occurrence is the struct for an occurrence of a template materialized or skipped.
+	// There is only one occurrence per template and date, which makes the materialization idempotent.
+	Occurrence struct {
+		gorm.Model
+		TemplateID    uint             `json:"template_id" gorm:"not null"`
+		Date          time.Time        `json:"date" gorm:"not null"`
+		Status        OccurrenceStatus `json:"status" gorm:"not null"`
+		TransactionID *uint            `json:"transaction_id"`
+		Error         string           `json:"error,omitempty" gorm:"not null"`
+	}
+)
+
+// TableName overrides the table name of the templates.
+func (Template) TableName() string {
+	return "recurring_templates"
+}
+
+// TableName overrides the table name of the occurrences.
+func (Occurrence) TableName() string {
+	return "recurring_occurrences"
+}
+
+// Transaction returns the transaction of the template for the occurrence on the date.
+func (t Template) Transaction(date time.Time) ledger.Transaction {
+	postings := make([]ledger.Posting, len(t.Postings))
+	for i, posting := range t.Postings {
+		postings[i] = ledger.Posting{AccountID: posting.AccountID, Memo: posting.Memo}
+		postings[i].Amount.Amount = posting.Amount
+	}
+
+	return ledger.Transaction{
+		UserID:      t.UserID,
+		Date:        date,
+		Kind:        t.Kind,
+		Description: t.Description,
+		CategoryID:  t.CategoryID,
+		Postings:    postings,
+	}
+}
+
+// IsEmpty reports if the patch does not change any field.
+func (p TemplatePatch) IsEmpty() bool {
+	return p.Name == nil && p.Description == nil && p.CategoryID == nil && p.Postings == nil &&
+		p.Frequency == nil && p.Interval == nil && p.ByDay == nil && p.EndDate == nil && p.Count == nil
+}
+
+// Value implements the driver.Valuer interface to store the postings as JSON.
+func (p TemplatePostings) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(bytes), nil
+}
+
+// Scan implements the sql.Scanner interface to read the postings from JSON.
+func (p *TemplatePostings) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = TemplatePostings{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("%w: unsupported type %T", errScanPostings, value)
+	}
+}
@@ -0,0 +1,294 @@
+package recurring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var loggerRepo = logger.Setup("domain.recurring.repository")
+
+// Repository is the interface for the recurring transactions repository.
+type Repository interface {
+	Create(ctx context.Context, template Template) (Template, error)
+	Get(ctx context.Context, userID uint) ([]Template, error)
+	GetByID(ctx context.Context, userID, id uint) (Template, error)
+	GetDue(ctx context.Context, now time.Time) ([]Template, error)
+	Delete(ctx context.Context, userID, id uint) error
+	Split(ctx context.Context, userID, id uint, endDate time.Time, next Template) (Template, error)
+	SetNextDate(ctx context.Context, id uint, nextDate *time.Time) error
+
+	GetOccurrences(ctx context.Context, templateID uint, from, to time.Time) ([]Occurrence, error)
+	ClaimOccurrence(ctx context.Context, templateID uint, date time.Time) (Occurrence, bool, error)
+	FailOccurrence(ctx context.Context, templateID uint, date time.Time, message string) error
+	UpdateOccurrence(ctx context.Context, occurrence Occurrence) error
+	SkipOccurrence(ctx context.Context, templateID uint, date time.Time) error
+	WithTx(tx gorm.Gorm) Repository
+}
+
+// RepositoryImpl is the struct that contains the recurring transactions repository.
+type RepositoryImpl struct {
+	db gorm.Gorm
+}
+
+// NewRecurringRepository creates a new recurring transactions repository.
+func NewRecurringRepository(db gorm.Gorm) Repository {
+	return &RepositoryImpl{db: db}
+}
+
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
+// Create creates a new template and returns it with its id.
+func (r *RepositoryImpl) Create(ctx context.Context, template Template) (Template, error) {
+	if err := r.db.Create(ctx, &template); err != nil {
+		loggerRepo.WithError(err).Error("Error creating record in the database")
+
+		return Template{}, err
+	}
+
+	return template, nil
+}
+
+// Get returns the templates of the user, sorted by id.
+func (r *RepositoryImpl) Get(ctx context.Context, userID uint) ([]Template, error) {
+	var templates []Template
+	if err := r.db.Order("id ASC").WhereFind(ctx, &templates, "user_id = ?", userID); err != nil {
+		loggerRepo.WithError(err).Error("Error getting records from the database")
+
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// GetByID returns the template of the user with the given id.
+func (r *RepositoryImpl) GetByID(ctx context.Context, userID, id uint) (Template, error) {
+	var template Template
+	if err := r.db.WhereFirst(ctx, &template, "user_id = ? AND id = ?", userID, id); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the template by id")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return Template{}, fmt.Errorf(crosscuting.WrapLabel, "Template not found", ErrTemplateNotFound, err.Error())
+		}
+
+		return Template{}, err
+	}
+
+	return template, nil
+}
+
+// GetDue returns the templates of all the users with occurrences to materialize at the given time.
+func (r *RepositoryImpl) GetDue(ctx context.Context, now time.Time) ([]Template, error) {
+	var templates []Template
+	if err := r.db.Order("id ASC").WhereFind(ctx, &templates, "next_date <= ?", now); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the due templates from the database")
+
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// Delete soft deletes the template of the user, the transactions already created are kept.
+func (r *RepositoryImpl) Delete(ctx context.Context, userID, id uint) error {
+	rows, err := r.db.WhereDelete(ctx, &Template{}, "user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error deleting record in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Template not found", ErrTemplateNotFound)
+	}
+
+	return nil
+}
+
+// Split ends the schedule of the template of the user at the end date and creates the next template, atomically.
+// The occurrences stored after the end date, like the skipped ones, are moved to the next template.
+func (r *RepositoryImpl) Split(ctx context.Context, userID, id uint, endDate time.Time, next Template) (Template, error) {
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		rows, err := tx.WhereUpdates(ctx, &Template{}, map[string]interface{}{"schedule_end_date": endDate, "schedule_count": nil},
+			"user_id = ? AND id = ?", userID, id)
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return fmt.Errorf(crosscuting.WrapLabelWithoutError, "Template not found", ErrTemplateNotFound)
+		}
+
+		if _, err := tx.WhereUpdates(ctx, &Template{}, map[string]interface{}{"next_date": nil},
+			"id = ? AND next_date > ?", id, endDate); err != nil {
+			return err
+		}
+
+		if err := tx.Create(ctx, &next); err != nil {
+			return err
+		}
+
+		_, err = tx.WhereUpdates(ctx, &Occurrence{}, map[string]interface{}{"template_id": next.ID},
+			"template_id = ? AND date > ?", id, endDate)
+
+		return err
+	})
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error splitting the template in the database")
+
+		return Template{}, err
+	}
+
+	return next, nil
+}
+
+// SetNextDate sets the first occurrence of the template not materialized yet.
+func (r *RepositoryImpl) SetNextDate(ctx context.Context, id uint, nextDate *time.Time) error {
+	if _, err := r.db.WhereUpdates(ctx, &Template{}, map[string]interface{}{"next_date": nextDate}, "id = ?", id); err != nil {
+		loggerRepo.WithError(err).Error("Error updating the next date of the template")
+
+		return err
+	}
+
+	return nil
+}
+
+// GetOccurrences returns the occurrences of the template between the dates, both included, sorted by date.
+func (r *RepositoryImpl) GetOccurrences(ctx context.Context, templateID uint, from, to time.Time) ([]Occurrence, error) {
+	var occurrences []Occurrence
+	if err := r.db.Order("date ASC").WhereFind(ctx, &occurrences, "template_id = ? AND date >= ? AND date <= ?", templateID, from, to); err != nil {
+		loggerRepo.WithError(err).Error("Error getting the occurrences from the database")
+
+		return nil, err
+	}
+
+	return occurrences, nil
+}
+
+// ClaimOccurrence marks the occurrence of the template as pending so only one worker materializes it.
+// New and failed occurrences can be claimed, it is not claimed if it was already posted or skipped.
+// It is meant to run in the transaction that posts the occurrence, so the claim is rolled back with it.
+func (r *RepositoryImpl) ClaimOccurrence(ctx context.Context, templateID uint, date time.Time) (Occurrence, bool, error) {
+	occurrence := Occurrence{TemplateID: templateID, Date: date, Status: Pending}
+
+	// The insert has its own savepoint, so a conflict does not abort the transaction of the repository.
+	err := r.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		return tx.Create(ctx, &occurrence)
+	})
+	if err == nil {
+		return occurrence, true, nil
+	}
+
+	if !errors.Is(err, crosscuting.ErrConflict) {
+		loggerRepo.WithError(err).Error("Error creating the occurrence in the database")
+
+		return Occurrence{}, false, err
+	}
+
+	query, args := claimableOccurrence(templateID, date)
+
+	rows, err := r.db.WhereUpdates(ctx, &Occurrence{}, map[string]interface{}{"status": Pending, "error": ""}, query, args...)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error claiming the failed occurrence in the database")
+
+		return Occurrence{}, false, err
+	}
+
+	if rows == 0 {
+		return Occurrence{}, false, nil
+	}
+
+	var claimed Occurrence
+	if err := r.db.WhereFirst(ctx, &claimed, "template_id = ? AND date = ?", templateID, date); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the claimed occurrence")
+
+		return Occurrence{}, false, err
+	}
+
+	return claimed, true, nil
+}
+
+// FailOccurrence records the error of the occurrence of the template, unless it was posted or skipped meanwhile.
+func (r *RepositoryImpl) FailOccurrence(ctx context.Context, templateID uint, date time.Time, message string) error {
+	err := r.db.Create(ctx, &Occurrence{TemplateID: templateID, Date: date, Status: Failed, Error: message})
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, crosscuting.ErrConflict) {
+		loggerRepo.WithError(err).Error("Error creating the occurrence in the database")
+
+		return err
+	}
+
+	query, args := claimableOccurrence(templateID, date)
+
+	if _, err := r.db.WhereUpdates(ctx, &Occurrence{}, map[string]interface{}{"error": message}, query, args...); err != nil {
+		loggerRepo.WithError(err).Error("Error updating the failed occurrence in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// UpdateOccurrence stores the status, the transaction and the error of the occurrence.
+func (r *RepositoryImpl) UpdateOccurrence(ctx context.Context, occurrence Occurrence) error {
+	values := map[string]interface{}{
+		"status":         occurrence.Status,
+		"transaction_id": occurrence.TransactionID,
+		"error":          occurrence.Error,
+	}
+
+	if _, err := r.db.WhereUpdates(ctx, &Occurrence{}, values, "id = ?", occurrence.ID); err != nil {
+		loggerRepo.WithError(err).Error("Error updating the occurrence in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// SkipOccurrence marks the occurrence of the template as skipped, only new and failed occurrences can be skipped.
+func (r *RepositoryImpl) SkipOccurrence(ctx context.Context, templateID uint, date time.Time) error {
+	err := r.db.Create(ctx, &Occurrence{TemplateID: templateID, Date: date, Status: Skipped})
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, crosscuting.ErrConflict) {
+		loggerRepo.WithError(err).Error("Error creating the occurrence in the database")
+
+		return err
+	}
+
+	query, args := claimableOccurrence(templateID, date)
+
+	rows, err := r.db.WhereUpdates(ctx, &Occurrence{}, map[string]interface{}{"status": Skipped, "error": ""}, query, args...)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error skipping the failed occurrence in the database")
+
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, "The occurrence was already posted or skipped", ErrOccurrenceTaken)
+	}
+
+	return nil
+}
+
+// claimableOccurrence returns the condition of the occurrence of the template that failed.
+// The pending occurrences are not claimable, they are only stored while a worker posts them, and a committed
+// pending occurrence may already have its transaction.
+func claimableOccurrence(templateID uint, date time.Time) (string, []interface{}) {
+	return "template_id = ? AND date = ? AND status = ?", []interface{}{templateID, date, Failed}
+}
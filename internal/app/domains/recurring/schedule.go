@@ -0,0 +1,239 @@
+package recurring
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+)
+
+const (
+	// Daily repeats every interval days.
+	Daily Frequency = "daily"
+	// Weekly repeats every interval weeks on the weekday of the start date.
+	Weekly Frequency = "weekly"
+	// Monthly repeats every interval months on the day of the start date, or on the nth weekday with by_day.
+	Monthly Frequency = "monthly"
+	// Yearly repeats every interval years on the day of the start date.
+	Yearly Frequency = "yearly"
+
+	// maxIterations bounds the candidates generated by a schedule, for schedules that rarely match.
+	maxIterations = 100000
+)
+
+var (
+	byDayRegex = regexp.MustCompile(`^(-1|[1-5])(MO|TU|WE|TH|FR|SA|SU)$`)
+
+	weekdays = map[string]time.Weekday{
+		"SU": time.Sunday,
+		"MO": time.Monday,
+		"TU": time.Tuesday,
+		"WE": time.Wednesday,
+		"TH": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+	}
+)
+
+type (
+	// Frequency can be daily, weekly, monthly or yearly.
+	Frequency string
+
+	// Schedule is the struct for the RRULE-style repetition of a template.
+	// The occurrences keep the time of the start date and stop at the end date or after count occurrences.
+	Schedule struct {
+		Frequency Frequency `json:"frequency" gorm:"not null"`
+		Interval  int       `json:"interval" gorm:"not null"`
+		// ByDay is the nth weekday of the month of the monthly schedules, like 2MO or -1FR for the last friday.
+		ByDay     string     `json:"by_day" gorm:"not null"`
+		StartDate time.Time  `json:"start_date" gorm:"not null"`
+		EndDate   *time.Time `json:"end_date"`
+		Count     *int       `json:"count"`
+	}
+)
+
+// IsValid reports if the frequency is supported.
+func (f Frequency) IsValid() bool {
+	switch f {
+	case Daily, Weekly, Monthly, Yearly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks that the schedule is consistent.
+func (s Schedule) Validate() error {
+	var desc string
+
+	switch {
+	case !s.Frequency.IsValid():
+		desc = fmt.Sprintf("Unknown frequency %s", s.Frequency)
+	case s.Interval < 1:
+		desc = "The interval must be one or more"
+	case s.StartDate.IsZero():
+		desc = "The start date is required"
+	case s.ByDay != "" && s.Frequency != Monthly:
+		desc = "The by_day is only allowed in monthly schedules"
+	case s.ByDay != "" && !byDayRegex.MatchString(s.ByDay):
+		desc = "The by_day must be the ordinal and the weekday, like 2MO or -1FR"
+	case s.EndDate != nil && s.EndDate.Before(s.StartDate):
+		desc = "The end date can not be before the start date"
+	case s.Count != nil && *s.Count < 1:
+		desc = "The count must be one or more"
+	default:
+		return nil
+	}
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidSchedule)
+}
+
+// Next returns up to n occurrences of the schedule at or after the date.
+func (s Schedule) Next(date time.Time, n int) []time.Time {
+	var dates []time.Time
+
+	if n <= 0 {
+		return dates
+	}
+
+	s.each(func(occurrence time.Time) bool {
+		if !occurrence.Before(date) {
+			dates = append(dates, occurrence)
+		}
+
+		return len(dates) < n
+	})
+
+	return dates
+}
+
+// Between returns the occurrences of the schedule between the dates, both included.
+func (s Schedule) Between(from, to time.Time) []time.Time {
+	var dates []time.Time
+
+	s.each(func(occurrence time.Time) bool {
+		if occurrence.After(to) {
+			return false
+		}
+
+		if !occurrence.Before(from) {
+			dates = append(dates, occurrence)
+		}
+
+		return true
+	})
+
+	return dates
+}
+
+// CountBefore returns the number of occurrences of the schedule before the date.
+func (s Schedule) CountBefore(date time.Time) int {
+	count := 0
+
+	s.each(func(occurrence time.Time) bool {
+		if !occurrence.Before(date) {
+			return false
+		}
+
+		count++
+
+		return true
+	})
+
+	return count
+}
+
+// Includes reports if the date is an occurrence of the schedule.
+func (s Schedule) Includes(date time.Time) bool {
+	found := false
+
+	s.each(func(occurrence time.Time) bool {
+		found = occurrence.Equal(date)
+
+		return !found && occurrence.Before(date)
+	})
+
+	return found
+}
+
+// each calls the function with the occurrences of the schedule in order until it returns false or the schedule ends.
+func (s Schedule) each(fn func(occurrence time.Time) bool) {
+	count := 0
+
+	for i := 0; i < maxIterations; i++ {
+		occurrence, ok := s.candidate(i)
+		if !ok || occurrence.Before(s.StartDate) {
+			continue
+		}
+
+		if s.EndDate != nil && occurrence.After(*s.EndDate) {
+			return
+		}
+
+		if !fn(occurrence) {
+			return
+		}
+
+		count++
+		if s.Count != nil && count >= *s.Count {
+			return
+		}
+	}
+}
+
+// candidate returns the i-th candidate of the schedule, it is not ok if the period has no occurrence,
+// like the fifth monday of a month with four mondays.
+func (s Schedule) candidate(i int) (time.Time, bool) {
+	step := i * s.Interval
+
+	switch s.Frequency {
+	case Daily:
+		return s.StartDate.AddDate(0, 0, step), true
+	case Weekly:
+		return s.StartDate.AddDate(0, 0, 7*step), true
+	case Monthly:
+		if s.ByDay == "" {
+			return crosscuting.AddMonths(s.StartDate, step), true
+		}
+
+		return nthWeekday(crosscuting.AddMonths(firstOfMonth(s.StartDate), step), s.ByDay)
+	case Yearly:
+		return crosscuting.AddMonths(s.StartDate, 12*step), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// nthWeekday returns the day of the month of the date described by the by_day, keeping the time of the date.
+func nthWeekday(month time.Time, byDay string) (time.Time, bool) {
+	match := byDayRegex.FindStringSubmatch(byDay)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	ordinal, _ := strconv.Atoi(match[1])
+	weekday := weekdays[match[2]]
+
+	if ordinal < 0 {
+		last := month.AddDate(0, 0, crosscuting.DaysIn(month)-1)
+		offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+
+		return last.AddDate(0, 0, -offset), true
+	}
+
+	offset := (int(weekday) - int(month.Weekday()) + 7) % 7
+	day := 1 + offset + 7*(ordinal-1)
+
+	if day > crosscuting.DaysIn(month) {
+		return time.Time{}, false
+	}
+
+	return month.AddDate(0, 0, day-1), true
+}
+
+// firstOfMonth returns the first day of the month of the date, keeping its time.
+func firstOfMonth(date time.Time) time.Time {
+	return date.AddDate(0, 0, 1-date.Day())
+}
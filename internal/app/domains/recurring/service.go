@@ -0,0 +1,296 @@
+package recurring
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/category"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+// MaxPreview is the maximum number of occurrences returned by a preview.
+const MaxPreview = 100
+
+var (
+	loggerService = logger.Setup("domain.recurring.service")
+	// ErrTemplateNotFound is returned when the template does not exist or belongs to another user.
+	ErrTemplateNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "RECURRING_TEMPLATE_NOT_FOUND", "recurring template not found error")
+	// ErrInvalidTemplate is returned when the fields of the template are not valid.
+	ErrInvalidTemplate = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_RECURRING_TEMPLATE", "invalid recurring template error")
+	// ErrInvalidSchedule is returned when the schedule of the template is not consistent.
+	ErrInvalidSchedule = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_SCHEDULE", "invalid schedule error")
+	// ErrOccurrenceNotFound is returned when the date is not an occurrence of the template.
+	ErrOccurrenceNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "OCCURRENCE_NOT_FOUND", "occurrence not found error")
+	// ErrOccurrenceTaken is returned when the occurrence was already posted or skipped.
+	ErrOccurrenceTaken = crosscuting.NewTypedError(crosscuting.ErrConflict, "OCCURRENCE_TAKEN", "occurrence already posted or skipped error")
+)
+
+// Service is the interface for the recurring transactions service.
+type Service interface {
+	Create(ctx context.Context, template Template) (Template, error)
+	Get(ctx context.Context, userID uint) ([]Template, error)
+	GetByID(ctx context.Context, userID, id uint) (Template, error)
+	Delete(ctx context.Context, userID, id uint) error
+	Preview(ctx context.Context, userID, id uint, from time.Time, n int) ([]Occurrence, error)
+	Skip(ctx context.Context, userID, id uint, date time.Time) error
+	Split(ctx context.Context, userID, id uint, date time.Time, patch TemplatePatch) (Template, error)
+}
+
+// ServiceImpl is the struct that contains the recurring transactions service.
+type ServiceImpl struct {
+	repo            Repository
+	ledgerService   ledger.Service
+	categoryService category.Service
+}
+
+// NewRecurringService creates a new recurring transactions service.
+func NewRecurringService(repo Repository, ledgerService ledger.Service, categoryService category.Service) Service {
+	return &ServiceImpl{repo: repo, ledgerService: ledgerService, categoryService: categoryService}
+}
+
+// Create validates and stores a template of the user, its first occurrence is materialized when due.
+func (s *ServiceImpl) Create(ctx context.Context, template Template) (Template, error) {
+	if err := s.validate(ctx, template); err != nil {
+		return Template{}, err
+	}
+
+	template.NextDate = firstOccurrence(template.Schedule, template.Schedule.StartDate)
+
+	created, err := s.repo.Create(ctx, template)
+	if err != nil {
+		loggerService.WithError(err).Error("Error creating the template")
+		return Template{}, err
+	}
+
+	return created, nil
+}
+
+// Get returns the templates of the user.
+func (s *ServiceImpl) Get(ctx context.Context, userID uint) ([]Template, error) {
+	templates, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the templates from the repo")
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// GetByID returns the template of the user with the given id.
+func (s *ServiceImpl) GetByID(ctx context.Context, userID, id uint) (Template, error) {
+	template, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the template from the repo")
+		return Template{}, err
+	}
+
+	return template, nil
+}
+
+// Delete soft deletes the template of the user, the transactions already created are kept.
+func (s *ServiceImpl) Delete(ctx context.Context, userID, id uint) error {
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		loggerService.WithError(err).Error("Error deleting the template")
+		return err
+	}
+
+	return nil
+}
+
+// Preview returns the next n occurrences of the template of the user at or after the date,
+// with the status of the ones already materialized or skipped.
+func (s *ServiceImpl) Preview(ctx context.Context, userID, id uint, from time.Time, n int) ([]Occurrence, error) {
+	if n < 1 || n > MaxPreview {
+		return nil, invalidTemplate(fmt.Sprintf("The number of occurrences must be between 1 and %d", MaxPreview))
+	}
+
+	template, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := template.Schedule.Next(from, n)
+	if len(dates) == 0 {
+		return []Occurrence{}, nil
+	}
+
+	stored, err := s.repo.GetOccurrences(ctx, template.ID, dates[0], dates[len(dates)-1])
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the occurrences from the repo")
+		return nil, err
+	}
+
+	byDate := make(map[time.Time]Occurrence, len(stored))
+	for _, occurrence := range stored {
+		byDate[occurrence.Date.UTC()] = occurrence
+	}
+
+	occurrences := make([]Occurrence, len(dates))
+	for i, date := range dates {
+		occurrence, ok := byDate[date.UTC()]
+		if !ok {
+			occurrence = Occurrence{TemplateID: template.ID, Date: date, Status: Scheduled}
+		}
+
+		occurrences[i] = occurrence
+	}
+
+	return occurrences, nil
+}
+
+// Skip marks an occurrence of the template of the user as skipped, so no transaction is created for it.
+func (s *ServiceImpl) Skip(ctx context.Context, userID, id uint, date time.Time) error {
+	template, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if !template.Schedule.Includes(date) {
+		return occurrenceNotFound(date)
+	}
+
+	if err := s.repo.SkipOccurrence(ctx, template.ID, date); err != nil {
+		loggerService.WithError(err).Error("Error skipping the occurrence")
+		return err
+	}
+
+	return nil
+}
+
+// Split applies the changes to the occurrence on the date and the following ones.
+// The template ends before the date and a new template with the changes starts on it, keeping the remaining
+// count and the skipped occurrences. The occurrence on the date can not be materialized already.
+func (s *ServiceImpl) Split(ctx context.Context, userID, id uint, date time.Time, patch TemplatePatch) (Template, error) {
+	if patch.IsEmpty() {
+		return Template{}, invalidTemplate("No fields to update")
+	}
+
+	template, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return Template{}, err
+	}
+
+	if !template.Schedule.Includes(date) {
+		return Template{}, occurrenceNotFound(date)
+	}
+
+	if template.NextDate == nil || date.Before(*template.NextDate) {
+		desc := "The occurrence was already materialized"
+		loggerService.WithError(ErrOccurrenceTaken).Error(desc)
+		return Template{}, fmt.Errorf(crosscuting.WrapLabel, desc, ErrOccurrenceTaken, date.Format(time.RFC3339))
+	}
+
+	next := template
+	next.Model = gorm.Model{}
+	next.Schedule.StartDate = date
+	applyPatch(&next, patch)
+
+	if patch.Count == nil && template.Schedule.Count != nil {
+		remaining := *template.Schedule.Count - template.Schedule.CountBefore(date)
+		next.Schedule.Count = &remaining
+	}
+
+	if err := s.validate(ctx, next); err != nil {
+		return Template{}, err
+	}
+
+	next.NextDate = firstOccurrence(next.Schedule, date)
+
+	created, err := s.repo.Split(ctx, userID, id, date.Add(-time.Nanosecond), next)
+	if err != nil {
+		loggerService.WithError(err).Error("Error splitting the template")
+		return Template{}, err
+	}
+
+	return created, nil
+}
+
+// validate checks the fields and the schedule of the template, and that its transactions would be valid.
+func (s *ServiceImpl) validate(ctx context.Context, template Template) error {
+	if strings.TrimSpace(template.Name) == "" {
+		return invalidTemplate("The name is required")
+	}
+
+	if err := template.Schedule.Validate(); err != nil {
+		loggerService.WithError(err).Error("Error validating the schedule")
+		return err
+	}
+
+	if template.CategoryID != nil {
+		if _, err := s.categoryService.GetByID(ctx, template.UserID, *template.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.ledgerService.ValidateTransaction(ctx, template.Transaction(template.Schedule.StartDate)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func applyPatch(template *Template, patch TemplatePatch) {
+	if patch.Name != nil {
+		template.Name = *patch.Name
+	}
+
+	if patch.Description != nil {
+		template.Description = *patch.Description
+	}
+
+	if patch.CategoryID != nil {
+		template.CategoryID = patch.CategoryID
+	}
+
+	if patch.Postings != nil {
+		template.Postings = patch.Postings
+	}
+
+	if patch.Frequency != nil {
+		template.Schedule.Frequency = *patch.Frequency
+	}
+
+	if patch.Interval != nil {
+		template.Schedule.Interval = *patch.Interval
+	}
+
+	if patch.ByDay != nil {
+		template.Schedule.ByDay = *patch.ByDay
+	}
+
+	if patch.EndDate != nil {
+		template.Schedule.EndDate = patch.EndDate
+	}
+
+	if patch.Count != nil {
+		template.Schedule.Count = patch.Count
+	}
+}
+
+// firstOccurrence returns the first occurrence of the schedule at or after the date, nil if there is none.
+func firstOccurrence(schedule Schedule, date time.Time) *time.Time {
+	dates := schedule.Next(date, 1)
+	if len(dates) == 0 {
+		return nil
+	}
+
+	return &dates[0]
+}
+
+func occurrenceNotFound(date time.Time) error {
+	desc := "The date is not an occurrence of the template"
+	loggerService.WithError(ErrOccurrenceNotFound).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabel, desc, ErrOccurrenceNotFound, date.Format(time.RFC3339))
+}
+
+func invalidTemplate(desc string) error {
+	loggerService.WithError(ErrInvalidTemplate).Error(desc)
+
+	return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidTemplate)
+}
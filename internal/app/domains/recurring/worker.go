@@ -0,0 +1,172 @@
+package recurring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/category"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerWorker       = logger.Setup("domain.recurring.worker")
+	errInvalidInterval = errors.New("invalid interval error")
+)
+
+// Worker is the interface for the periodic materialization of the recurring transactions.
+type Worker interface {
+	Run(ctx context.Context)
+	RunOnce(ctx context.Context)
+}
+
+// WorkerImpl is the struct that contains the dependencies of the materialization.
+// The database runs the units of work that post each occurrence with its transaction.
+type WorkerImpl struct {
+	db              gorm.Gorm
+	repo            Repository
+	ledgerService   ledger.Service
+	categoryService category.Service
+	interval        time.Duration
+}
+
+// NewWorker creates a new worker that materializes the due occurrences every interval, which must be positive.
+func NewWorker(db gorm.Gorm, repo Repository, ledgerService ledger.Service, categoryService category.Service, interval time.Duration) (Worker, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("The recurring interval %s must be positive", interval), errInvalidInterval)
+	}
+
+	return &WorkerImpl{db: db, repo: repo, ledgerService: ledgerService, categoryService: categoryService, interval: interval}, nil
+}
+
+// Run materializes the due occurrences right away and then every interval until the context is done.
+func (w *WorkerImpl) Run(ctx context.Context) {
+	loggerWorker.Infof("Starting the recurring transactions every %s", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			loggerWorker.Info("Stopping the recurring transactions")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce materializes the occurrences of all the templates that are due now.
+func (w *WorkerImpl) RunOnce(ctx context.Context) {
+	now := time.Now()
+
+	templates, err := w.repo.GetDue(ctx, now)
+	if err != nil {
+		loggerWorker.WithError(err).Error("Error getting the due templates")
+		return
+	}
+
+	for _, template := range templates {
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.materialize(ctx, template, now)
+	}
+}
+
+// materialize creates the transactions of the due occurrences of the template and moves its next date.
+// The next date stays on the first failed occurrence, so it is retried in the next run.
+func (w *WorkerImpl) materialize(ctx context.Context, template Template, now time.Time) {
+	log := loggerWorker.WithField("block", "materialize").WithField("template_id", template.ID)
+
+	var firstFailed *time.Time
+
+	// The category may have been deleted since the template was created.
+	if template.CategoryID != nil {
+		if _, err := w.categoryService.GetByID(ctx, template.UserID, *template.CategoryID); err != nil {
+			log.WithError(err).Warn("Materializing without the category of the template")
+			template.CategoryID = nil
+		}
+	}
+
+	for _, date := range template.Schedule.Between(*template.NextDate, now) {
+		err := w.post(ctx, template, date)
+		if err == nil {
+			continue
+		}
+
+		var txnErr *transactionError
+		if !errors.As(err, &txnErr) {
+			log.WithError(err).Errorf("Error posting the occurrence of %s", date.Format(time.RFC3339))
+			return
+		}
+
+		log.WithError(txnErr.err).Errorf("Error creating the transaction of %s", date.Format(time.RFC3339))
+
+		// The failure is recorded even when the worker is stopping, so the occurrence shows why it is late.
+		if err := w.repo.FailOccurrence(context.WithoutCancel(ctx), template.ID, date, txnErr.err.Error()); err != nil {
+			log.WithError(err).Error("Error recording the failed occurrence")
+			return
+		}
+
+		if firstFailed == nil {
+			failedDate := date
+			firstFailed = &failedDate
+		}
+	}
+
+	nextDate := firstFailed
+	if nextDate == nil {
+		nextDate = firstOccurrence(template.Schedule, now.Add(time.Nanosecond))
+	}
+
+	if err := w.repo.SetNextDate(ctx, template.ID, nextDate); err != nil {
+		log.WithError(err).Error("Error updating the next date of the template")
+	}
+}
+
+// post claims the occurrence of the template at the date and creates its transaction in one unit of work.
+// The occurrence is only posted along with its transaction, so a worker dying halfway leaves neither of them,
+// and the occurrences already posted or skipped are not claimed again. The errors of the transaction itself
+// are returned as a transactionError, after the unit of work is rolled back.
+func (w *WorkerImpl) post(ctx context.Context, template Template, date time.Time) error {
+	return w.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		repo := w.repo.WithTx(tx)
+
+		occurrence, claimed, err := repo.ClaimOccurrence(ctx, template.ID, date)
+		if err != nil || !claimed {
+			return err
+		}
+
+		txn, err := w.ledgerService.WithTx(tx).CreateTransaction(ctx, template.Transaction(date))
+		if err != nil {
+			return &transactionError{err: err}
+		}
+
+		occurrence.Status = Posted
+		occurrence.TransactionID = &txn.ID
+		occurrence.Error = ""
+
+		return repo.UpdateOccurrence(ctx, occurrence)
+	})
+}
+
+// transactionError is the error of the transaction of an occurrence, apart from the errors of storing it.
+type transactionError struct {
+	err error
+}
+
+func (e *transactionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transactionError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,113 @@
+package recurring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/shopspring/decimal"
+)
+
+func TestWorkerRunOnce(t *testing.T) {
+	ctx := context.Background()
+	db := gorm.NewMemoryGorm()
+
+	finAssetService := finasset.NewFinAssetService(finasset.NewCurrencyRepository(db))
+	accountService := account.NewAccountService(account.NewAccountRepository(db), finAssetService)
+	ledgerService := ledger.NewLedgerService(ledger.NewLedgerRepository(db), accountService, finAssetService)
+	repo := NewRecurringRepository(db)
+
+	minorUnits := 2
+	usd := finasset.FinancialAsset{Symbol: "USD", Name: "US dollar", Type: finasset.Currency, Metadata: finasset.Metadata{NumericCode: "840", MinorUnits: &minorUnits}}
+
+	if err := finAssetService.Create(ctx, usd); err != nil {
+		t.Fatalf("Create() of the currency error = %v", err)
+	}
+
+	var ids []uint
+
+	for _, accountType := range []account.AccountType{account.Checking, account.Expense} {
+		acc, err := accountService.Create(ctx, account.Account{UserID: 1, AssetID: 1, Name: string(accountType), Type: accountType})
+		if err != nil {
+			t.Fatalf("Create() of the %s account error = %v", accountType, err)
+		}
+
+		ids = append(ids, acc.ID)
+	}
+
+	start := time.Now().Add(-48 * time.Hour)
+	schedule := Schedule{Frequency: Daily, Interval: 1, StartDate: start}
+
+	create := func(name string, expenseID uint) Template {
+		t.Helper()
+
+		template, err := repo.Create(ctx, Template{
+			UserID: 1, Name: name, Kind: ledger.Expense, Schedule: schedule, NextDate: &start,
+			Postings: TemplatePostings{
+				{AccountID: ids[0], Amount: decimal.NewFromInt(-10)},
+				{AccountID: expenseID, Amount: decimal.NewFromInt(10)},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Create() of the template error = %v", err)
+		}
+
+		return template
+	}
+
+	rent := create("Rent", ids[1])
+	// The postings of the broken template use an account that does not exist.
+	broken := create("Broken", 99)
+
+	worker, err := NewWorker(db, repo, ledgerService, nil, time.Hour)
+	if err != nil {
+		t.Fatalf("NewWorker() error = %v", err)
+	}
+
+	worker.RunOnce(ctx)
+
+	// Running again from the start date does not post the occurrences twice.
+	if err := repo.SetNextDate(ctx, rent.ID, &start); err != nil {
+		t.Fatalf("SetNextDate() error = %v", err)
+	}
+
+	worker.RunOnce(ctx)
+
+	txns, err := ledgerService.GetTransactions(ctx, 1, ledger.TransactionFilter{})
+	if err != nil || len(txns) != 3 {
+		t.Fatalf("GetTransactions() = %d transactions, %v, want the 3 occurrences of the rent", len(txns), err)
+	}
+
+	tests := []struct {
+		name       string
+		template   Template
+		wantStatus OccurrenceStatus
+	}{
+		{name: "posted with their transaction", template: rent, wantStatus: Posted},
+		{name: "failed without a transaction", template: broken, wantStatus: Failed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences, err := repo.GetOccurrences(ctx, tt.template.ID, start, time.Now())
+			if err != nil || len(occurrences) != 3 {
+				t.Fatalf("GetOccurrences() = %d occurrences, %v, want 3", len(occurrences), err)
+			}
+
+			for _, occurrence := range occurrences {
+				if occurrence.Status != tt.wantStatus || (occurrence.TransactionID != nil) != (tt.wantStatus == Posted) {
+					t.Errorf("occurrence of %s = %s with the transaction %v, want %s", occurrence.Date, occurrence.Status, occurrence.TransactionID, tt.wantStatus)
+				}
+			}
+		})
+	}
+
+	template, err := repo.GetByID(ctx, 1, broken.ID)
+	if err != nil || template.NextDate == nil || !template.NextDate.Equal(start) {
+		t.Errorf("the next date of the failed template = %v, %v, want the first failed occurrence %v", template.NextDate, err, start)
+	}
+}
@@ -1,14 +1,38 @@
 package user
 
-import "github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+import (
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+)
 
 type (
 	// User is the struct for the user.
 	User struct {
 		gorm.Model
 		Name     string `json:"name" gorm:"not null"`
-		Email    string `json:"email" gorm:"not null;unique"`
+		Email    string `json:"email" gorm:"not null"`
 		Currency string `json:"currency" gorm:"not null"`
 		Password string `json:"password" gorm:"not null"`
 	}
+
+	// Session is the struct for the tokens issued to an authenticated user.
+	Session struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+
+	// RefreshToken is the struct for a refresh token issued to a user.
+	// Only the hash of the token is stored, all the tokens rotated from the same login share the family.
+	RefreshToken struct {
+		gorm.Model
+		UserID    uint       `json:"user_id" gorm:"not null"`
+		FamilyID  string     `json:"-" gorm:"not null"`
+		TokenHash string     `json:"-" gorm:"not null;unique"`
+		ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+		RotatedAt *time.Time `json:"-"`
+		RevokedAt *time.Time `json:"-"`
+	}
 )
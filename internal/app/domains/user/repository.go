@@ -1,6 +1,12 @@
 package user
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
 	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
 	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
 )
@@ -9,8 +15,15 @@ var loggerRepo = logger.Setup("domain.user.repository")
 
 // Repository is the interface for the user repository.
 type Repository interface {
-	FindByEmail(email string) (User, error)
-	Create(user User) error
+	FindByEmail(ctx context.Context, email string) (User, error)
+	FindByID(ctx context.Context, id uint) (User, error)
+	FindAll(ctx context.Context) ([]User, error)
+	Create(ctx context.Context, user User) (User, error)
+	CreateRefreshToken(ctx context.Context, token RefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, hash string) (RefreshToken, bool, error)
+	MarkRefreshTokenRotated(ctx context.Context, id uint) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	WithTx(tx gorm.Gorm) Repository
 }
 
 // RepositoryImpl is the struct that contains the user repository.
@@ -23,10 +36,15 @@ func NewUserRepository(db gorm.Gorm) Repository {
 	return &RepositoryImpl{db: db}
 }
 
+// WithTx returns the repository bound to the transaction, its operations run inside it.
+func (r *RepositoryImpl) WithTx(tx gorm.Gorm) Repository {
+	return &RepositoryImpl{db: tx}
+}
+
 // FindByEmail finds a user by email.
-func (r *RepositoryImpl) FindByEmail(email string) (User, error) {
+func (r *RepositoryImpl) FindByEmail(ctx context.Context, email string) (User, error) {
 	var user User
-	if err := r.db.WhereFirst(&user, "email = ?", email); err != nil {
+	if err := r.db.WhereFirst(ctx, &user, "email = ?", email); err != nil {
 		loggerRepo.WithError(err).Error("Error querying the user by email")
 
 		return User{}, err
@@ -35,11 +53,93 @@ func (r *RepositoryImpl) FindByEmail(email string) (User, error) {
 	return user, nil
 }
 
-// Create creates a new user.
-func (r *RepositoryImpl) Create(user User) error {
-	if err := r.db.Create(&user); err != nil {
+// FindByID finds a user by id.
+func (r *RepositoryImpl) FindByID(ctx context.Context, id uint) (User, error) {
+	var user User
+	if err := r.db.WhereFirst(ctx, &user, "id = ?", id); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the user by id")
+
+		if errors.Is(err, crosscuting.ErrNotFound) {
+			return User{}, fmt.Errorf(crosscuting.WrapLabel, "User not found", ErrUserNotFound, err.Error())
+		}
+
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// FindAll returns all the users sorted by id.
+func (r *RepositoryImpl) FindAll(ctx context.Context) ([]User, error) {
+	var users []User
+	if err := r.db.Order("id ASC").WhereFind(ctx, &users, "id > ?", 0); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the users")
+
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Create creates a new user and returns it with its id.
+func (r *RepositoryImpl) Create(ctx context.Context, user User) (User, error) {
+	if err := r.db.Create(ctx, &user); err != nil {
 		loggerRepo.WithError(err).Error("Error creating record in the database")
 
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// CreateRefreshToken creates a new refresh token.
+func (r *RepositoryImpl) CreateRefreshToken(ctx context.Context, token RefreshToken) error {
+	if err := r.db.Create(ctx, &token); err != nil {
+		loggerRepo.WithError(err).Error("Error creating the refresh token in the database")
+
+		return err
+	}
+
+	return nil
+}
+
+// FindRefreshTokenByHash finds a refresh token by the hash of its value.
+// The bool result reports if the token exists.
+func (r *RepositoryImpl) FindRefreshTokenByHash(ctx context.Context, hash string) (RefreshToken, bool, error) {
+	var tokens []RefreshToken
+	if err := r.db.WhereFind(ctx, &tokens, "token_hash = ?", hash); err != nil {
+		loggerRepo.WithError(err).Error("Error querying the refresh token by hash")
+
+		return RefreshToken{}, false, err
+	}
+
+	if len(tokens) == 0 {
+		return RefreshToken{}, false, nil
+	}
+
+	return tokens[0], true, nil
+}
+
+// MarkRefreshTokenRotated marks the refresh token as rotated if it was not rotated or revoked yet.
+// The bool result is false when another request already rotated or revoked the token.
+func (r *RepositoryImpl) MarkRefreshTokenRotated(ctx context.Context, id uint) (bool, error) {
+	rows, err := r.db.WhereUpdates(ctx, &RefreshToken{}, map[string]interface{}{"rotated_at": time.Now()},
+		"id = ? AND rotated_at IS NULL AND revoked_at IS NULL", id)
+	if err != nil {
+		loggerRepo.WithError(err).Error("Error marking the refresh token as rotated")
+
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// RevokeRefreshTokenFamily revokes all the active refresh tokens of the family.
+func (r *RepositoryImpl) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	if _, err := r.db.WhereUpdates(ctx, &RefreshToken{}, map[string]interface{}{"revoked_at": time.Now()},
+		"family_id = ? AND revoked_at IS NULL", familyID); err != nil {
+		loggerRepo.WithError(err).Error("Error revoking the refresh token family")
+
 		return err
 	}
 
@@ -1,39 +1,68 @@
 package user
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/app/domains/category"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/jwt"
 	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	loggerService   = logger.Setup("domain.user.service")
-	errHash         = errors.New("hash error")
-	errValidateUser = errors.New("validate user error")
+	loggerService = logger.Setup("domain.user.service")
+	errHash       = errors.New("hash error")
+	errRandom     = errors.New("random generation error")
+	// ErrUserExists is returned when the email is already registered.
+	ErrUserExists = crosscuting.NewTypedError(crosscuting.ErrConflict, "USER_EMAIL_TAKEN", "user already exists error")
+	// ErrUserNotFound is returned when the user does not exist.
+	ErrUserNotFound = crosscuting.NewTypedError(crosscuting.ErrNotFound, "USER_NOT_FOUND", "user not found error")
+	// ErrInvalidCredentials is returned when the email or the password are wrong.
+	ErrInvalidCredentials = crosscuting.NewTypedError(crosscuting.ErrUnauthorized, "INVALID_CREDENTIALS", "invalid credentials error")
+	// ErrInvalidRefreshToken is returned when the refresh token is unknown, expired, revoked or reused.
+	ErrInvalidRefreshToken = crosscuting.NewTypedError(crosscuting.ErrUnauthorized, "INVALID_REFRESH_TOKEN", "invalid refresh token error")
 )
 
+const tokenType = "Bearer"
+
 // UserService is the interface for the user service.
 type Service interface {
-	Signup(user User) error
+	Signup(ctx context.Context, user User) error
+	GetByID(ctx context.Context, id uint) (User, error)
+	GetAll(ctx context.Context) ([]User, error)
+	Login(ctx context.Context, email, password string) (Session, error)
+	Refresh(ctx context.Context, refreshToken string) (Session, error)
+	Logout(ctx context.Context, refreshToken string) error
 }
 
 // ServiceImpl is the struct that contains the user service.
+// The database runs the units of work that span the user and the data seeded for them.
 type ServiceImpl struct {
-	repo Repository
+	db              gorm.Gorm
+	repo            Repository
+	tokens          jwt.JWT
+	refreshTTL      time.Duration
+	categoryService category.Service
 }
 
 // NewUserService creates a new user service.
-func NewUserService(repo Repository) Service {
-	return &ServiceImpl{repo: repo}
+func NewUserService(db gorm.Gorm, repo Repository, tokens jwt.JWT, refreshTTL time.Duration, categoryService category.Service) Service {
+	return &ServiceImpl{db: db, repo: repo, tokens: tokens, refreshTTL: refreshTTL, categoryService: categoryService}
 }
 
-// Signup creates a new user.
-func (s *ServiceImpl) Signup(user User) error {
-	existingUser, err := s.repo.FindByEmail(user.Email)
-	if err != nil {
+// Signup creates a new user with the default categories.
+func (s *ServiceImpl) Signup(ctx context.Context, user User) error {
+	existingUser, err := s.repo.FindByEmail(ctx, user.Email)
+	if err != nil && !errors.Is(err, crosscuting.ErrNotFound) {
 		loggerService.WithError(err).Error("Error finding the user by email")
 
 		return err
@@ -41,9 +70,9 @@ func (s *ServiceImpl) Signup(user User) error {
 
 	if existingUser.Email != "" {
 		desc := "User already exists"
-		loggerService.WithError(errValidateUser).Error(desc)
+		loggerService.WithError(ErrUserExists).Error(desc)
 
-		return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, errValidateUser)
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrUserExists)
 	}
 
 	hashedPassword, err := hashPassword(user.Password)
@@ -53,8 +82,134 @@ func (s *ServiceImpl) Signup(user User) error {
 
 	user.Password = hashedPassword
 
-	if err := s.repo.Create(user); err != nil {
-		loggerService.WithError(err).Error("Error creating the user")
+	// The user is not created without their default categories.
+	return s.db.Transaction(ctx, func(tx gorm.Gorm) error {
+		created, err := s.repo.WithTx(tx).Create(ctx, user)
+		if err != nil {
+			loggerService.WithError(err).Error("Error creating the user")
+
+			return err
+		}
+
+		if err := s.categoryService.WithTx(tx).SeedDefaults(ctx, created.ID); err != nil {
+			loggerService.WithError(err).Error("Error seeding the default categories of the user")
+
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetByID returns the user with the given id.
+func (s *ServiceImpl) GetByID(ctx context.Context, id uint) (User, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the user from the repo")
+
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// GetAll returns all the users.
+func (s *ServiceImpl) GetAll(ctx context.Context) ([]User, error) {
+	users, err := s.repo.FindAll(ctx)
+	if err != nil {
+		loggerService.WithError(err).Error("Error getting the users from the repo")
+
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Login verifies the credentials of the user and issues a new access token.
+func (s *ServiceImpl) Login(ctx context.Context, email, password string) (Session, error) {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if errors.Is(err, crosscuting.ErrNotFound) {
+		desc := "Wrong email or password"
+		loggerService.WithError(err).Error(desc)
+
+		return Session{}, fmt.Errorf(crosscuting.WrapLabel, desc, ErrInvalidCredentials, err.Error())
+	}
+
+	if err != nil {
+		loggerService.WithError(err).Error("Error finding the user by email")
+
+		return Session{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		desc := "Wrong email or password"
+		loggerService.WithError(err).Error(desc)
+
+		return Session{}, fmt.Errorf(crosscuting.WrapLabel, desc, ErrInvalidCredentials, err.Error())
+	}
+
+	familyID, err := randomString(16)
+	if err != nil {
+		return Session{}, err
+	}
+
+	return s.newSession(ctx, user.ID, familyID)
+}
+
+// Refresh rotates the refresh token and issues a new session.
+// If an already rotated token is used again, the whole family is revoked.
+func (s *ServiceImpl) Refresh(ctx context.Context, refreshToken string) (Session, error) {
+	token, err := s.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return Session{}, err
+	}
+
+	if token.RotatedAt != nil {
+		desc := "Refresh token reused, revoking the family"
+		loggerService.WithError(ErrInvalidRefreshToken).Error(desc)
+
+		if err := s.repo.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+			loggerService.WithError(err).Error("Error revoking the refresh token family")
+
+			return Session{}, err
+		}
+
+		return Session{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidRefreshToken)
+	}
+
+	rotated, err := s.repo.MarkRefreshTokenRotated(ctx, token.ID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error rotating the refresh token")
+
+		return Session{}, err
+	}
+
+	if !rotated {
+		// Another request rotated the same token at the same time, so it is a reuse too.
+		desc := "Refresh token rotated concurrently, revoking the family"
+		loggerService.WithError(ErrInvalidRefreshToken).Error(desc)
+
+		if err := s.repo.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+			loggerService.WithError(err).Error("Error revoking the refresh token family")
+
+			return Session{}, err
+		}
+
+		return Session{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidRefreshToken)
+	}
+
+	return s.newSession(ctx, token.UserID, token.FamilyID)
+}
+
+// Logout revokes the family of the given refresh token.
+func (s *ServiceImpl) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+		loggerService.WithError(err).Error("Error revoking the refresh token family")
 
 		return err
 	}
@@ -62,6 +217,66 @@ func (s *ServiceImpl) Signup(user User) error {
 	return nil
 }
 
+// findRefreshToken returns the stored refresh token if it exists, is not revoked and is not expired.
+func (s *ServiceImpl) findRefreshToken(ctx context.Context, refreshToken string) (RefreshToken, error) {
+	token, found, err := s.repo.FindRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		loggerService.WithError(err).Error("Error finding the refresh token")
+
+		return RefreshToken{}, err
+	}
+
+	var desc string
+
+	switch {
+	case !found:
+		desc = "Refresh token not found"
+	case token.RevokedAt != nil:
+		desc = "Refresh token revoked"
+	case time.Now().After(token.ExpiresAt):
+		desc = "Refresh token expired"
+	default:
+		return token, nil
+	}
+
+	loggerService.WithError(ErrInvalidRefreshToken).Error(desc)
+
+	return RefreshToken{}, fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrInvalidRefreshToken)
+}
+
+// newSession issues a new access token and a new refresh token of the given family.
+func (s *ServiceImpl) newSession(ctx context.Context, userID uint, familyID string) (Session, error) {
+	accessToken, err := s.tokens.Generate(userID)
+	if err != nil {
+		loggerService.WithError(err).Error("Error generating the access token")
+
+		return Session{}, err
+	}
+
+	refreshToken, err := randomString(32)
+	if err != nil {
+		return Session{}, err
+	}
+
+	if err := s.repo.CreateRefreshToken(ctx, RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}); err != nil {
+		loggerService.WithError(err).Error("Error storing the refresh token")
+
+		return Session{}, err
+	}
+
+	return Session{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    tokenType,
+		ExpiresIn:    int(s.tokens.TTL().Seconds()),
+	}, nil
+}
+
 func hashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	if err != nil {
@@ -73,3 +288,23 @@ func hashPassword(password string) (string, error) {
 
 	return string(bytes), nil
 }
+
+// randomString returns a url safe string of n random bytes.
+func randomString(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		desc := "Error generating random bytes"
+		loggerService.WithError(err).Error(desc)
+
+		return "", fmt.Errorf(crosscuting.WrapLabel, desc, errRandom, err.Error())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// hashToken returns the hex encoded sha256 of the token, only this hash is stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
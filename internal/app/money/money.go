@@ -0,0 +1,161 @@
+// Package money has the exact decimal amounts used by all the financial fields of the domains.
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrCurrencyMismatch is returned when operating amounts of different currencies.
+	ErrCurrencyMismatch = crosscuting.NewTypedError(crosscuting.ErrValidation, "CURRENCY_MISMATCH", "currency mismatch error")
+	// ErrInvalidAmount is returned when the amount is not a decimal number.
+	ErrInvalidAmount = crosscuting.NewTypedError(crosscuting.ErrValidation, "INVALID_AMOUNT", "invalid amount error")
+)
+
+// Money is an exact amount of a currency asset, referenced by its symbol.
+// Embedded in a gorm model it is stored in a NUMERIC column for the amount and a column for the currency:
+//
+//	Balance money.Money `gorm:"embedded;embeddedPrefix:balance_"`
+type Money struct {
+	Amount   decimal.Decimal `json:"amount" gorm:"type:numeric;not null"`
+	Currency string          `json:"currency" gorm:"not null"`
+}
+
+// New creates an amount of the currency.
+func New(amount decimal.Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Zero returns the zero amount of the currency.
+func Zero(currency string) Money {
+	return Money{Amount: decimal.Zero, Currency: currency}
+}
+
+// Parse creates an amount of the currency from its decimal representation.
+func Parse(amount, currency string) (Money, error) {
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return Money{}, fmt.Errorf(crosscuting.WrapLabel, "Invalid amount", ErrInvalidAmount, err.Error())
+	}
+
+	return New(value, currency), nil
+}
+
+// Add returns the sum of the amounts, they must be of the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	return New(m.Amount.Add(other.Amount), m.Currency), nil
+}
+
+// Sub returns the difference of the amounts, they must be of the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	return New(m.Amount.Sub(other.Amount), m.Currency), nil
+}
+
+// Cmp compares the amounts, they must be of the same currency.
+// It returns -1 if m is less than other, 0 if they are equal and 1 if m is greater.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	return m.Amount.Cmp(other.Amount), nil
+}
+
+// Mul returns the amount multiplied by the factor, in the same currency.
+func (m Money) Mul(factor decimal.Decimal) Money {
+	return New(m.Amount.Mul(factor), m.Currency)
+}
+
+// Neg returns the amount with the opposite sign.
+func (m Money) Neg() Money {
+	return New(m.Amount.Neg(), m.Currency)
+}
+
+// Convert returns the amount in another currency with the given rate, without rounding.
+func (m Money) Convert(rate decimal.Decimal, currency string) Money {
+	return New(m.Amount.Mul(rate), currency)
+}
+
+// Round returns the amount rounded half away from zero to the minor units of its currency.
+func (m Money) Round(minorUnits int32) Money {
+	return New(m.Amount.Round(minorUnits), m.Currency)
+}
+
+// RoundBank returns the amount rounded half to even to the minor units of its currency.
+func (m Money) RoundBank(minorUnits int32) Money {
+	return New(m.Amount.RoundBank(minorUnits), m.Currency)
+}
+
+// IsZero reports if the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// IsNegative reports if the amount is less than zero.
+func (m Money) IsNegative() bool {
+	return m.Amount.IsNegative()
+}
+
+// String returns the amount followed by the currency.
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
+
+// MarshalJSON marshals the amount as a string to keep its precision.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{Amount: m.Amount.String(), Currency: m.Currency})
+}
+
+// UnmarshalJSON accepts the amount as a string or as a number.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Amount   decimal.Decimal `json:"amount"`
+		Currency string          `json:"currency"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*m = New(raw.Amount, raw.Currency)
+
+	return nil
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		desc := fmt.Sprintf("Can not operate %s with %s", m.Currency, other.Currency)
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, desc, ErrCurrencyMismatch)
+	}
+
+	return nil
+}
+
+// Sum returns the sum of the amounts, all of them must be of the currency.
+func Sum(currency string, amounts ...Money) (Money, error) {
+	total := Zero(currency)
+
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+
+	return total, nil
+}
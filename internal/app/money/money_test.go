@@ -0,0 +1,133 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMoneyAddSub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		wantAdd string
+		wantSub string
+		wantErr bool
+	}{
+		{name: "same currency", a: New(decimal.RequireFromString("10.25"), "USD"), b: New(decimal.RequireFromString("0.75"), "USD"), wantAdd: "11 USD", wantSub: "9.5 USD"},
+		{name: "negative result", a: Zero("EUR"), b: New(decimal.RequireFromString("0.1"), "EUR"), wantAdd: "0.1 EUR", wantSub: "-0.1 EUR"},
+		{name: "mismatched currencies", a: New(decimal.NewFromInt(1), "USD"), b: New(decimal.NewFromInt(1), "EUR"), wantErr: true},
+		{name: "zero of another currency", a: New(decimal.NewFromInt(1), "USD"), b: Zero("EUR"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, addErr := tt.a.Add(tt.b)
+			difference, subErr := tt.a.Sub(tt.b)
+
+			if tt.wantErr {
+				if !errors.Is(addErr, ErrCurrencyMismatch) || !errors.Is(subErr, ErrCurrencyMismatch) {
+					t.Errorf("Add() error = %v and Sub() error = %v, want %v", addErr, subErr, ErrCurrencyMismatch)
+				}
+
+				return
+			}
+
+			if addErr != nil || subErr != nil {
+				t.Fatalf("Add() error = %v and Sub() error = %v, want nil", addErr, subErr)
+			}
+
+			if sum.String() != tt.wantAdd || difference.String() != tt.wantSub {
+				t.Errorf("Add() = %s and Sub() = %s, want %s and %s", sum, difference, tt.wantAdd, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		name          string
+		amount        string
+		minorUnits    int32
+		wantRound     string
+		wantRoundBank string
+	}{
+		{name: "half to an odd digit", amount: "2.345", minorUnits: 2, wantRound: "2.35", wantRoundBank: "2.34"},
+		{name: "half to an even digit", amount: "2.355", minorUnits: 2, wantRound: "2.36", wantRoundBank: "2.36"},
+		{name: "negative half", amount: "-2.345", minorUnits: 2, wantRound: "-2.35", wantRoundBank: "-2.34"},
+		{name: "half without minor units", amount: "0.5", minorUnits: 0, wantRound: "1", wantRoundBank: "0"},
+		{name: "not half way", amount: "1.2349", minorUnits: 2, wantRound: "1.23", wantRoundBank: "1.23"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount := New(decimal.RequireFromString(tt.amount), "USD")
+
+			round, roundBank := amount.Round(tt.minorUnits), amount.RoundBank(tt.minorUnits)
+			if round.Amount.String() != tt.wantRound || roundBank.Amount.String() != tt.wantRoundBank {
+				t.Errorf("Round() = %s and RoundBank() = %s, want %s and %s", round.Amount, roundBank.Amount, tt.wantRound, tt.wantRoundBank)
+			}
+
+			if round.Currency != "USD" || roundBank.Currency != "USD" {
+				t.Errorf("the rounded amounts changed the currency to %s and %s", round.Currency, roundBank.Currency)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     Money
+		wantJSON string
+		wantErr  bool
+	}{
+		{
+			name:     "amount as a string",
+			data:     `{"amount":"0.1000000000000000055","currency":"BTC"}`,
+			want:     New(decimal.RequireFromString("0.1000000000000000055"), "BTC"),
+			wantJSON: `{"amount":"0.1000000000000000055","currency":"BTC"}`,
+		},
+		{
+			name:     "amount as a number",
+			data:     `{"amount":-12.50,"currency":"USD"}`,
+			want:     New(decimal.RequireFromString("-12.5"), "USD"),
+			wantJSON: `{"amount":"-12.5","currency":"USD"}`,
+		},
+		{name: "amount not a number", data: `{"amount":"ten","currency":"USD"}`, wantErr: true},
+		{name: "amount not a scalar", data: `{"amount":{},"currency":"USD"}`, wantErr: true},
+		{name: "not an object", data: `"10 USD"`, wantErr: true},
+		{name: "malformed", data: `{"amount":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("json.Unmarshal() = %s, want an error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+
+			if !got.Amount.Equal(tt.want.Amount) || got.Currency != tt.want.Currency {
+				t.Errorf("json.Unmarshal() = %s, want %s", got, tt.want)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil || string(data) != tt.wantJSON {
+				t.Errorf("json.Marshal() = %s, %v, want %s", data, err, tt.wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+
+	"github.com/jho3r/finanger-back/internal/app/domains/account"
+	"github.com/jho3r/finanger-back/internal/app/domains/budget"
+	"github.com/jho3r/finanger-back/internal/app/domains/category"
+	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
+	"github.com/jho3r/finanger-back/internal/app/domains/fx"
+	"github.com/jho3r/finanger-back/internal/app/domains/holding"
+	"github.com/jho3r/finanger-back/internal/app/domains/importer"
+	"github.com/jho3r/finanger-back/internal/app/domains/ingestion"
+	"github.com/jho3r/finanger-back/internal/app/domains/ledger"
+	"github.com/jho3r/finanger-back/internal/app/domains/networth"
+	"github.com/jho3r/finanger-back/internal/app/domains/portfolio"
+	"github.com/jho3r/finanger-back/internal/app/domains/recurring"
+	"github.com/jho3r/finanger-back/internal/app/domains/user"
+	"github.com/jho3r/finanger-back/internal/app/settings"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/migrations"
+	"github.com/jho3r/finanger-back/internal/infrastructure/database/migrator"
+	"github.com/jho3r/finanger-back/internal/infrastructure/jwt"
+)
+
+// dependencies contains the infrastructure, repos and services shared by the server and the admin commands.
+type dependencies struct {
+	db     gorm.Gorm
+	tokens jwt.JWT
+
+	ingestionRepo ingestion.Repository
+	recurringRepo recurring.Repository
+
+	userService      user.Service
+	finAssetService  finasset.Service
+	fxService        fx.Service
+	accountService   account.Service
+	ledgerService    ledger.Service
+	categoryService  category.Service
+	budgetService    budget.Service
+	recurringService recurring.Service
+	holdingService   holding.Service
+	portfolioService portfolio.Service
+	netWorthService  networth.Service
+	importerService  importer.Service
+}
+
+// Admin contains the services used by the admin commands.
+type Admin struct {
+	NetWorthService networth.Service
+}
+
+// SetupAdmin connects to the database and returns the services used by the admin commands.
+func SetupAdmin() Admin {
+	deps := setupDependencies()
+
+	return Admin{NetWorthService: deps.netWorthService}
+}
+
+// SetupMigrator connects to the database and returns the migrator of the embedded migrations.
+func SetupMigrator() migrator.Migrator {
+	db, err := gorm.NewSQLDB(settings.Database.ConnURL)
+	if err != nil {
+		loggerServer.WithError(err).Fatal("Error connecting to the database")
+	}
+
+	dbMigrator, err := migrator.NewMigrator(db, migrations.FS)
+	if err != nil {
+		loggerServer.WithError(err).Fatal("Error reading the migrations")
+	}
+
+	return dbMigrator
+}
+
+// autoMigrate applies the pending migrations before starting the server.
+// The migrator holds an advisory lock, so the replicas starting at once apply them one time.
+func autoMigrate() {
+	dbMigrator := SetupMigrator()
+	defer dbMigrator.Close()
+
+	applied, err := dbMigrator.Up(context.Background())
+	if err != nil {
+		loggerServer.WithError(err).Fatal("Error applying the migrations")
+	}
+
+	loggerServer.Infof("Applied %d migrations", len(applied))
+}
+
+// setupDependencies connects to the database and creates the repos and the services.
+func setupDependencies() dependencies {
+	// Infrastructure
+	gormDB := gorm.NewGormDB(settings.Database.ConnURL, settings.Database.MaxIdle, settings.Database.MaxOpen, settings.Database.QueryTimeout)
+	tokens := jwt.NewJWT(settings.Auth.JWTSecret, settings.Auth.JWTIssuer, settings.Auth.AccessTokenTTL)
+
+	// Repos
+	userRepo := user.NewUserRepository(gormDB)
+	finAssetRepo := finasset.NewCurrencyRepository(gormDB)
+	ingestionRepo := ingestion.NewIngestionRepository(gormDB)
+	accountRepo := account.NewAccountRepository(gormDB)
+	ledgerRepo := ledger.NewLedgerRepository(gormDB)
+	categoryRepo := category.NewCategoryRepository(gormDB)
+	budgetRepo := budget.NewBudgetRepository(gormDB)
+	recurringRepo := recurring.NewRecurringRepository(gormDB)
+	holdingRepo := holding.NewHoldingRepository(gormDB)
+	netWorthRepo := networth.NewNetWorthRepository(gormDB)
+	importerRepo := importer.NewImporterRepository(gormDB)
+
+	// Services
+	finAssetService := finasset.NewFinAssetService(finAssetRepo)
+	fxService := fx.NewFXService(finAssetService, settings.FX.PivotCurrency)
+	accountService := account.NewAccountService(accountRepo, finAssetService)
+	ledgerService := ledger.NewLedgerService(ledgerRepo, accountService, finAssetService)
+	categoryService := category.NewCategoryService(gormDB, categoryRepo, ledgerService)
+	userService := user.NewUserService(gormDB, userRepo, tokens, settings.Auth.RefreshTokenTTL, categoryService)
+	budgetService := budget.NewBudgetService(budgetRepo, userService, categoryService, accountService, ledgerService, finAssetService, fxService)
+	recurringService := recurring.NewRecurringService(recurringRepo, ledgerService, categoryService)
+	holdingService := holding.NewHoldingService(gormDB, holdingRepo, accountService, finAssetService)
+	portfolioService := portfolio.NewPortfolioService(userService, holdingService, finAssetService, fxService)
+	netWorthService := networth.NewNetWorthService(netWorthRepo, userService, accountService, ledgerService, portfolioService, finAssetService, fxService)
+	importerService := importer.NewImporterService(gormDB, importerRepo, accountService, ledgerService, finAssetService)
+
+	return dependencies{
+		db:               gormDB,
+		tokens:           tokens,
+		ingestionRepo:    ingestionRepo,
+		recurringRepo:    recurringRepo,
+		userService:      userService,
+		finAssetService:  finAssetService,
+		fxService:        fxService,
+		accountService:   accountService,
+		ledgerService:    ledgerService,
+		categoryService:  categoryService,
+		budgetService:    budgetService,
+		recurringService: recurringService,
+		holdingService:   holdingService,
+		portfolioService: portfolioService,
+		netWorthService:  netWorthService,
+		importerService:  importerService,
+	}
+}
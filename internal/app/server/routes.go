@@ -2,23 +2,29 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jho3r/finanger-back/internal/app/controller"
-	"github.com/jho3r/finanger-back/internal/app/domains/finasset"
-	"github.com/jho3r/finanger-back/internal/app/domains/user"
+	"github.com/jho3r/finanger-back/internal/app/domains/ingestion"
+	"github.com/jho3r/finanger-back/internal/app/domains/networth"
+	"github.com/jho3r/finanger-back/internal/app/domains/recurring"
 	"github.com/jho3r/finanger-back/internal/app/settings"
-	"github.com/jho3r/finanger-back/internal/infrastructure/database/gorm"
 	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
 	"github.com/sirupsen/logrus"
 )
 
 var loggerServer = logger.Setup("server")
 
-// SetupServer Init the server with the middlewares and the routes
-func SetupServer() *gin.Engine {
+// Worker is a background process started alongside the HTTP server.
+type Worker interface {
+	Run(ctx context.Context)
+}
+
+// SetupServer Init the server with the middlewares and the routes, and the background workers enabled in the settings
+func SetupServer() (*gin.Engine, []Worker) {
 	loggerServer.Info("Initializing server ...")
 
 	basePath := fmt.Sprintf("/api/%s", settings.Commons.ProjectName)
@@ -29,33 +35,177 @@ func SetupServer() *gin.Engine {
 		SkipPaths: []string{basePath + "/health"},
 		Formatter: formatter,
 	}))
+	router.Use(controller.ErrorHandler())
+
+	if settings.Database.AutoMigrate {
+		autoMigrate()
+	}
+
+	deps := setupDependencies()
+
+	// Workers
+
+	var workers []Worker
+
+	if settings.Ingestion.Enabled {
+		scheduler, err := ingestion.NewScheduler(deps.finAssetService, deps.ingestionRepo, settings.Ingestion.Interval, priceProviders()...)
+		if err != nil {
+			loggerServer.WithError(err).Fatal("Error creating the price ingestion")
+		}
+
+		workers = append(workers, scheduler)
+	}
 
-	// Dependencies
+	if settings.Recurring.Enabled {
+		worker, err := recurring.NewWorker(deps.db, deps.recurringRepo, deps.ledgerService, deps.categoryService, settings.Recurring.Interval)
+		if err != nil {
+			loggerServer.WithError(err).Fatal("Error creating the recurring transactions worker")
+		}
 
-	// Infrastructure
-	gormDB := gorm.NewGormDB(settings.Database.ConnURL, settings.Database.MaxIdle, settings.Database.MaxOpen)
+		workers = append(workers, worker)
+	}
 
-	// Repos
-	userRepo := user.NewUserRepository(gormDB)
-	finAssetRepo := finasset.NewCurrencyRepository(gormDB)
+	if settings.NetWorth.Enabled {
+		worker, err := networth.NewWorker(deps.netWorthService, deps.userService, settings.NetWorth.Interval)
+		if err != nil {
+			loggerServer.WithError(err).Fatal("Error creating the net worth worker")
+		}
 
-	// Services
-	userService := user.NewUserService(userRepo)
-	finAssetService := finasset.NewFinAssetService(finAssetRepo)
+		workers = append(workers, worker)
+	}
 
 	// Routes
 
 	base := router.Group(basePath)
 	base.GET("/health", controller.HealthCheck)
 
-	finassets := base.Group("/financial-assets")
-	finassets.POST("/", controller.CreateFinancialAsset(finAssetService))
-	finassets.GET("/", controller.GetFinancialAssets(finAssetService))
-
 	users := base.Group("/users")
-	users.POST("/signup", controller.Signup(userService))
+	users.POST("/signup", controller.Signup(deps.userService))
+	users.POST("/login", controller.Login(deps.userService))
+	users.POST("/token/refresh", controller.RefreshToken(deps.userService))
+	users.POST("/logout", controller.Logout(deps.userService))
+
+	// Private routes
+
+	private := base.Group("")
+	private.Use(controller.Authenticate(deps.tokens))
+
+	finassets := private.Group("/financial-assets")
+	finassets.GET("/", controller.GetFinancialAssets(deps.finAssetService))
+	finassets.GET("/types", controller.GetFinancialAssetTypes)
+	finassets.GET("/:id", controller.GetFinancialAsset(deps.finAssetService))
+	finassets.GET("/:id/prices", controller.GetPrices(deps.finAssetService))
+
+	// The financial assets are shared by all the users, only the admins change them.
+	finassetsAdmin := finassets.Group("", controller.RequireAdmin(settings.Auth.AdminUserIDs))
+	finassetsAdmin.POST("/", controller.CreateFinancialAsset(deps.finAssetService))
+	finassetsAdmin.PATCH("/:id", controller.UpdateFinancialAsset(deps.finAssetService))
+	finassetsAdmin.DELETE("/:id", controller.DeleteFinancialAsset(deps.finAssetService))
+	finassetsAdmin.POST("/:id/restore", controller.RestoreFinancialAsset(deps.finAssetService))
+	finassetsAdmin.POST("/:id/prices", controller.UpsertPrices(deps.finAssetService))
+
+	fxs := private.Group("/fx")
+	fxs.GET("/convert", controller.Convert(deps.fxService))
+
+	accounts := private.Group("/accounts")
+	accounts.POST("/", controller.CreateAccount(deps.accountService))
+	accounts.GET("/", controller.GetAccounts(deps.accountService))
+	accounts.GET("/:id", controller.GetAccount(deps.accountService))
+	accounts.PATCH("/:id", controller.UpdateAccount(deps.accountService))
+	accounts.DELETE("/:id", controller.DeleteAccount(deps.accountService))
+	accounts.GET("/:id/balance", controller.GetAccountBalance(deps.ledgerService))
+	accounts.GET("/:id/entries", controller.GetAccountEntries(deps.ledgerService))
+	accounts.POST("/:id/import", controller.ImportStatement(deps.importerService))
+	accounts.POST("/:id/import/csv", controller.ImportCSV(deps.importerService))
+	accounts.POST("/:id/import/csv/preview", controller.PreviewCSVImport(deps.importerService))
+
+	transactions := private.Group("/transactions")
+	transactions.POST("/", controller.CreateTransaction(deps.ledgerService))
+	transactions.GET("/:id", controller.GetTransaction(deps.ledgerService))
+	transactions.DELETE("/:id", controller.DeleteTransaction(deps.ledgerService))
+	transactions.PUT("/:id/category", controller.SetTransactionCategory(deps.categoryService))
+	transactions.GET("/:id/tags", controller.GetTransactionTags(deps.categoryService))
+	transactions.PUT("/:id/tags", controller.SetTransactionTags(deps.categoryService))
+
+	categories := private.Group("/categories")
+	categories.POST("/", controller.CreateCategory(deps.categoryService))
+	categories.GET("/", controller.GetCategories(deps.categoryService))
+	categories.PATCH("/:id", controller.UpdateCategory(deps.categoryService))
+	categories.POST("/:id/move", controller.MoveCategory(deps.categoryService))
+	categories.POST("/:id/merge", controller.MergeCategory(deps.categoryService))
+	categories.DELETE("/:id", controller.DeleteCategory(deps.categoryService))
+
+	tags := private.Group("/tags")
+	tags.POST("/", controller.CreateTag(deps.categoryService))
+	tags.GET("/", controller.GetTags(deps.categoryService))
+	tags.DELETE("/:id", controller.DeleteTag(deps.categoryService))
+
+	importProfiles := private.Group("/import-profiles")
+	importProfiles.POST("/", controller.CreateImportProfile(deps.importerService))
+	importProfiles.GET("/", controller.GetImportProfiles(deps.importerService))
+	importProfiles.GET("/:id", controller.GetImportProfile(deps.importerService))
+	importProfiles.PUT("/:id", controller.UpdateImportProfile(deps.importerService))
+	importProfiles.DELETE("/:id", controller.DeleteImportProfile(deps.importerService))
+
+	budgets := private.Group("/budgets")
+	budgets.POST("/", controller.CreateBudget(deps.budgetService))
+	budgets.GET("/", controller.GetBudgets(deps.budgetService))
+	budgets.GET("/:id", controller.GetBudget(deps.budgetService))
+	budgets.PATCH("/:id", controller.UpdateBudget(deps.budgetService))
+	budgets.DELETE("/:id", controller.DeleteBudget(deps.budgetService))
+	budgets.GET("/:id/progress", controller.GetBudgetProgress(deps.budgetService))
+
+	recurrings := private.Group("/recurring")
+	recurrings.POST("/", controller.CreateRecurringTemplate(deps.recurringService))
+	recurrings.GET("/", controller.GetRecurringTemplates(deps.recurringService))
+	recurrings.GET("/:id", controller.GetRecurringTemplate(deps.recurringService))
+	recurrings.DELETE("/:id", controller.DeleteRecurringTemplate(deps.recurringService))
+	recurrings.GET("/:id/preview", controller.PreviewRecurringTemplate(deps.recurringService))
+	recurrings.POST("/:id/skip", controller.SkipRecurringOccurrence(deps.recurringService))
+	recurrings.POST("/:id/split", controller.SplitRecurringTemplate(deps.recurringService))
+
+	trades := private.Group("/trades")
+	trades.POST("/", controller.CreateTrade(deps.holdingService))
+	trades.GET("/", controller.GetTrades(deps.holdingService))
+
+	holdings := private.Group("/holdings")
+	holdings.GET("/", controller.GetHoldings(deps.holdingService))
+	holdings.GET("/lots", controller.GetLots(deps.holdingService))
+
+	portfolios := private.Group("/portfolio")
+	portfolios.GET("/valuation", controller.GetPortfolioValuation(deps.portfolioService))
+	portfolios.GET("/pnl", controller.GetPortfolioPnL(deps.portfolioService))
+
+	netWorths := private.Group("/net-worth")
+	netWorths.GET("/", controller.GetNetWorth(deps.netWorthService))
+	netWorths.GET("/history", controller.GetNetWorthHistory(deps.netWorthService))
+
+	return router, workers
+}
+
+// priceProviders returns the price providers enabled in the settings.
+func priceProviders() []ingestion.ProviderConfig {
+	var providers []ingestion.ProviderConfig
+
+	if settings.Ingestion.CSVDir != "" {
+		providers = append(providers, ingestion.ProviderConfig{
+			Provider:   ingestion.NewCSVProvider(settings.Ingestion.CSVDir),
+			RateLimit:  settings.Ingestion.CSVRateLimit,
+			MaxRetries: settings.Ingestion.MaxRetries,
+			Backoff:    settings.Ingestion.RetryBackoff,
+		})
+	}
+
+	if settings.Ingestion.HTTPBaseURL != "" {
+		providers = append(providers, ingestion.ProviderConfig{
+			Provider:   ingestion.NewHTTPProvider(settings.Ingestion.HTTPBaseURL, settings.Ingestion.HTTPTimeout),
+			RateLimit:  settings.Ingestion.HTTPRateLimit,
+			MaxRetries: settings.Ingestion.MaxRetries,
+			Backoff:    settings.Ingestion.RetryBackoff,
+		})
+	}
 
-	return router
+	return providers
 }
 
 // formatter format the log from the gin server.
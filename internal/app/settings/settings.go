@@ -1,6 +1,8 @@
 package settings
 
 import (
+	"time"
+
 	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
 	"github.com/kelseyhightower/envconfig"
 )
@@ -11,6 +13,16 @@ var (
 	Commons commons
 	// Database struct to store all the settings of the database.
 	Database database
+	// Auth struct to store all the settings of the authentication.
+	Auth auth
+	// Ingestion struct to store all the settings of the price ingestion.
+	Ingestion ingestion
+	// FX struct to store all the settings of the currency conversion.
+	FX fx
+	// Recurring struct to store all the settings of the recurring transactions.
+	Recurring recurring
+	// NetWorth struct to store all the settings of the net worth snapshots.
+	NetWorth netWorth
 )
 
 type commons struct {
@@ -20,9 +32,46 @@ type commons struct {
 }
 
 type database struct {
-	ConnURL string `envconfig:"DATABASE_CONN_URL" required:"true"`
-	MaxIdle int    `envconfig:"DATABASE_MAX_IDLE_CONNS" default:"10"`
-	MaxOpen int    `envconfig:"DATABASE_MAX_OPEN_CONNS" default:"10"`
+	ConnURL      string        `envconfig:"DATABASE_CONN_URL" required:"true"`
+	MaxIdle      int           `envconfig:"DATABASE_MAX_IDLE_CONNS" default:"10"`
+	MaxOpen      int           `envconfig:"DATABASE_MAX_OPEN_CONNS" default:"10"`
+	QueryTimeout time.Duration `envconfig:"DATABASE_QUERY_TIMEOUT" default:"5s"`
+	AutoMigrate  bool          `envconfig:"DATABASE_AUTO_MIGRATE" default:"false"`
+}
+
+type auth struct {
+	JWTSecret       string        `envconfig:"AUTH_JWT_SECRET" required:"true"`
+	JWTIssuer       string        `envconfig:"AUTH_JWT_ISSUER" default:"finanger-back"`
+	AccessTokenTTL  time.Duration `envconfig:"AUTH_ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL time.Duration `envconfig:"AUTH_REFRESH_TOKEN_TTL" default:"720h"`
+	// AdminUserIDs are the users allowed to change the financial assets shared by all the users.
+	AdminUserIDs []uint `envconfig:"AUTH_ADMIN_USER_IDS"`
+}
+
+type ingestion struct {
+	Enabled       bool          `envconfig:"INGESTION_ENABLED" default:"false"`
+	Interval      time.Duration `envconfig:"INGESTION_INTERVAL" default:"1h"`
+	CSVDir        string        `envconfig:"INGESTION_CSV_DIR"`
+	CSVRateLimit  time.Duration `envconfig:"INGESTION_CSV_RATE_LIMIT" default:"0s"`
+	HTTPBaseURL   string        `envconfig:"INGESTION_HTTP_BASE_URL"`
+	HTTPTimeout   time.Duration `envconfig:"INGESTION_HTTP_TIMEOUT" default:"10s"`
+	HTTPRateLimit time.Duration `envconfig:"INGESTION_HTTP_RATE_LIMIT" default:"1s"`
+	MaxRetries    int           `envconfig:"INGESTION_MAX_RETRIES" default:"3"`
+	RetryBackoff  time.Duration `envconfig:"INGESTION_RETRY_BACKOFF" default:"1s"`
+}
+
+type fx struct {
+	PivotCurrency string `envconfig:"FX_PIVOT_CURRENCY" default:"USD"`
+}
+
+type recurring struct {
+	Enabled  bool          `envconfig:"RECURRING_ENABLED" default:"true"`
+	Interval time.Duration `envconfig:"RECURRING_INTERVAL" default:"15m"`
+}
+
+type netWorth struct {
+	Enabled  bool          `envconfig:"NET_WORTH_ENABLED" default:"true"`
+	Interval time.Duration `envconfig:"NET_WORTH_INTERVAL" default:"1h"`
 }
 
 // LoadEnvs loads all the envs of the application.
@@ -37,4 +86,29 @@ func LoadEnvs() {
 	if err != nil {
 		settingsLogger.WithError(err).Fatal("Error loading database envs")
 	}
+
+	err = envconfig.Process("", &Auth)
+	if err != nil {
+		settingsLogger.WithError(err).Fatal("Error loading auth envs")
+	}
+
+	err = envconfig.Process("", &Ingestion)
+	if err != nil {
+		settingsLogger.WithError(err).Fatal("Error loading ingestion envs")
+	}
+
+	err = envconfig.Process("", &FX)
+	if err != nil {
+		settingsLogger.WithError(err).Fatal("Error loading fx envs")
+	}
+
+	err = envconfig.Process("", &Recurring)
+	if err != nil {
+		settingsLogger.WithError(err).Fatal("Error loading recurring envs")
+	}
+
+	err = envconfig.Process("", &NetWorth)
+	if err != nil {
+		settingsLogger.WithError(err).Fatal("Error loading net worth envs")
+	}
 }
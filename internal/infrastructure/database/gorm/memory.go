@@ -0,0 +1,847 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	errUnsupportedModel = errors.New("unsupported model error")
+
+	// Constraints are the unique constraints of the migrations that are not declared in the tags of the models.
+	// The in-memory databases check them besides the primary keys and the unique tags.
+	Constraints = []UniqueConstraint{
+		{Name: "financial_assets_symbol_active_key", Table: "financial_assets", Columns: []string{"symbol"}, OnlyActive: true},
+		{Name: "users_email_active_key", Table: "users", Columns: []string{"email"}, OnlyActive: true},
+		{Name: "asset_prices_asset_quote_timestamp_key", Table: "asset_prices", Columns: []string{"asset_id", "quote_currency", "timestamp"}},
+		{Name: "price_fetches_asset_provider_key", Table: "price_fetches", Columns: []string{"asset_id", "provider"}},
+		{Name: "tags_user_id_name_key", Table: "tags", Columns: []string{"user_id", "name"}, OnlyActive: true},
+		{Name: "recurring_occurrences_template_id_date_key", Table: "recurring_occurrences", Columns: []string{"template_id", "date"}, OnlyActive: true},
+		{Name: "net_worth_snapshots_user_id_date_key", Table: "net_worth_snapshots", Columns: []string{"user_id", "date"}},
+		{Name: "imported_transactions_account_id_external_id_key", Table: "imported_transactions", Columns: []string{"account_id", "external_id"}, OnlyActive: true},
+	}
+)
+
+type (
+	// UniqueConstraint is the struct for a unique constraint checked by the in-memory databases.
+	// The constraints that only apply to the active records are the unique indexes WHERE deleted_at IS NULL.
+	UniqueConstraint struct {
+		Name       string
+		Table      string
+		Columns    []string
+		OnlyActive bool
+	}
+
+	// MemoryImpl is the struct that contains an in-memory database, for the tests that run without postgres.
+	// The tables are created with the first record of each model. The queries support the conditions used by
+	// the repositories: comparisons, LIKE, IN, IS NULL, AND, OR, NOT and the keyset row comparisons.
+	// The models with DeletedAt are soft deleted, and the primary keys, the unique tags and the unique constraints
+	// return the same errors as postgres. A transaction restores the tables when it is rolled back, it is not
+	// isolated from the operations that run concurrently outside of it.
+	MemoryImpl struct {
+		store    *memoryStore
+		unscoped bool
+		order    string
+		limit    int
+	}
+
+	memoryStore struct {
+		mu          sync.Mutex
+		schemas     sync.Map
+		tables      map[string]*memoryTable
+		constraints []UniqueConstraint
+	}
+
+	// memoryTable keeps pointers to copies of the records, which are never handed out.
+	memoryTable struct {
+		lastID uint64
+		rows   []reflect.Value
+	}
+)
+
+// NewMemoryGorm creates a new empty in-memory database with the unique constraints of the migrations.
+func NewMemoryGorm() Gorm {
+	return &MemoryImpl{store: &memoryStore{tables: map[string]*memoryTable{}, constraints: Constraints}}
+}
+
+// WhereFirst returns the first record that matches the conditions, sorted by the order and the primary key.
+func (m *MemoryImpl) WhereFirst(ctx context.Context, model interface{}, query interface{}, args ...interface{}) error {
+	desc := "Error getting the first element"
+
+	target, ok := structTarget(model)
+	if !ok {
+		return wrapError(ctx, desc, fmt.Errorf("%w: %T is not a pointer to a struct", errUnsupportedModel, model))
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	rows, sch, err := m.find(ctx, model, query, args, true)
+	if err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	if len(rows) == 0 {
+		return wrapError(ctx, desc, gorm.ErrRecordNotFound)
+	}
+
+	target.Set(copyRow(sch, rows[0]).Elem())
+
+	return nil
+}
+
+// Create inserts the record, or the records of a slice, assigning their ids and timestamps.
+// The records of a slice are inserted all or none.
+func (m *MemoryImpl) Create(ctx context.Context, model interface{}) error {
+	desc := "Error creating the element"
+
+	if err := ctx.Err(); err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	elements, err := modelElements(model)
+	if err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	sch, err := m.store.schema(model)
+	if err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	if err := m.insert(ctx, sch, elements); err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	return nil
+}
+
+// WhereFind returns the records that match the conditions in the slice of the model.
+func (m *MemoryImpl) WhereFind(ctx context.Context, model interface{}, query interface{}, args ...interface{}) error {
+	desc := "Error getting the elements"
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	rows, sch, err := m.find(ctx, model, query, args, false)
+	if err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	if target, ok := structTarget(model); ok {
+		if len(rows) > 0 {
+			target.Set(copyRow(sch, rows[0]).Elem())
+		}
+
+		return nil
+	}
+
+	slice := reflect.ValueOf(model).Elem()
+	result := reflect.MakeSlice(slice.Type(), 0, len(rows))
+
+	for _, row := range rows {
+		copied := copyRow(sch, row)
+		if slice.Type().Elem().Kind() != reflect.Ptr {
+			copied = copied.Elem()
+		}
+
+		result = reflect.Append(result, copied)
+	}
+
+	slice.Set(result)
+
+	return nil
+}
+
+// WhereUpdates sets the values in the records that match the conditions and returns the number of rows affected.
+// The updated records must keep satisfying the unique constraints.
+func (m *MemoryImpl) WhereUpdates(ctx context.Context, model interface{}, values map[string]interface{}, query interface{}, args ...interface{}) (int64, error) {
+	desc := "Error updating the elements"
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	rows, sch, err := m.find(ctx, model, query, args, false)
+	if err != nil {
+		return 0, wrapError(ctx, desc, err)
+	}
+
+	now := time.Now()
+	updated := make([]reflect.Value, len(rows))
+
+	for i, row := range rows {
+		updated[i] = copyRow(sch, row)
+
+		for column, value := range values {
+			field := sch.LookUpField(column)
+			if field == nil {
+				return 0, wrapError(ctx, desc, fmt.Errorf("column %q of %s does not exist", column, sch.Table))
+			}
+
+			if err := field.Set(ctx, updated[i].Elem(), value); err != nil {
+				return 0, wrapError(ctx, desc, err)
+			}
+		}
+
+		for _, field := range sch.Fields {
+			if _, ok := values[field.DBName]; field.AutoUpdateTime != 0 && !ok {
+				if err := field.Set(ctx, updated[i].Elem(), now); err != nil {
+					return 0, wrapError(ctx, desc, err)
+				}
+			}
+		}
+	}
+
+	table := m.store.table(sch.Table)
+	if err := m.store.checkUnique(ctx, sch, table, updated, rows); err != nil {
+		return 0, wrapError(ctx, desc, err)
+	}
+
+	for i, row := range rows {
+		row.Elem().Set(cloneValue(updated[i].Elem()))
+	}
+
+	return int64(len(rows)), nil
+}
+
+// WhereDelete deletes the records that match the conditions and returns the number of rows affected.
+// The models with DeletedAt are soft deleted unless the database is unscoped.
+func (m *MemoryImpl) WhereDelete(ctx context.Context, model interface{}, query interface{}, args ...interface{}) (int64, error) {
+	desc := "Error deleting the elements"
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	rows, sch, err := m.find(ctx, model, query, args, false)
+	if err != nil {
+		return 0, wrapError(ctx, desc, err)
+	}
+
+	if field := deletedAtField(sch); field != nil && !m.unscoped {
+		now := time.Now()
+		for _, row := range rows {
+			if err := field.Set(ctx, row.Elem(), now); err != nil {
+				return 0, wrapError(ctx, desc, err)
+			}
+		}
+
+		return int64(len(rows)), nil
+	}
+
+	deleted := make(map[reflect.Value]bool, len(rows))
+	for _, row := range rows {
+		deleted[row] = true
+	}
+
+	table := m.store.table(sch.Table)
+	kept := table.rows[:0]
+
+	for _, row := range table.rows {
+		if !deleted[row] {
+			kept = append(kept, row)
+		}
+	}
+
+	table.rows = kept
+
+	return int64(len(rows)), nil
+}
+
+// Upsert inserts the records, or overwrites the update columns of the active records with the same conflict columns.
+func (m *MemoryImpl) Upsert(ctx context.Context, model interface{}, conflictColumns []string, updateColumns []string) error {
+	desc := "Error upserting the elements"
+
+	if err := ctx.Err(); err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	elements, err := modelElements(model)
+	if err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	sch, err := m.store.schema(model)
+	if err != nil {
+		return wrapError(ctx, desc, err)
+	}
+
+	table := m.store.table(sch.Table)
+
+	for _, element := range elements {
+		existing, err := conflictingRow(ctx, sch, table, element, conflictColumns)
+		if err != nil {
+			return wrapError(ctx, desc, err)
+		}
+
+		if !existing.IsValid() {
+			if err := m.insert(ctx, sch, []reflect.Value{element}); err != nil {
+				return wrapError(ctx, desc, err)
+			}
+
+			continue
+		}
+
+		updated := copyRow(sch, existing)
+		for _, column := range updateColumns {
+			field := sch.LookUpField(column)
+			if field == nil {
+				return wrapError(ctx, desc, fmt.Errorf("column %q of %s does not exist", column, sch.Table))
+			}
+
+			if err := field.Set(ctx, updated.Elem(), field.ReflectValueOf(ctx, element).Interface()); err != nil {
+				return wrapError(ctx, desc, err)
+			}
+		}
+
+		existing.Elem().Set(cloneValue(updated.Elem()))
+		element.Set(copyRow(sch, existing).Elem())
+	}
+
+	return nil
+}
+
+// WhereCount returns the number of records that match the conditions.
+func (m *MemoryImpl) WhereCount(ctx context.Context, model interface{}, query interface{}, args ...interface{}) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	rows, _, err := m.find(ctx, model, query, args, false)
+	if err != nil {
+		return 0, wrapError(ctx, "Error counting the elements", err)
+	}
+
+	return int64(len(rows)), nil
+}
+
+// Unscoped returns a Gorm whose operations include the soft deleted records.
+func (m *MemoryImpl) Unscoped() Gorm {
+	return &MemoryImpl{store: m.store, unscoped: true, order: m.order, limit: m.limit}
+}
+
+// Order returns a Gorm whose find operations are sorted by the given ORDER BY clause.
+func (m *MemoryImpl) Order(order string) Gorm {
+	return &MemoryImpl{store: m.store, unscoped: m.unscoped, order: order, limit: m.limit}
+}
+
+// Limit returns a Gorm whose find operations return at most limit elements.
+func (m *MemoryImpl) Limit(limit int) Gorm {
+	return &MemoryImpl{store: m.store, unscoped: m.unscoped, order: m.order, limit: limit}
+}
+
+// Transaction runs the function with the same database, restoring the tables as they were before it
+// if the function returns an error or panics. The panic is propagated after the rollback.
+// Nested transactions restore only their own work, like the savepoints.
+func (m *MemoryImpl) Transaction(ctx context.Context, fn func(tx Gorm) error) error {
+	if err := ctx.Err(); err != nil {
+		return wrapError(ctx, "Error beginning the transaction", err)
+	}
+
+	snapshot := m.store.snapshot()
+
+	defer func() {
+		if r := recover(); r != nil {
+			m.store.restore(snapshot)
+			panic(r)
+		}
+	}()
+
+	if err := fn(&MemoryImpl{store: m.store}); err != nil {
+		m.store.restore(snapshot)
+
+		return err
+	}
+
+	return nil
+}
+
+// find returns the stored records of the table of the model that match the conditions, sorted and limited.
+// The first flag sorts them by the primary key after the order, like gorm does for First.
+func (m *MemoryImpl) find(ctx context.Context, model interface{}, query interface{}, args []interface{}, first bool) ([]reflect.Value, *schema.Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	sch, err := m.store.schema(model)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text, ok := query.(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: the query %T is not a string", errUnsupportedModel, query)
+	}
+
+	cond, err := parseCondition(text, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orders, err := parseOrder(m.order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if first {
+		for _, name := range sch.PrimaryFieldDBNames {
+			orders = append(orders, orderColumn{column: name})
+		}
+	}
+
+	var matched []reflect.Value
+
+	for _, row := range m.store.table(sch.Table).rows {
+		if !m.unscoped && isDeleted(ctx, sch, row) {
+			continue
+		}
+
+		ok, err := cond.match(columnReader(ctx, sch, row))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if ok {
+			matched = append(matched, row)
+		}
+	}
+
+	var sortErr error
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		less, err := orderLess(orders, columnReader(ctx, sch, matched[i]), columnReader(ctx, sch, matched[j]))
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+
+		return less
+	})
+
+	if sortErr != nil {
+		return nil, nil, sortErr
+	}
+
+	if m.limit > 0 && len(matched) > m.limit {
+		matched = matched[:m.limit]
+	}
+
+	return matched, sch, nil
+}
+
+// insert stores copies of the elements with their ids and timestamps, and writes them back to the elements.
+// Nothing is stored if any of them violates a unique constraint.
+func (m *MemoryImpl) insert(ctx context.Context, sch *schema.Schema, elements []reflect.Value) error {
+	table := m.store.table(sch.Table)
+	lastID := table.lastID
+	now := time.Now()
+	rows := make([]reflect.Value, len(elements))
+
+	for i, element := range elements {
+		row := reflect.New(sch.ModelType)
+		row.Elem().Set(cloneValue(element))
+
+		if err := setDefaults(ctx, sch, row.Elem(), now, &lastID); err != nil {
+			return err
+		}
+
+		rows[i] = row
+	}
+
+	if err := m.store.checkUnique(ctx, sch, table, rows, nil); err != nil {
+		return err
+	}
+
+	table.lastID = lastID
+	table.rows = append(table.rows, rows...)
+
+	for i, element := range elements {
+		element.Set(copyRow(sch, rows[i]).Elem())
+	}
+
+	return nil
+}
+
+// setDefaults assigns the next id to the records without one and the timestamps and the defaults of the zero fields.
+func setDefaults(ctx context.Context, sch *schema.Schema, row reflect.Value, now time.Time, lastID *uint64) error {
+	if isAutoID(sch) {
+		field := sch.PrioritizedPrimaryField
+		value := field.ReflectValueOf(ctx, row)
+
+		if value.IsZero() {
+			*lastID++
+			if err := field.Set(ctx, row, *lastID); err != nil {
+				return err
+			}
+		} else if id, ok := unsigned(value); ok && id > *lastID {
+			*lastID = id
+		}
+	}
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" || !field.ReflectValueOf(ctx, row).IsZero() {
+			continue
+		}
+
+		var value interface{}
+
+		switch {
+		case field.AutoCreateTime != 0 || field.AutoUpdateTime != 0:
+			value = now
+		case field.DefaultValueInterface != nil:
+			value = field.DefaultValueInterface
+		case field.HasDefaultValue && field.DefaultValue != "" && reflect.PtrTo(field.FieldType).Implements(scannerType):
+			value = []byte(strings.Trim(field.DefaultValue, "'"))
+		default:
+			continue
+		}
+
+		if err := field.Set(ctx, row, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkUnique returns the unique violation of the first constraint of the table broken by the rows.
+// The rows replace the stored ones being updated, if any, and are checked against each other.
+func (s *memoryStore) checkUnique(ctx context.Context, sch *schema.Schema, table *memoryTable, rows, replaced []reflect.Value) error {
+	skipped := make(map[reflect.Value]bool, len(replaced))
+	for _, row := range replaced {
+		skipped[row] = true
+	}
+
+	var others []reflect.Value
+
+	for _, row := range table.rows {
+		if !skipped[row] {
+			others = append(others, row)
+		}
+	}
+
+	for _, constraint := range s.tableConstraints(sch) {
+		seen := map[string]bool{}
+
+		for _, row := range append(others, rows...) {
+			if constraint.OnlyActive && isDeleted(ctx, sch, row) {
+				continue
+			}
+
+			key, ok := uniqueKey(ctx, sch, row, constraint.Columns)
+			if !ok {
+				continue
+			}
+
+			if seen[key] {
+				return &pgconn.PgError{
+					Severity:       "ERROR",
+					Code:           pgUniqueViolation,
+					ConstraintName: constraint.Name,
+					Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint.Name),
+				}
+			}
+
+			seen[key] = true
+		}
+	}
+
+	return nil
+}
+
+// tableConstraints returns the primary key, the unique tags and the registered unique constraints of the table.
+func (s *memoryStore) tableConstraints(sch *schema.Schema) []UniqueConstraint {
+	constraints := []UniqueConstraint{{Name: sch.Table + "_pkey", Table: sch.Table, Columns: sch.PrimaryFieldDBNames}}
+
+	for _, field := range sch.Fields {
+		if field.Unique && field.DBName != "" {
+			name := fmt.Sprintf("%s_%s_key", sch.Table, field.DBName)
+			constraints = append(constraints, UniqueConstraint{Name: name, Table: sch.Table, Columns: []string{field.DBName}})
+		}
+	}
+
+	for _, constraint := range s.constraints {
+		if constraint.Table == sch.Table {
+			constraints = append(constraints, constraint)
+		}
+	}
+
+	return constraints
+}
+
+// conflictingRow returns the stored record with the same conflict columns as the element, if any.
+// The soft deleted records conflict too, since the upserts target the constraints over the whole table.
+func conflictingRow(ctx context.Context, sch *schema.Schema, table *memoryTable, element reflect.Value, columns []string) (reflect.Value, error) {
+	for _, column := range columns {
+		if sch.LookUpField(column) == nil {
+			return reflect.Value{}, fmt.Errorf("column %q of %s does not exist", column, sch.Table)
+		}
+	}
+
+	key, ok := uniqueKey(ctx, sch, element, columns)
+	if !ok {
+		return reflect.Value{}, nil
+	}
+
+	for _, row := range table.rows {
+		if rowKey, ok := uniqueKey(ctx, sch, row, columns); ok && rowKey == key {
+			return row, nil
+		}
+	}
+
+	return reflect.Value{}, nil
+}
+
+// uniqueKey returns the values of the columns of the row as a comparable key.
+// It returns false when any of them is null, since nulls are distinct in the unique constraints.
+func uniqueKey(ctx context.Context, sch *schema.Schema, row reflect.Value, columns []string) (string, bool) {
+	read := columnReader(ctx, sch, row)
+	parts := make([]string, len(columns))
+
+	for i, column := range columns {
+		value, err := read(column)
+		if err != nil || value == nil {
+			return "", false
+		}
+
+		parts[i] = fmt.Sprintf("%T:%v", value, keyOf(value))
+	}
+
+	return strings.Join(parts, "\x1f"), true
+}
+
+// schema returns the parsed schema of the model, cached by type.
+func (s *memoryStore) schema(model interface{}) (*schema.Schema, error) {
+	sch, err := schema.Parse(model, &s.schemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedModel, err.Error())
+	}
+
+	return sch, nil
+}
+
+// table returns the table with the name, creating it when it does not exist.
+func (s *memoryStore) table(name string) *memoryTable {
+	table, ok := s.tables[name]
+	if !ok {
+		table = &memoryTable{}
+		s.tables[name] = table
+	}
+
+	return table
+}
+
+// snapshot returns a copy of the tables to restore them on a rollback.
+func (s *memoryStore) snapshot() map[string]memoryTable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make(map[string]memoryTable, len(s.tables))
+
+	for name, table := range s.tables {
+		rows := make([]reflect.Value, len(table.rows))
+		for i, row := range table.rows {
+			rows[i] = reflect.New(row.Elem().Type())
+			rows[i].Elem().Set(cloneValue(row.Elem()))
+		}
+
+		snapshot[name] = memoryTable{lastID: table.lastID, rows: rows}
+	}
+
+	return snapshot
+}
+
+// restore replaces the tables with the snapshot. The ids are not reused, like the sequences of postgres.
+func (s *memoryStore) restore(snapshot map[string]memoryTable) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, table := range s.tables {
+		restored, ok := snapshot[name]
+		if !ok {
+			table.rows = nil
+			continue
+		}
+
+		table.rows = restored.rows
+	}
+}
+
+// columnReader returns the function that reads the normalized value of a column of the row.
+func columnReader(ctx context.Context, sch *schema.Schema, row reflect.Value) func(column string) (interface{}, error) {
+	return func(column string) (interface{}, error) {
+		if i := strings.LastIndex(column, "."); i >= 0 {
+			column = column[i+1:]
+		}
+
+		field := sch.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("column %q of %s does not exist", column, sch.Table)
+		}
+
+		return normalize(field.ReflectValueOf(ctx, reflect.Indirect(row)).Interface()), nil
+	}
+}
+
+// isDeleted reports if the row is soft deleted.
+func isDeleted(ctx context.Context, sch *schema.Schema, row reflect.Value) bool {
+	field := deletedAtField(sch)
+	if field == nil {
+		return false
+	}
+
+	deletedAt, _ := field.ReflectValueOf(ctx, row.Elem()).Interface().(gorm.DeletedAt)
+
+	return deletedAt.Valid
+}
+
+// deletedAtField returns the DeletedAt field of the models that are soft deleted.
+func deletedAtField(sch *schema.Schema) *schema.Field {
+	field := sch.LookUpField("deleted_at")
+	if field == nil || field.FieldType != reflect.TypeOf(gorm.DeletedAt{}) {
+		return nil
+	}
+
+	return field
+}
+
+// isAutoID reports if the primary key is a single integer id, which gorm auto increments.
+func isAutoID(sch *schema.Schema) bool {
+	field := sch.PrioritizedPrimaryField
+	if field == nil || len(sch.PrimaryFields) != 1 {
+		return false
+	}
+
+	switch field.FieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// unsigned returns the value of an integer id.
+func unsigned(value reflect.Value) (uint64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(value.Int()), value.Int() > 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint(), true
+	}
+
+	return 0, false
+}
+
+// copyRow returns a pointer to a copy of the stored record.
+func copyRow(sch *schema.Schema, row reflect.Value) reflect.Value {
+	copied := reflect.New(sch.ModelType)
+	copied.Elem().Set(cloneValue(row.Elem()))
+
+	return copied
+}
+
+// cloneValue returns a copy of the value that does not share its pointers, slices and maps, so the stored records
+// and the models of the callers do not change each other, like the rows of a database. The unexported fields,
+// like the ones of time.Time and decimal.Decimal, are copied as they are since their types do not mutate them.
+func cloneValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+
+		cloned := reflect.New(value.Type().Elem())
+		cloned.Elem().Set(cloneValue(value.Elem()))
+
+		return cloned
+	case reflect.Struct:
+		cloned := reflect.New(value.Type()).Elem()
+		cloned.Set(value)
+
+		for i := 0; i < cloned.NumField(); i++ {
+			if cloned.Field(i).CanSet() {
+				cloned.Field(i).Set(cloneValue(value.Field(i)))
+			}
+		}
+
+		return cloned
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+
+		cloned := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			cloned.Index(i).Set(cloneValue(value.Index(i)))
+		}
+
+		return cloned
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		cloned := reflect.MakeMapWithSize(value.Type(), value.Len())
+		for iter := value.MapRange(); iter.Next(); {
+			cloned.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+
+		return cloned
+	default:
+		return value
+	}
+}
+
+// structTarget returns the struct the model points to.
+func structTarget(model interface{}) (reflect.Value, bool) {
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	return value.Elem(), true
+}
+
+// modelElements returns the addressable structs of a pointer to a struct or to a slice of structs or pointers.
+func modelElements(model interface{}) ([]reflect.Value, error) {
+	if target, ok := structTarget(model); ok {
+		return []reflect.Value{target}, nil
+	}
+
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%w: %T is not a pointer to a struct or a slice", errUnsupportedModel, model)
+	}
+
+	slice := value.Elem()
+	elements := make([]reflect.Value, slice.Len())
+
+	for i := range elements {
+		element := slice.Index(i)
+		if element.Kind() == reflect.Ptr {
+			element = element.Elem()
+		}
+
+		elements[i] = element
+	}
+
+	return elements, nil
+}
+
+// scannerType is the type of the sql.Scanner interface.
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
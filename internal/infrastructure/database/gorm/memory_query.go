@@ -0,0 +1,796 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+var errInvalidQuery = errors.New("invalid query error")
+
+type (
+	// condition is a parsed WHERE clause of the in-memory databases.
+	condition interface {
+		match(read columnFunc) (bool, error)
+	}
+
+	// columnFunc reads the normalized value of a column of a record.
+	columnFunc func(column string) (interface{}, error)
+
+	allCondition  []condition
+	anyCondition  []condition
+	notCondition  struct{ condition condition }
+	trueCondition struct{}
+
+	// comparison compares a column, or a row of columns, with the values.
+	comparison struct {
+		columns  []string
+		operator string
+		values   []interface{}
+	}
+
+	nullCondition struct {
+		column string
+		not    bool
+	}
+
+	inCondition struct {
+		column string
+		values []interface{}
+		not    bool
+	}
+
+	likeCondition struct {
+		column  string
+		pattern *regexp.Regexp
+		not     bool
+	}
+
+	orderColumn struct {
+		column string
+		desc   bool
+	}
+
+	// queryParser parses the conditions of a query with its args, which replace the ? placeholders in order.
+	queryParser struct {
+		tokens []string
+		pos    int
+		args   []interface{}
+	}
+)
+
+// parseCondition parses the conditions of the query, an empty query matches every record.
+func parseCondition(query string, args []interface{}) (condition, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return trueCondition{}, nil
+	}
+
+	parser := &queryParser{tokens: tokens, args: args}
+
+	cond, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.pos < len(parser.tokens) {
+		return nil, parser.errorf("unexpected %q", parser.peek())
+	}
+
+	return cond, nil
+}
+
+// tokenize splits the query in identifiers, keywords, operators, placeholders, parentheses, commas and literals.
+func tokenize(query string) ([]string, error) {
+	var tokens []string
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',' || r == '?':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && (runes[end] != '\'' || end+1 < len(runes) && runes[end+1] == '\'') {
+				if runes[end] == '\'' {
+					end++
+				}
+				end++
+			}
+
+			if end >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string in %q", errInvalidQuery, query)
+			}
+
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case strings.ContainsRune("=<>!", r):
+			end := i + 1
+			for end < len(runes) && strings.ContainsRune("=<>", runes[end]) {
+				end++
+			}
+
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || strings.ContainsRune("_.", runes[end])) {
+				end++
+			}
+
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in %q", errInvalidQuery, r, query)
+		}
+	}
+
+	return tokens, nil
+}
+
+func (p *queryParser) parseOr() (condition, error) {
+	cond, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := anyCondition{cond}
+	for p.accept("OR") {
+		cond, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, cond)
+	}
+
+	if len(conditions) == 1 {
+		return cond, nil
+	}
+
+	return conditions, nil
+}
+
+func (p *queryParser) parseAnd() (condition, error) {
+	cond, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := allCondition{cond}
+	for p.accept("AND") {
+		cond, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, cond)
+	}
+
+	if len(conditions) == 1 {
+		return cond, nil
+	}
+
+	return conditions, nil
+}
+
+func (p *queryParser) parseNot() (condition, error) {
+	if p.accept("NOT") {
+		cond, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return notCondition{condition: cond}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a condition between parentheses, a row comparison or the condition of a column.
+func (p *queryParser) parsePrimary() (condition, error) {
+	if p.peek() == "(" {
+		if p.peekAt(2) == "," {
+			return p.parseRowComparison()
+		}
+
+		p.pos++
+
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(")") {
+			return nil, p.errorf("missing )")
+		}
+
+		return cond, nil
+	}
+
+	column, err := p.parseColumn()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("IS") {
+		not := p.accept("NOT")
+		if !p.accept("NULL") {
+			return nil, p.errorf("expected NULL after IS")
+		}
+
+		return nullCondition{column: column, not: not}, nil
+	}
+
+	not := p.accept("NOT")
+
+	switch {
+	case p.accept("IN"):
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+
+		return inCondition{column: column, values: values, not: not}, nil
+	case p.accept("LIKE"), p.acceptILike():
+		caseInsensitive := strings.EqualFold(p.tokens[p.pos-1], "ILIKE")
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, p.errorf("the pattern of %s must be a string", column)
+		}
+
+		return likeCondition{column: column, pattern: likePattern(pattern, caseInsensitive), not: not}, nil
+	case not:
+		return nil, p.errorf("expected IN or LIKE after NOT")
+	}
+
+	operator, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return comparison{columns: []string{column}, operator: operator, values: []interface{}{value}}, nil
+}
+
+// parseRowComparison parses the comparisons of rows of columns like (created_at, id) > (?, ?).
+func (p *queryParser) parseRowComparison() (condition, error) {
+	p.pos++
+
+	var columns []string
+
+	for {
+		column, err := p.parseColumn()
+		if err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, column)
+
+		if p.accept(")") {
+			break
+		}
+
+		if !p.accept(",") {
+			return nil, p.errorf("expected , or ) in the row")
+		}
+	}
+
+	operator, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(columns) {
+		return nil, p.errorf("the row has %d columns and %d values", len(columns), len(values))
+	}
+
+	return comparison{columns: columns, operator: operator, values: values}, nil
+}
+
+// parseList parses a list of values between parentheses, or a placeholder of a slice.
+func (p *queryParser) parseList() ([]interface{}, error) {
+	if p.accept("?") {
+		arg, err := p.nextArg()
+		if err != nil {
+			return nil, err
+		}
+
+		value := reflect.ValueOf(arg)
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			return []interface{}{normalize(arg)}, nil
+		}
+
+		values := make([]interface{}, value.Len())
+		for i := range values {
+			values[i] = normalize(value.Index(i).Interface())
+		}
+
+		return values, nil
+	}
+
+	if !p.accept("(") {
+		return nil, p.errorf("expected a list")
+	}
+
+	var values []interface{}
+
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+
+		if p.accept(")") {
+			return values, nil
+		}
+
+		if !p.accept(",") {
+			return nil, p.errorf("expected , or ) in the list")
+		}
+	}
+}
+
+// parseValue parses a placeholder, a string, a number, a boolean or NULL.
+func (p *queryParser) parseValue() (interface{}, error) {
+	token := p.peek()
+	p.pos++
+
+	switch {
+	case token == "?":
+		arg, err := p.nextArg()
+		if err != nil {
+			return nil, err
+		}
+
+		return normalize(arg), nil
+	case strings.HasPrefix(token, "'"):
+		return strings.ReplaceAll(token[1:len(token)-1], "''", "'"), nil
+	case strings.EqualFold(token, "NULL"):
+		return nil, nil
+	case strings.EqualFold(token, "TRUE"), strings.EqualFold(token, "FALSE"):
+		return strings.EqualFold(token, "TRUE"), nil
+	}
+
+	number, err := decimal.NewFromString(token)
+	if err != nil {
+		return nil, p.errorf("unexpected value %q", token)
+	}
+
+	return number, nil
+}
+
+func (p *queryParser) parseColumn() (string, error) {
+	token := p.peek()
+	if token == "" || !(unicode.IsLetter([]rune(token)[0]) || token[0] == '_') || isKeyword(token) {
+		return "", p.errorf("expected a column instead of %q", token)
+	}
+
+	p.pos++
+
+	return token, nil
+}
+
+func (p *queryParser) parseOperator() (string, error) {
+	token := p.peek()
+
+	switch token {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+		p.pos++
+		return token, nil
+	}
+
+	return "", p.errorf("unexpected operator %q", token)
+}
+
+func (p *queryParser) nextArg() (interface{}, error) {
+	if len(p.args) == 0 {
+		return nil, p.errorf("missing the arg of a placeholder")
+	}
+
+	arg := p.args[0]
+	p.args = p.args[1:]
+
+	return arg, nil
+}
+
+// accept consumes the next token if it is the keyword or the symbol.
+func (p *queryParser) accept(token string) bool {
+	if strings.EqualFold(p.peek(), token) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *queryParser) acceptILike() bool {
+	return p.accept("ILIKE")
+}
+
+func (p *queryParser) peek() string {
+	return p.peekAt(0)
+}
+
+func (p *queryParser) peekAt(offset int) string {
+	if p.pos+offset >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos+offset]
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s in %q", errInvalidQuery, fmt.Sprintf(format, args...), strings.Join(p.tokens, " "))
+}
+
+func isKeyword(token string) bool {
+	switch strings.ToUpper(token) {
+	case "AND", "OR", "NOT", "IS", "NULL", "IN", "LIKE", "ILIKE", "TRUE", "FALSE":
+		return true
+	}
+
+	return false
+}
+
+// likePattern translates a LIKE pattern to a regular expression, % matches any text and _ any character.
+func likePattern(pattern string, caseInsensitive bool) *regexp.Regexp {
+	var expr strings.Builder
+
+	expr.WriteString("^(?s)")
+	if caseInsensitive {
+		expr.WriteString("(?i)")
+	}
+
+	escaped := false
+
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			expr.WriteString(".*")
+		case r == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String())
+}
+
+func (c allCondition) match(read columnFunc) (bool, error) {
+	for _, cond := range c {
+		ok, err := cond.match(read)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (c anyCondition) match(read columnFunc) (bool, error) {
+	for _, cond := range c {
+		ok, err := cond.match(read)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+func (c notCondition) match(read columnFunc) (bool, error) {
+	ok, err := c.condition.match(read)
+
+	return !ok, err
+}
+
+func (trueCondition) match(columnFunc) (bool, error) {
+	return true, nil
+}
+
+// match compares the columns with the values, the rows are compared column by column like in postgres.
+// Comparisons with nulls do not match.
+func (c comparison) match(read columnFunc) (bool, error) {
+	result := 0
+
+	for i, column := range c.columns {
+		value, err := read(column)
+		if err != nil {
+			return false, err
+		}
+
+		cmp, ok, err := compare(value, c.values[i])
+		if err != nil || !ok {
+			return false, err
+		}
+
+		if cmp != 0 {
+			result = cmp
+			break
+		}
+	}
+
+	switch c.operator {
+	case "=":
+		return result == 0, nil
+	case "<>", "!=":
+		return result != 0, nil
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	default:
+		return result >= 0, nil
+	}
+}
+
+func (c nullCondition) match(read columnFunc) (bool, error) {
+	value, err := read(c.column)
+	if err != nil {
+		return false, err
+	}
+
+	return (value == nil) != c.not, nil
+}
+
+func (c inCondition) match(read columnFunc) (bool, error) {
+	value, err := read(c.column)
+	if err != nil || value == nil {
+		return false, err
+	}
+
+	for _, candidate := range c.values {
+		cmp, ok, err := compare(value, candidate)
+		if err != nil {
+			return false, err
+		}
+
+		if ok && cmp == 0 {
+			return !c.not, nil
+		}
+	}
+
+	return c.not, nil
+}
+
+func (c likeCondition) match(read columnFunc) (bool, error) {
+	value, err := read(c.column)
+	if err != nil || value == nil {
+		return false, err
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return false, fmt.Errorf("%w: the column %s is not a text", errInvalidQuery, c.column)
+	}
+
+	return c.pattern.MatchString(text) != c.not, nil
+}
+
+// parseOrder parses an ORDER BY clause like "date ASC, id DESC".
+func parseOrder(order string) ([]orderColumn, error) {
+	var columns []orderColumn
+
+	for _, part := range strings.Split(order, ",") {
+		fields := strings.Fields(part)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) > 2, len(fields) == 2 && !strings.EqualFold(fields[1], "ASC") && !strings.EqualFold(fields[1], "DESC"):
+			return nil, fmt.Errorf("%w: unexpected order %q", errInvalidQuery, part)
+		}
+
+		columns = append(columns, orderColumn{column: fields[0], desc: len(fields) == 2 && strings.EqualFold(fields[1], "DESC")})
+	}
+
+	return columns, nil
+}
+
+// orderLess reports if the first record goes before the second one.
+// The nulls go last in ascending order and first in descending order, like in postgres.
+func orderLess(columns []orderColumn, a, b columnFunc) (bool, error) {
+	for _, column := range columns {
+		left, err := a(column.column)
+		if err != nil {
+			return false, err
+		}
+
+		right, err := b(column.column)
+		if err != nil {
+			return false, err
+		}
+
+		var cmp int
+
+		switch {
+		case left == nil && right == nil:
+			continue
+		case left == nil:
+			cmp = 1
+		case right == nil:
+			cmp = -1
+		default:
+			if cmp, _, err = compare(left, right); err != nil {
+				return false, err
+			}
+		}
+
+		if cmp == 0 {
+			continue
+		}
+
+		if column.desc {
+			return cmp > 0, nil
+		}
+
+		return cmp < 0, nil
+	}
+
+	return false, nil
+}
+
+// compare compares two normalized values. It returns false when any of them is null.
+func compare(a, b interface{}) (int, bool, error) {
+	if a == nil || b == nil {
+		return 0, false, nil
+	}
+
+	switch left := a.(type) {
+	case decimal.Decimal:
+		if right, ok := b.(decimal.Decimal); ok {
+			return left.Cmp(right), true, nil
+		}
+
+		if right, ok := b.(string); ok {
+			if number, err := decimal.NewFromString(right); err == nil {
+				return left.Cmp(number), true, nil
+			}
+		}
+	case time.Time:
+		if right, ok := b.(time.Time); ok {
+			return left.Compare(right), true, nil
+		}
+
+		if right, ok := b.(string); ok {
+			if date, err := parseTime(right); err == nil {
+				return left.Compare(date), true, nil
+			}
+		}
+	case string:
+		switch right := b.(type) {
+		case string:
+			return strings.Compare(left, right), true, nil
+		case decimal.Decimal, time.Time:
+			cmp, ok, err := compare(b, a)
+			return -cmp, ok, err
+		}
+	case bool:
+		if right, ok := b.(bool); ok {
+			switch {
+			case left == right:
+				return 0, true, nil
+			case right:
+				return -1, true, nil
+			default:
+				return 1, true, nil
+			}
+		}
+	}
+
+	return 0, false, fmt.Errorf("%w: can not compare %T with %T", errInvalidQuery, a, b)
+}
+
+// parseTime parses the text of a timestamp or a date.
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", time.DateOnly} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: invalid timestamp %q", errInvalidQuery, value)
+}
+
+// normalize converts a value of a record or an arg to the types compared by the conditions:
+// nil, decimal.Decimal for the numbers, time.Time, string and bool. The valuers are converted to their values.
+func normalize(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Ptr {
+		if reflected.IsNil() {
+			return nil
+		}
+
+		return normalize(reflected.Elem().Interface())
+	}
+
+	switch typed := value.(type) {
+	case decimal.Decimal, time.Time:
+		return typed
+	case []byte:
+		return string(typed)
+	case driver.Valuer:
+		converted, err := typed.Value()
+		if err != nil {
+			return nil
+		}
+
+		return normalize(converted)
+	}
+
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decimal.NewFromInt(reflected.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return decimal.NewFromBigInt(new(big.Int).SetUint64(reflected.Uint()), 0)
+	case reflect.Float32, reflect.Float64:
+		return decimal.NewFromFloat(reflected.Float())
+	case reflect.String:
+		return reflected.String()
+	case reflect.Bool:
+		return reflected.Bool()
+	}
+
+	return value
+}
+
+// keyOf returns a representation of a normalized value that is equal for the equal values.
+func keyOf(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case decimal.Decimal:
+		return typed.String()
+	case time.Time:
+		return typed.UTC().Format(time.RFC3339Nano)
+	}
+
+	return value
+}
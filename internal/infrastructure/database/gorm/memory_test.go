@@ -0,0 +1,558 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/shopspring/decimal"
+)
+
+type memoryItem struct {
+	Model
+	Name     string          `gorm:"not null"`
+	Kind     string          `gorm:"not null"`
+	Code     string          `gorm:"unique"`
+	Amount   decimal.Decimal `gorm:"type:numeric"`
+	Active   bool
+	ParentID *uint
+}
+
+// seedItems stores the items in a new in-memory database, their ids are their position from one.
+func seedItems(t *testing.T, constraints ...UniqueConstraint) (Gorm, []memoryItem) {
+	t.Helper()
+
+	parent := uint(1)
+	items := []memoryItem{
+		{Name: "Apple", Kind: "fruit", Code: "A", Amount: decimal.NewFromInt(3), Active: true},
+		{Name: "apricot", Kind: "fruit", Code: "B", Amount: decimal.NewFromInt(5), ParentID: &parent},
+		{Name: "Banana", Kind: "fruit", Code: "C", Amount: decimal.NewFromInt(1), Active: true},
+		{Name: "Carrot", Kind: "vegetable", Code: "D", Amount: decimal.NewFromInt(5), ParentID: &parent},
+		{Name: "it's", Kind: "other", Code: "E", Amount: decimal.RequireFromString("2.5")},
+	}
+
+	db := &MemoryImpl{store: &memoryStore{tables: map[string]*memoryTable{}, constraints: constraints}}
+
+	for i := range items {
+		if err := db.Create(context.Background(), &items[i]); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	return db, items
+}
+
+func names(items []memoryItem) []string {
+	result := []string{}
+	for _, item := range items {
+		result = append(result, item.Name)
+	}
+
+	return result
+}
+
+func TestMemoryQueries(t *testing.T) {
+	db, items := seedItems(t)
+
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		want  []string
+	}{
+		{name: "empty query", query: "", want: []string{"Apple", "apricot", "Banana", "Carrot", "it's"}},
+		{name: "equal", query: "kind = ?", args: []interface{}{"fruit"}, want: []string{"Apple", "apricot", "Banana"}},
+		{name: "not equal", query: "kind <> ?", args: []interface{}{"fruit"}, want: []string{"Carrot", "it's"}},
+		{name: "not equal bang", query: "kind != 'fruit'", want: []string{"Carrot", "it's"}},
+		{name: "numbers", query: "amount >= ? AND amount < 5", args: []interface{}{2.5}, want: []string{"Apple", "it's"}},
+		{name: "uint id", query: "id = ?", args: []interface{}{items[2].ID}, want: []string{"Banana"}},
+		{name: "like", query: "name LIKE ?", args: []interface{}{"%an%"}, want: []string{"Banana"}},
+		{name: "like is case sensitive", query: "name LIKE ?", args: []interface{}{"a%"}, want: []string{"apricot"}},
+		{name: "ilike", query: "name ILIKE ?", args: []interface{}{"a%"}, want: []string{"Apple", "apricot"}},
+		{name: "like single character", query: "code LIKE '_'", want: []string{"Apple", "apricot", "Banana", "Carrot", "it's"}},
+		{name: "not like", query: "name NOT LIKE ?", args: []interface{}{"%a%"}, want: []string{"Apple", "it's"}},
+		{name: "escaped quote", query: "name = 'it''s'", want: []string{"it's"}},
+		{name: "in slice", query: "code IN ?", args: []interface{}{[]string{"A", "D"}}, want: []string{"Apple", "Carrot"}},
+		{name: "in list", query: "code IN ('B', 'C')", want: []string{"apricot", "Banana"}},
+		{name: "not in", query: "code NOT IN ?", args: []interface{}{[]string{"A", "B", "C"}}, want: []string{"Carrot", "it's"}},
+		{name: "is null", query: "parent_id IS NULL", want: []string{"Apple", "Banana", "it's"}},
+		{name: "is not null", query: "parent_id IS NOT NULL", want: []string{"apricot", "Carrot"}},
+		{name: "null comparison does not match", query: "parent_id <> ?", args: []interface{}{2}, want: []string{"apricot", "Carrot"}},
+		{name: "boolean", query: "active = TRUE", want: []string{"Apple", "Banana"}},
+		{name: "boolean arg", query: "active = ?", args: []interface{}{false}, want: []string{"apricot", "Carrot", "it's"}},
+		{name: "or with parentheses", query: "(kind = ? OR kind = ?) AND amount > ?", args: []interface{}{"vegetable", "other", 2}, want: []string{"Carrot", "it's"}},
+		{name: "and binds tighter than or", query: "kind = 'other' OR kind = 'fruit' AND amount > 4", want: []string{"apricot", "it's"}},
+		{name: "not", query: "NOT kind = ?", args: []interface{}{"fruit"}, want: []string{"Carrot", "it's"}},
+		{name: "case insensitive keywords", query: "kind = 'fruit' and name like 'B%'", want: []string{"Banana"}},
+		{name: "row comparison", query: "(amount, id) > (?, ?)", args: []interface{}{5, items[1].ID}, want: []string{"Carrot"}},
+		{name: "row comparison less", query: "(amount, id) < (?, ?)", args: []interface{}{3, 100}, want: []string{"Apple", "Banana", "it's"}},
+		{name: "table prefix", query: "memory_items.kind = ?", args: []interface{}{"vegetable"}, want: []string{"Carrot"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []memoryItem
+			if err := db.Order("id ASC").WhereFind(context.Background(), &got, tt.query, tt.args...); err != nil {
+				t.Fatalf("WhereFind() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(names(got), tt.want) {
+				t.Errorf("WhereFind() = %v, want %v", names(got), tt.want)
+			}
+
+			count, err := db.WhereCount(context.Background(), &memoryItem{}, tt.query, tt.args...)
+			if err != nil {
+				t.Fatalf("WhereCount() error = %v", err)
+			}
+
+			if count != int64(len(tt.want)) {
+				t.Errorf("WhereCount() = %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestMemoryInvalidQueries(t *testing.T) {
+	db, _ := seedItems(t)
+
+	tests := []struct {
+		name  string
+		query interface{}
+		args  []interface{}
+	}{
+		{name: "unknown column", query: "color = ?", args: []interface{}{"red"}},
+		{name: "missing arg", query: "kind = ? AND name = ?", args: []interface{}{"fruit"}},
+		{name: "unterminated string", query: "kind = 'fruit"},
+		{name: "unknown operator", query: "kind ~ 'fruit'"},
+		{name: "missing parenthesis", query: "(kind = 'fruit'"},
+		{name: "trailing tokens", query: "kind = 'fruit' 'vegetable'"},
+		{name: "row size mismatch", query: "(amount, id) > (?)", args: []interface{}{1}},
+		{name: "not a string query", query: map[string]interface{}{"kind": "fruit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []memoryItem
+			if err := db.WhereFind(context.Background(), &got, tt.query, tt.args...); err == nil {
+				t.Errorf("WhereFind() = %v, want an error", names(got))
+			}
+		})
+	}
+}
+
+func TestMemoryOrderAndLimit(t *testing.T) {
+	db, _ := seedItems(t)
+
+	tests := []struct {
+		name  string
+		order string
+		limit int
+		want  []string
+	}{
+		{name: "primary key by default", want: []string{"Apple", "apricot", "Banana", "Carrot", "it's"}},
+		{name: "descending with tie breaker", order: "amount DESC, id ASC", want: []string{"apricot", "Carrot", "Apple", "it's", "Banana"}},
+		{name: "several columns", order: "kind ASC, name DESC", want: []string{"apricot", "Banana", "Apple", "it's", "Carrot"}},
+		{name: "limit", order: "amount ASC", limit: 2, want: []string{"Banana", "it's"}},
+		{name: "nulls last ascending", order: "parent_id ASC, id ASC", want: []string{"apricot", "Carrot", "Apple", "Banana", "it's"}},
+		{name: "nulls first descending", order: "parent_id DESC, id ASC", want: []string{"Apple", "Banana", "it's", "apricot", "Carrot"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := db
+			if tt.order != "" {
+				query = query.Order(tt.order)
+			}
+
+			if tt.limit > 0 {
+				query = query.Limit(tt.limit)
+			}
+
+			var got []memoryItem
+			if err := query.WhereFind(context.Background(), &got, ""); err != nil {
+				t.Fatalf("WhereFind() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(names(got), tt.want) {
+				t.Errorf("WhereFind() = %v, want %v", names(got), tt.want)
+			}
+		})
+	}
+
+	var first memoryItem
+	if err := db.Order("amount DESC").WhereFirst(context.Background(), &first, "kind = ?", "fruit"); err != nil {
+		t.Fatalf("WhereFirst() error = %v", err)
+	}
+
+	if first.Name != "apricot" {
+		t.Errorf("WhereFirst() = %s, want apricot", first.Name)
+	}
+
+	var scoped []memoryItem
+	if err := db.WhereFind(context.Background(), &scoped, ""); err != nil || len(scoped) != 5 {
+		t.Errorf("the order and the limit of a query changed the database: %d items, error %v", len(scoped), err)
+	}
+}
+
+func TestMemorySoftDelete(t *testing.T) {
+	ctx := context.Background()
+	db, items := seedItems(t)
+
+	rows, err := db.WhereDelete(ctx, &memoryItem{}, "kind = ?", "fruit")
+	if err != nil || rows != 3 {
+		t.Fatalf("WhereDelete() = %d, %v, want 3 rows", rows, err)
+	}
+
+	var found memoryItem
+	if err := db.WhereFirst(ctx, &found, "id = ?", items[0].ID); !errors.Is(err, crosscuting.ErrNotFound) {
+		t.Errorf("WhereFirst() of a deleted item error = %v, want %v", err, crosscuting.ErrNotFound)
+	}
+
+	if count, _ := db.WhereCount(ctx, &memoryItem{}, ""); count != 2 {
+		t.Errorf("WhereCount() = %d, want 2", count)
+	}
+
+	var all []memoryItem
+	if err := db.Unscoped().WhereFind(ctx, &all, "deleted_at IS NOT NULL"); err != nil || len(all) != 3 {
+		t.Errorf("Unscoped().WhereFind() = %v, %v, want the 3 deleted items", names(all), err)
+	}
+
+	rows, err = db.Unscoped().WhereUpdates(ctx, &memoryItem{}, map[string]interface{}{"deleted_at": nil}, "id = ? AND deleted_at IS NOT NULL", items[0].ID)
+	if err != nil || rows != 1 {
+		t.Fatalf("restoring with Unscoped().WhereUpdates() = %d, %v, want 1 row", rows, err)
+	}
+
+	if err := db.WhereFirst(ctx, &found, "id = ?", items[0].ID); err != nil || found.Name != "Apple" {
+		t.Errorf("WhereFirst() of the restored item = %s, %v", found.Name, err)
+	}
+
+	if rows, err := db.WhereUpdates(ctx, &memoryItem{}, map[string]interface{}{"name": "x"}, "id = ?", items[1].ID); err != nil || rows != 0 {
+		t.Errorf("WhereUpdates() of a deleted item = %d, %v, want 0 rows", rows, err)
+	}
+
+	rows, err = db.Unscoped().WhereDelete(ctx, &memoryItem{}, "id = ?", items[1].ID)
+	if err != nil || rows != 1 {
+		t.Fatalf("Unscoped().WhereDelete() = %d, %v, want 1 row", rows, err)
+	}
+
+	if count, _ := db.Unscoped().WhereCount(ctx, &memoryItem{}, ""); count != 4 {
+		t.Errorf("Unscoped().WhereCount() after the hard delete = %d, want 4", count)
+	}
+}
+
+func TestMemoryUniqueConstraints(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		constraints []UniqueConstraint
+		item        memoryItem
+		deleteFirst bool
+		wantErr     bool
+	}{
+		{name: "unique tag", item: memoryItem{Name: "x", Code: "A"}, wantErr: true},
+		{name: "unique tag over deleted rows", item: memoryItem{Name: "x", Code: "A"}, deleteFirst: true, wantErr: true},
+		{name: "unique tag without conflict", item: memoryItem{Name: "x", Code: "Z"}},
+		{
+			name:        "registered constraint",
+			constraints: []UniqueConstraint{{Name: "memory_items_kind_name_key", Table: "memory_items", Columns: []string{"kind", "name"}}},
+			item:        memoryItem{Name: "Apple", Kind: "fruit", Code: "Z"},
+			wantErr:     true,
+		},
+		{
+			name:        "registered constraint over deleted rows",
+			constraints: []UniqueConstraint{{Name: "memory_items_kind_name_key", Table: "memory_items", Columns: []string{"kind", "name"}}},
+			item:        memoryItem{Name: "Apple", Kind: "fruit", Code: "Z"},
+			deleteFirst: true,
+			wantErr:     true,
+		},
+		{
+			name:        "partial constraint",
+			constraints: []UniqueConstraint{{Name: "memory_items_name_active_key", Table: "memory_items", Columns: []string{"name"}, OnlyActive: true}},
+			item:        memoryItem{Name: "Apple", Code: "Z"},
+			wantErr:     true,
+		},
+		{
+			name:        "partial constraint ignores deleted rows",
+			constraints: []UniqueConstraint{{Name: "memory_items_name_active_key", Table: "memory_items", Columns: []string{"name"}, OnlyActive: true}},
+			item:        memoryItem{Name: "Apple", Code: "Z"},
+			deleteFirst: true,
+		},
+		{
+			name:        "nulls are distinct",
+			constraints: []UniqueConstraint{{Name: "memory_items_parent_id_key", Table: "memory_items", Columns: []string{"parent_id"}}},
+			item:        memoryItem{Name: "x", Code: "Z"},
+		},
+		{
+			name:        "constraint of another table",
+			constraints: []UniqueConstraint{{Name: "others_name_key", Table: "others", Columns: []string{"name"}}},
+			item:        memoryItem{Name: "Apple", Code: "Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, items := seedItems(t)
+
+			// The seed shares a parent, the constraints are registered once the parents are cleared.
+			if _, err := db.WhereUpdates(ctx, &memoryItem{}, map[string]interface{}{"parent_id": nil}, ""); err != nil {
+				t.Fatalf("WhereUpdates() error = %v", err)
+			}
+
+			db.(*MemoryImpl).store.constraints = tt.constraints
+
+			if tt.deleteFirst {
+				if _, err := db.WhereDelete(ctx, &memoryItem{}, "id = ?", items[0].ID); err != nil {
+					t.Fatalf("WhereDelete() error = %v", err)
+				}
+			}
+
+			err := db.Create(ctx, &tt.item)
+			if tt.wantErr != errors.Is(err, crosscuting.ErrConflict) {
+				t.Fatalf("Create() error = %v, want conflict %t", err, tt.wantErr)
+			}
+
+			if tt.wantErr && tt.item.ID != 0 {
+				t.Errorf("Create() set the id %d of a conflicting item", tt.item.ID)
+			}
+		})
+	}
+}
+
+func TestMemoryUniqueViolationOnUpdate(t *testing.T) {
+	ctx := context.Background()
+	db, items := seedItems(t)
+
+	_, err := db.WhereUpdates(ctx, &memoryItem{}, map[string]interface{}{"code": "A"}, "id = ?", items[1].ID)
+	if !errors.Is(err, ErrDuplicatedKey) {
+		t.Fatalf("WhereUpdates() error = %v, want %v", err, ErrDuplicatedKey)
+	}
+
+	var found memoryItem
+	if err := db.WhereFirst(ctx, &found, "id = ?", items[1].ID); err != nil || found.Code != "B" {
+		t.Errorf("the failed update changed the item: code %s, error %v", found.Code, err)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	seen := map[string]bool{}
+
+	for _, constraint := range Constraints {
+		if constraint.Name == "" || constraint.Table == "" || len(constraint.Columns) == 0 {
+			t.Errorf("the constraint %+v must have a name, a table and columns", constraint)
+		}
+
+		if seen[constraint.Name] {
+			t.Errorf("the constraint %s is registered twice", constraint.Name)
+		}
+
+		seen[constraint.Name] = true
+	}
+
+	// The constraints with specific errors are registered or come from a unique tag, named <table>_<column>_key like postgres.
+	for name := range uniqueViolations {
+		if !seen[name] && !strings.HasSuffix(name, "_key") {
+			t.Errorf("the constraint %s has a specific error but is not in the Constraints", name)
+		}
+	}
+}
+
+func TestMemoryUpsert(t *testing.T) {
+	ctx := context.Background()
+	db, items := seedItems(t)
+
+	upserted := []memoryItem{
+		{Name: "Apple", Kind: "fruit", Code: "A", Amount: decimal.NewFromInt(9)},
+		{Name: "Fig", Kind: "fruit", Code: "F", Amount: decimal.NewFromInt(7)},
+	}
+
+	if err := db.Upsert(ctx, &upserted, []string{"code"}, []string{"amount", "updated_at"}); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	if upserted[0].ID != items[0].ID || upserted[1].ID == 0 {
+		t.Errorf("Upsert() ids = %d, %d, want %d and a new one", upserted[0].ID, upserted[1].ID, items[0].ID)
+	}
+
+	var apple memoryItem
+	if err := db.WhereFirst(ctx, &apple, "code = ?", "A"); err != nil || !apple.Amount.Equal(decimal.NewFromInt(9)) || !apple.Active {
+		t.Errorf("the upserted item = %+v, %v, want the amount updated and the other columns kept", apple, err)
+	}
+
+	if count, _ := db.WhereCount(ctx, &memoryItem{}, ""); count != 6 {
+		t.Errorf("WhereCount() = %d, want 6", count)
+	}
+}
+
+func TestMemoryTransaction(t *testing.T) {
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	count := func(db Gorm, name string) int64 {
+		t.Helper()
+
+		n, err := db.WhereCount(ctx, &memoryItem{}, "name = ?", name)
+		if err != nil {
+			t.Fatalf("WhereCount() error = %v", err)
+		}
+
+		return n
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		db, _ := seedItems(t)
+
+		err := db.Transaction(ctx, func(tx Gorm) error {
+			return tx.Create(ctx, &memoryItem{Name: "outer", Code: "O"})
+		})
+		if err != nil || count(db, "outer") != 1 {
+			t.Errorf("Transaction() = %v, want the item committed", err)
+		}
+	})
+
+	t.Run("nested rollback keeps the outer work", func(t *testing.T) {
+		db, _ := seedItems(t)
+
+		err := db.Transaction(ctx, func(tx Gorm) error {
+			if err := tx.Create(ctx, &memoryItem{Name: "outer", Code: "O"}); err != nil {
+				return err
+			}
+
+			err := tx.Transaction(ctx, func(inner Gorm) error {
+				if err := inner.Create(ctx, &memoryItem{Name: "inner", Code: "I"}); err != nil {
+					return err
+				}
+
+				return errRollback
+			})
+			if !errors.Is(err, errRollback) {
+				t.Errorf("nested Transaction() error = %v, want %v", err, errRollback)
+			}
+
+			if count(tx, "inner") != 0 || count(tx, "outer") != 1 {
+				t.Error("the nested rollback must only restore the nested work")
+			}
+
+			return nil
+		})
+		if err != nil || count(db, "outer") != 1 || count(db, "inner") != 0 {
+			t.Errorf("Transaction() = %v, want only the outer item", err)
+		}
+	})
+
+	t.Run("outer rollback discards the nested work", func(t *testing.T) {
+		db, _ := seedItems(t)
+
+		err := db.Transaction(ctx, func(tx Gorm) error {
+			if err := tx.Transaction(ctx, func(inner Gorm) error {
+				return inner.Create(ctx, &memoryItem{Name: "inner", Code: "I"})
+			}); err != nil {
+				return err
+			}
+
+			if _, err := tx.WhereDelete(ctx, &memoryItem{}, "code = ?", "A"); err != nil {
+				return err
+			}
+
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) || count(db, "inner") != 0 || count(db, "Apple") != 1 {
+			t.Errorf("Transaction() = %v, want the inner item and the delete rolled back", err)
+		}
+	})
+
+	t.Run("panic rolls back and propagates", func(t *testing.T) {
+		db, _ := seedItems(t)
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Transaction() did not propagate the panic")
+			}
+
+			if count(db, "panic") != 0 {
+				t.Error("Transaction() did not roll back the panic")
+			}
+		}()
+
+		_ = db.Transaction(ctx, func(tx Gorm) error {
+			if err := tx.Create(ctx, &memoryItem{Name: "panic", Code: "P"}); err != nil {
+				return err
+			}
+
+			panic("boom")
+		})
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		db, _ := seedItems(t)
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := db.Transaction(canceled, func(Gorm) error { return nil })
+		if !errors.Is(err, ErrQueryCanceled) {
+			t.Errorf("Transaction() error = %v, want %v", err, ErrQueryCanceled)
+		}
+	})
+}
+
+func TestMemoryTimestamps(t *testing.T) {
+	ctx := context.Background()
+	db, items := seedItems(t)
+
+	if items[0].CreatedAt.IsZero() || items[0].UpdatedAt.IsZero() {
+		t.Fatalf("Create() did not set the timestamps: %+v", items[0].Model)
+	}
+
+	before := items[0].UpdatedAt
+	time.Sleep(time.Millisecond)
+
+	if _, err := db.WhereUpdates(ctx, &memoryItem{}, map[string]interface{}{"name": "Apples"}, "id = ?", items[0].ID); err != nil {
+		t.Fatalf("WhereUpdates() error = %v", err)
+	}
+
+	var found memoryItem
+	if err := db.WhereFirst(ctx, &found, "id = ?", items[0].ID); err != nil || !found.UpdatedAt.After(before) {
+		t.Errorf("WhereUpdates() did not update the updated_at: %v, %v", found.UpdatedAt, err)
+	}
+}
+
+func TestMemoryRecordsDoNotShareValues(t *testing.T) {
+	ctx := context.Background()
+	db, _ := seedItems(t)
+
+	parentID := uint(1)
+	item := memoryItem{Name: "Fig", Kind: "fruit", Code: "F", ParentID: &parentID}
+
+	if err := db.Create(ctx, &item); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	parentID = 2
+
+	newParentID := uint(3)
+	if _, err := db.WhereUpdates(ctx, &memoryItem{}, map[string]interface{}{"parent_id": &newParentID}, "parent_id = ?", 1); err != nil {
+		t.Fatalf("WhereUpdates() error = %v", err)
+	}
+
+	newParentID = 4
+
+	if parentID != 2 || *item.ParentID != 1 {
+		t.Errorf("WhereUpdates() changed the values of the caller: %d, %d", parentID, *item.ParentID)
+	}
+
+	var found memoryItem
+	if err := db.WhereFirst(ctx, &found, "id = ?", item.ID); err != nil || found.ParentID == nil || *found.ParentID != 3 {
+		t.Fatalf("WhereFirst() = %+v, %v, want the parent 3", found.ParentID, err)
+	}
+
+	*found.ParentID = 5
+
+	if count, _ := db.WhereCount(ctx, &memoryItem{}, "parent_id = ?", 3); count != 3 {
+		t.Errorf("WhereCount() = %d, the stored records changed with the values of the callers", count)
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX financial_assets_symbol_active_key;
+
+ALTER TABLE financial_assets ADD CONSTRAINT financial_assets_symbol_key UNIQUE (symbol);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE financial_assets DROP CONSTRAINT financial_assets_symbol_key;
+
+CREATE UNIQUE INDEX financial_assets_symbol_active_key ON financial_assets (symbol) WHERE deleted_at IS NULL;
\ No newline at end of file
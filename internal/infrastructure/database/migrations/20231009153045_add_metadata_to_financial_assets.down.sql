@@ -0,0 +1 @@
+ALTER TABLE financial_assets DROP COLUMN metadata;
\ No newline at end of file
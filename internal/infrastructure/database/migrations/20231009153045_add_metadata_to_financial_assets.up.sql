@@ -0,0 +1 @@
+ALTER TABLE financial_assets ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
\ No newline at end of file
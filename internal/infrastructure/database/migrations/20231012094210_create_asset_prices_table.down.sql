@@ -0,0 +1 @@
+DROP TABLE asset_prices;
\ No newline at end of file
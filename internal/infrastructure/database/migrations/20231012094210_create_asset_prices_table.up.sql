@@ -0,0 +1,16 @@
+CREATE TABLE asset_prices (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    asset_id INTEGER NOT NULL REFERENCES financial_assets (id),
+    timestamp TIMESTAMP NOT NULL,
+    open NUMERIC NOT NULL,
+    high NUMERIC NOT NULL,
+    low NUMERIC NOT NULL,
+    close NUMERIC NOT NULL,
+    volume NUMERIC NOT NULL DEFAULT 0,
+    source VARCHAR(255) NOT NULL,
+    quote_currency VARCHAR(16) NOT NULL,
+    CONSTRAINT asset_prices_asset_quote_timestamp_key UNIQUE (asset_id, quote_currency, timestamp)
+);
\ No newline at end of file
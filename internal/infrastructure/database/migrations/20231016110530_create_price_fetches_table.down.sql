@@ -0,0 +1 @@
+DROP TABLE price_fetches;
\ No newline at end of file
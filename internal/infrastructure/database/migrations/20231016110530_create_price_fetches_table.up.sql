@@ -0,0 +1,13 @@
+CREATE TABLE price_fetches (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    asset_id INTEGER NOT NULL REFERENCES financial_assets (id),
+    provider VARCHAR(255) NOT NULL,
+    last_attempt_at TIMESTAMP NOT NULL,
+    last_success_at TIMESTAMP,
+    last_price_at TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    CONSTRAINT price_fetches_asset_provider_key UNIQUE (asset_id, provider)
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE accounts;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE accounts (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    asset_id INTEGER NOT NULL REFERENCES financial_assets (id),
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(255) NOT NULL,
+    institution VARCHAR(255) NOT NULL DEFAULT '',
+    opening_balance_amount NUMERIC NOT NULL DEFAULT 0,
+    opening_balance_currency VARCHAR(16) NOT NULL,
+    status VARCHAR(255) NOT NULL,
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX idx_accounts_user_id ON accounts (user_id);
\ No newline at end of file
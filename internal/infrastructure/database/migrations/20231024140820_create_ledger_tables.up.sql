@@ -0,0 +1,28 @@
+CREATE TABLE ledger_transactions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    date TIMESTAMP NOT NULL,
+    kind VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_ledger_transactions_user_id ON ledger_transactions (user_id);
+
+CREATE TABLE ledger_postings (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    transaction_id INTEGER NOT NULL REFERENCES ledger_transactions (id),
+    account_id INTEGER NOT NULL REFERENCES accounts (id),
+    date TIMESTAMP NOT NULL,
+    amount_amount NUMERIC NOT NULL,
+    amount_currency VARCHAR(16) NOT NULL,
+    memo TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_ledger_postings_transaction_id ON ledger_postings (transaction_id);
+CREATE INDEX idx_ledger_postings_account_id_date ON ledger_postings (account_id, date);
\ No newline at end of file
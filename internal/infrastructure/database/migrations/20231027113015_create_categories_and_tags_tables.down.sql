@@ -0,0 +1,5 @@
+ALTER TABLE ledger_transactions DROP COLUMN category_id;
+
+DROP TABLE transaction_tags;
+DROP TABLE tags;
+DROP TABLE categories;
\ No newline at end of file
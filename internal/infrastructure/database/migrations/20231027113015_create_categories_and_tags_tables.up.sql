@@ -0,0 +1,38 @@
+CREATE TABLE categories (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    parent_id INTEGER REFERENCES categories (id),
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(255) NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    icon VARCHAR(255) NOT NULL DEFAULT '',
+    color VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_categories_user_id ON categories (user_id);
+
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX tags_user_id_name_key ON tags (user_id, name) WHERE deleted_at IS NULL;
+
+CREATE TABLE transaction_tags (
+    transaction_id INTEGER NOT NULL REFERENCES ledger_transactions (id),
+    tag_id INTEGER NOT NULL REFERENCES tags (id),
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX idx_transaction_tags_tag_id ON transaction_tags (tag_id);
+
+ALTER TABLE ledger_transactions ADD COLUMN category_id INTEGER REFERENCES categories (id);
+
+CREATE INDEX idx_ledger_transactions_category_id ON ledger_transactions (category_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE budgets;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE budgets (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    category_id INTEGER NOT NULL REFERENCES categories (id),
+    name VARCHAR(255) NOT NULL,
+    period VARCHAR(255) NOT NULL,
+    amount_amount NUMERIC NOT NULL,
+    amount_currency VARCHAR(16) NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP,
+    rollover BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX idx_budgets_user_id ON budgets (user_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE recurring_occurrences;
+DROP TABLE recurring_templates;
\ No newline at end of file
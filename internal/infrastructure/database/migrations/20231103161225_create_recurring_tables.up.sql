@@ -0,0 +1,36 @@
+CREATE TABLE recurring_templates (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category_id INTEGER REFERENCES categories (id),
+    postings JSONB NOT NULL,
+    schedule_frequency VARCHAR(255) NOT NULL,
+    schedule_interval INTEGER NOT NULL,
+    schedule_by_day VARCHAR(255) NOT NULL DEFAULT '',
+    schedule_start_date TIMESTAMP NOT NULL,
+    schedule_end_date TIMESTAMP,
+    schedule_count INTEGER,
+    next_date TIMESTAMP
+);
+
+CREATE INDEX idx_recurring_templates_user_id ON recurring_templates (user_id);
+CREATE INDEX idx_recurring_templates_next_date ON recurring_templates (next_date) WHERE deleted_at IS NULL;
+
+CREATE TABLE recurring_occurrences (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    template_id INTEGER NOT NULL REFERENCES recurring_templates (id),
+    date TIMESTAMP NOT NULL,
+    status VARCHAR(255) NOT NULL,
+    transaction_id INTEGER REFERENCES ledger_transactions (id),
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX recurring_occurrences_template_id_date_key ON recurring_occurrences (template_id, date) WHERE deleted_at IS NULL;
\ No newline at end of file
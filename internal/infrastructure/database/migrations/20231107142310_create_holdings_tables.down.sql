@@ -0,0 +1,5 @@
+DROP TABLE tax_lot_sales;
+DROP TABLE tax_lots;
+DROP TABLE trades;
+
+ALTER TABLE accounts DROP COLUMN cost_basis_method;
\ No newline at end of file
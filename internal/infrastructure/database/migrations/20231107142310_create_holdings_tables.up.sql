@@ -0,0 +1,58 @@
+ALTER TABLE accounts ADD COLUMN cost_basis_method VARCHAR(255) NOT NULL DEFAULT 'fifo';
+
+CREATE TABLE trades (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL REFERENCES accounts (id),
+    asset_id INTEGER NOT NULL REFERENCES financial_assets (id),
+    side VARCHAR(255) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    quantity NUMERIC NOT NULL,
+    price_amount NUMERIC NOT NULL,
+    price_currency VARCHAR(16) NOT NULL,
+    fee_amount NUMERIC NOT NULL DEFAULT 0,
+    fee_currency VARCHAR(16) NOT NULL
+);
+
+CREATE INDEX idx_trades_user_id ON trades (user_id);
+
+CREATE TABLE tax_lots (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL REFERENCES accounts (id),
+    asset_id INTEGER NOT NULL REFERENCES financial_assets (id),
+    trade_id INTEGER NOT NULL REFERENCES trades (id),
+    date TIMESTAMP NOT NULL,
+    quantity NUMERIC NOT NULL,
+    cost_amount NUMERIC NOT NULL,
+    cost_currency VARCHAR(16) NOT NULL,
+    remaining NUMERIC NOT NULL,
+    remaining_cost_amount NUMERIC NOT NULL,
+    remaining_cost_currency VARCHAR(16) NOT NULL
+);
+
+CREATE INDEX idx_tax_lots_account_id_asset_id ON tax_lots (account_id, asset_id) WHERE remaining > 0;
+
+CREATE TABLE tax_lot_sales (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    trade_id INTEGER NOT NULL REFERENCES trades (id),
+    lot_id INTEGER NOT NULL REFERENCES tax_lots (id),
+    quantity NUMERIC NOT NULL,
+    cost_basis_amount NUMERIC NOT NULL,
+    cost_basis_currency VARCHAR(16) NOT NULL,
+    proceeds_amount NUMERIC NOT NULL,
+    proceeds_currency VARCHAR(16) NOT NULL,
+    gain_amount NUMERIC NOT NULL,
+    gain_currency VARCHAR(16) NOT NULL
+);
+
+CREATE INDEX idx_tax_lot_sales_trade_id ON tax_lot_sales (trade_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE net_worth_snapshots;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE net_worth_snapshots (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    date TIMESTAMP NOT NULL,
+    assets_amount NUMERIC NOT NULL,
+    assets_currency VARCHAR(16) NOT NULL,
+    liabilities_amount NUMERIC NOT NULL,
+    liabilities_currency VARCHAR(16) NOT NULL,
+    net_worth_amount NUMERIC NOT NULL,
+    net_worth_currency VARCHAR(16) NOT NULL,
+    by_account_type JSONB NOT NULL DEFAULT '{}',
+    by_asset_class JSONB NOT NULL DEFAULT '{}',
+    CONSTRAINT net_worth_snapshots_user_id_date_key UNIQUE (user_id, date)
+);
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE imported_transactions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL REFERENCES accounts (id),
+    external_id VARCHAR(255) NOT NULL,
+    transaction_id INTEGER REFERENCES ledger_transactions (id)
+);
+
+CREATE UNIQUE INDEX imported_transactions_account_id_external_id_key ON imported_transactions (account_id, external_id) WHERE deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE import_profiles;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE import_profiles (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    delimiter VARCHAR(4) NOT NULL,
+    encoding VARCHAR(16) NOT NULL,
+    skip_rows INTEGER NOT NULL DEFAULT 0,
+    has_header BOOLEAN NOT NULL DEFAULT FALSE,
+    date_column VARCHAR(255) NOT NULL,
+    date_format VARCHAR(64) NOT NULL,
+    amount_column VARCHAR(255) NOT NULL DEFAULT '',
+    debit_column VARCHAR(255) NOT NULL DEFAULT '',
+    credit_column VARCHAR(255) NOT NULL DEFAULT '',
+    payee_column VARCHAR(255) NOT NULL DEFAULT '',
+    memo_column VARCHAR(255) NOT NULL DEFAULT '',
+    id_column VARCHAR(255) NOT NULL DEFAULT '',
+    decimal_separator VARCHAR(1) NOT NULL
+);
\ No newline at end of file
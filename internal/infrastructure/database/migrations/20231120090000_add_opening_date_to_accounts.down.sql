@@ -0,0 +1 @@
+ALTER TABLE accounts DROP COLUMN opening_date;
\ No newline at end of file
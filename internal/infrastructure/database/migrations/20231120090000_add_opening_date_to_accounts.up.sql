@@ -0,0 +1,5 @@
+ALTER TABLE accounts ADD COLUMN opening_date TIMESTAMP;
+
+UPDATE accounts SET opening_date = created_at;
+
+ALTER TABLE accounts ALTER COLUMN opening_date SET NOT NULL;
\ No newline at end of file
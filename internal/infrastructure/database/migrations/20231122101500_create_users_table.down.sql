@@ -0,0 +1 @@
+DROP TABLE users;
\ No newline at end of file
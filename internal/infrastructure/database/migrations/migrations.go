@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// FS contains the up and down SQL files of the migrations, named <version>_<name>.<up|down>.sql.
+//
+//go:embed *.sql
+var FS embed.FS
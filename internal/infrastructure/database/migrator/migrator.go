@@ -0,0 +1,394 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+// lockID is the key of the postgres advisory lock held while migrating, so the replicas do not migrate at once.
+const lockID int64 = 7_302_118_454_910_263
+
+var (
+	loggerMigrator = logger.Setup("infrastructure.database.migrator")
+	errMigrator    = errors.New("migrator error")
+
+	// ErrDirty is returned when a migration failed halfway and the version must be forced after fixing the database.
+	ErrDirty = errors.New("dirty database error")
+	// ErrUnknownVersion is returned when the version is not one of the migrations.
+	ErrUnknownVersion = errors.New("unknown migration version error")
+
+	// fileName matches the names of the migration files: <version>_<name>.<up|down>.sql.
+	fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+)
+
+type (
+	// Migrator is the interface for applying and reverting the migrations of the database.
+	Migrator interface {
+		Up(ctx context.Context) ([]Migration, error)
+		Down(ctx context.Context, steps int) ([]Migration, error)
+		Status(ctx context.Context) (Status, error)
+		Force(ctx context.Context, version uint64) error
+		Close() error
+	}
+
+	// MigratorImpl is the struct that contains the migrator.
+	// The version is stored in the schema_migrations table, in the same way as the migrate CLI,
+	// so the databases migrated with it keep their version.
+	MigratorImpl struct {
+		db         *sql.DB
+		migrations []Migration
+	}
+
+	// Migration is the struct for a migration and its up and down SQL.
+	Migration struct {
+		Version uint64
+		Name    string
+		up      string
+		down    string
+	}
+
+	// downStep is the struct for a migration to revert and the version of the database after reverting it.
+	downStep struct {
+		migration Migration
+		previous  uint64
+	}
+
+	// Status is the struct for the version of the database and the migrations applied and pending.
+	// The version is zero when no migration was applied.
+	Status struct {
+		Version uint64
+		Dirty   bool
+		Applied []Migration
+		Pending []Migration
+	}
+)
+
+// NewMigrator creates a new migrator of the database with the migrations of the files.
+func NewMigrator(db *sql.DB, files fs.FS) (Migrator, error) {
+	migrations, err := readMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MigratorImpl{db: db, migrations: migrations}, nil
+}
+
+// Up applies the pending migrations in order and returns them. Each migration runs in its own transaction
+// with the update of the version, so a failed migration does not leave the database dirty.
+func (m *MigratorImpl) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= version {
+				continue
+			}
+
+			if err := apply(ctx, conn, migration.up, migration.Version); err != nil {
+				return fmt.Errorf(crosscuting.WrapLabel, fmt.Sprintf("Error applying the migration %d_%s", migration.Version, migration.Name), errMigrator, err.Error())
+			}
+
+			loggerMigrator.Infof("Applied the migration %d_%s", migration.Version, migration.Name)
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns them.
+func (m *MigratorImpl) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, step := range downSteps(m.migrations, version, steps) {
+			migration := step.migration
+
+			if err := apply(ctx, conn, migration.down, step.previous); err != nil {
+				return fmt.Errorf(crosscuting.WrapLabel, fmt.Sprintf("Error reverting the migration %d_%s", migration.Version, migration.Name), errMigrator, err.Error())
+			}
+
+			loggerMigrator.Infof("Reverted the migration %d_%s", migration.Version, migration.Name)
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status returns the version of the database and the migrations applied and pending.
+func (m *MigratorImpl) Status(ctx context.Context) (Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return Status{}, fmt.Errorf(crosscuting.WrapLabel, "Error connecting to the database", errMigrator, err.Error())
+	}
+	defer conn.Close()
+
+	if err := createVersionTable(ctx, conn); err != nil {
+		return Status{}, err
+	}
+
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Version: version, Dirty: dirty}
+
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+
+	return status, nil
+}
+
+// Force sets the version of the database without running the migrations and clears the dirty flag.
+// It is used after fixing by hand a database left dirty by a failed migration. Zero means no migration applied.
+func (m *MigratorImpl) Force(ctx context.Context, version uint64) error {
+	if version != 0 && !m.exists(version) {
+		return fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("The migration %d does not exist", version), ErrUnknownVersion)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		if err := apply(ctx, conn, "", version); err != nil {
+			return fmt.Errorf(crosscuting.WrapLabel, "Error forcing the version", errMigrator, err.Error())
+		}
+
+		loggerMigrator.Infof("Forced the version %d", version)
+
+		return nil
+	})
+}
+
+// Close closes the connections to the database.
+func (m *MigratorImpl) Close() error {
+	return m.db.Close()
+}
+
+// withLock runs the function in a connection that holds the advisory lock of the migrations,
+// waiting for the other migrators to finish.
+func (m *MigratorImpl) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf(crosscuting.WrapLabel, "Error connecting to the database", errMigrator, err.Error())
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf(crosscuting.WrapLabel, "Error acquiring the migrations lock", errMigrator, err.Error())
+	}
+
+	defer func() {
+		// The lock is released even if the context is done, the pooled connection would keep holding it otherwise.
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			loggerMigrator.WithError(err).Error("Error releasing the migrations lock")
+		}
+	}()
+
+	if err := createVersionTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *MigratorImpl) exists(version uint64) bool {
+	i := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version >= version })
+
+	return i < len(m.migrations) && m.migrations[i].Version == version
+}
+
+// downSteps returns the last steps migrations applied up to the version, newest first, with the version left
+// after reverting each one. The previous version is the one of the migration before it, zero for the first one.
+func downSteps(migrations []Migration, version uint64, steps int) []downStep {
+	var result []downStep
+
+	for i := len(migrations) - 1; i >= 0 && len(result) < steps; i-- {
+		if migrations[i].Version > version {
+			continue
+		}
+
+		var previous uint64
+		if i > 0 {
+			previous = migrations[i-1].Version
+		}
+
+		result = append(result, downStep{migration: migrations[i], previous: previous})
+	}
+
+	return result
+}
+
+// currentVersion returns the version of the database, failing if it is dirty.
+func currentVersion(ctx context.Context, conn *sql.Conn) (uint64, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("The migration %d failed, fix the database and force its version", version), ErrDirty)
+	}
+
+	return version, nil
+}
+
+func createVersionTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"); err != nil {
+		return fmt.Errorf(crosscuting.WrapLabel, "Error creating the schema_migrations table", errMigrator, err.Error())
+	}
+
+	return nil
+}
+
+func readVersion(ctx context.Context, conn *sql.Conn) (uint64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf(crosscuting.WrapLabel, "Error reading the version", errMigrator, err.Error())
+	}
+
+	if version < 0 {
+		return 0, dirty, nil
+	}
+
+	return uint64(version), dirty, nil
+}
+
+// apply runs the SQL and sets the version in the same transaction. A zero version removes it.
+// The SQL runs without args so the files can have several statements.
+func apply(ctx context.Context, conn *sql.Conn, query string, version uint64) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := execWithVersion(ctx, tx, query, version); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			loggerMigrator.WithError(rollbackErr).Error("Error rolling back the migration")
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func execWithVersion(ctx context.Context, tx *sql.Tx, query string, version uint64) error {
+	if query != "" {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, FALSE)", int64(version))
+
+	return err
+}
+
+// readMigrations reads the migrations of the directory sorted by version. Every version needs its up and down files,
+// an empty file does nothing.
+func readMigrations(dir fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, fmt.Errorf(crosscuting.WrapLabel, "Error reading the migrations", errMigrator, err.Error())
+	}
+
+	byVersion := map[uint64]*Migration{}
+	files := map[uint64]map[string]bool{}
+
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("Invalid version of the migration %s", entry.Name()), errMigrator)
+		}
+
+		data, err := fs.ReadFile(dir, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf(crosscuting.WrapLabel, fmt.Sprintf("Error reading the migration %s", entry.Name()), errMigrator, err.Error())
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+			files[version] = map[string]bool{}
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("The migrations %s and %s have the same version", migration.Name, match[2]), errMigrator)
+		}
+
+		files[version][match[3]] = true
+
+		if match[3] == "up" {
+			migration.up = string(data)
+		} else {
+			migration.down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if !files[migration.Version]["up"] || !files[migration.Version]["down"] {
+			return nil, fmt.Errorf(crosscuting.WrapLabelWithoutError, fmt.Sprintf("The migration %d_%s needs its up and down files", migration.Version, migration.Name), errMigrator)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
@@ -0,0 +1,166 @@
+package migrator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"20231118101500_create_users_table.up.sql":     {Data: []byte("CREATE TABLE users ();")},
+				"20231118101500_create_users_table.down.sql":   {Data: []byte("DROP TABLE users;")},
+				"20230925023232_create_assets_table.up.sql":    {Data: []byte("CREATE TABLE assets ();")},
+				"20230925023232_create_assets_table.down.sql":  {Data: []byte("DROP TABLE assets;")},
+				"900_create_tokens_table.up.sql":               {Data: []byte("CREATE TABLE tokens ();")},
+				"900_create_tokens_table.down.sql":             {Data: []byte("")},
+				"migrations.go":                                {Data: []byte("package migrations")},
+				"20231120090000_ignored_directory.up.sql/file": {Data: []byte("")},
+			},
+			want: []Migration{
+				{Version: 900, Name: "create_tokens_table", up: "CREATE TABLE tokens ();"},
+				{Version: 20230925023232, Name: "create_assets_table", up: "CREATE TABLE assets ();", down: "DROP TABLE assets;"},
+				{Version: 20231118101500, Name: "create_users_table", up: "CREATE TABLE users ();", down: "DROP TABLE users;"},
+			},
+		},
+		{
+			name:  "no migrations",
+			files: fstest.MapFS{"migrations.go": {Data: []byte("package migrations")}},
+			want:  []Migration{},
+		},
+		{
+			name:    "missing down",
+			files:   fstest.MapFS{"1_create_users_table.up.sql": {Data: []byte("CREATE TABLE users ();")}},
+			wantErr: true,
+		},
+		{
+			name:    "missing up",
+			files:   fstest.MapFS{"1_create_users_table.down.sql": {Data: []byte("DROP TABLE users;")}},
+			wantErr: true,
+		},
+		{
+			name: "same version with another name",
+			files: fstest.MapFS{
+				"1_create_users_table.up.sql":    {Data: []byte("CREATE TABLE users ();")},
+				"1_create_users_table.down.sql":  {Data: []byte("DROP TABLE users;")},
+				"1_create_tokens_table.up.sql":   {Data: []byte("CREATE TABLE tokens ();")},
+				"1_create_tokens_table.down.sql": {Data: []byte("DROP TABLE tokens;")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero version",
+			files: fstest.MapFS{
+				"0_create_users_table.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+				"0_create_users_table.down.sql": {Data: []byte("DROP TABLE users;")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "version out of range",
+			files: fstest.MapFS{
+				"99999999999999999999_create_users_table.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+				"99999999999999999999_create_users_table.down.sql": {Data: []byte("DROP TABLE users;")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readMigrations(tt.files)
+
+			if tt.wantErr {
+				if !errors.Is(err, errMigrator) {
+					t.Fatalf("readMigrations() error = %v, want %v", err, errMigrator)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("readMigrations() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readMigrations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownSteps(t *testing.T) {
+	migrations := []Migration{{Version: 3}, {Version: 10}, {Version: 20231005201130}, {Version: 20231118101500}}
+
+	tests := []struct {
+		name    string
+		version uint64
+		steps   int
+		want    []downStep
+	}{
+		{
+			name:    "last migration",
+			version: 20231118101500,
+			steps:   1,
+			want:    []downStep{{migration: Migration{Version: 20231118101500}, previous: 20231005201130}},
+		},
+		{
+			name:    "several migrations with gaps",
+			version: 20231118101500,
+			steps:   3,
+			want: []downStep{
+				{migration: Migration{Version: 20231118101500}, previous: 20231005201130},
+				{migration: Migration{Version: 20231005201130}, previous: 10},
+				{migration: Migration{Version: 10}, previous: 3},
+			},
+		},
+		{
+			name:    "pending migrations are skipped",
+			version: 10,
+			steps:   1,
+			want:    []downStep{{migration: Migration{Version: 10}, previous: 3}},
+		},
+		{
+			name:    "version between migrations",
+			version: 20231010000000,
+			steps:   1,
+			want:    []downStep{{migration: Migration{Version: 20231005201130}, previous: 10}},
+		},
+		{
+			name:    "more steps than applied",
+			version: 10,
+			steps:   5,
+			want: []downStep{
+				{migration: Migration{Version: 10}, previous: 3},
+				{migration: Migration{Version: 3}, previous: 0},
+			},
+		},
+		{
+			name:    "nothing applied",
+			version: 0,
+			steps:   1,
+		},
+		{
+			name:    "no steps",
+			version: 20231118101500,
+			steps:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downSteps(migrations, tt.version, tt.steps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("downSteps() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jho3r/finanger-back/internal/app/crosscuting"
+	"github.com/jho3r/finanger-back/internal/infrastructure/logger"
+)
+
+var (
+	loggerJWT     = logger.Setup("infrastructure.jwt")
+	errJWTSign    = errors.New("jwt sign error")
+	errJWTInvalid = crosscuting.NewTypedError(crosscuting.ErrUnauthorized, "INVALID_ACCESS_TOKEN", "jwt invalid token error")
+)
+
+// Claims is the struct for the claims of the access token.
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// UserID returns the user id stored in the subject of the claims.
+func (c Claims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf(crosscuting.WrapLabel, "Error parsing the subject", errJWTInvalid, err.Error())
+	}
+
+	return uint(id), nil
+}
+
+// JWT is the interface for the access token generation and validation.
+type JWT interface {
+	Generate(userID uint) (string, error)
+	Validate(token string) (Claims, error)
+	TTL() time.Duration
+}
+
+// JWTImpl is the struct that contains the HS256 key and settings of the tokens.
+type JWTImpl struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+}
+
+// NewJWT creates a new HS256 token signer and validator.
+func NewJWT(secret, issuer string, ttl time.Duration) JWT {
+	return &JWTImpl{secret: []byte(secret), issuer: issuer, ttl: ttl}
+}
+
+// Generate creates a signed access token for the given user.
+func (j *JWTImpl) Generate(userID uint) (string, error) {
+	now := time.Now()
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    j.issuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secret)
+	if err != nil {
+		desc := "Error signing the token"
+		loggerJWT.WithError(err).Error(desc)
+
+		return "", fmt.Errorf(crosscuting.WrapLabel, desc, errJWTSign, err.Error())
+	}
+
+	return signed, nil
+}
+
+// Validate parses the token, checks the signature, the issuer and the expiration and returns its claims.
+func (j *JWTImpl) Validate(token string) (Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		return j.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(j.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf(crosscuting.WrapLabel, "Error validating the token", errJWTInvalid, err.Error())
+	}
+
+	return claims, nil
+}
+
+// TTL returns the time to live of the access tokens.
+func (j *JWTImpl) TTL() time.Duration {
+	return j.ttl
+}